@@ -5,7 +5,10 @@ import (
 	"crawler/geocoder"
 	"crawler/model"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
@@ -15,42 +18,6 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
-var gc_count int
-
-func consume(gc *geocoder.Geocoder, rs *model.Residents, stats *map[time.Time]int, in chan model.Resident) {
-	for r := range in {
-		//	地理编码
-		if gc != nil {
-			s := fmt.Sprintf("%s%s%s", r.City, r.District, r.Address)
-			addr, err := gc.Geocode(s)
-			if err != nil {
-				log.Warnf("解析地址 %q 失败：%s", s, err)
-			} else {
-				r.Longitude = addr.Longitude
-				r.Latitude = addr.Latitude
-				gc_count += 1
-			}
-		}
-		if gc_count%100000 == 0 {
-			fmt.Println()
-		} else if gc_count%10000 == 0 {
-			fmt.Print(":")
-		} else if gc_count%1000 == 0 {
-			fmt.Print(".")
-		}
-		//	追加
-		*rs = append(*rs, r)
-		//	统计
-		if val, ok := (*stats)[r.Date]; ok {
-			(*stats)[r.Date] = val + 1
-		} else {
-			(*stats)[r.Date] = 1
-		}
-	}
-
-	fmt.Println()
-}
-
 var spewConfig = spew.ConfigState{
 	Indent:                  "  ",
 	DisablePointerAddresses: true,
@@ -116,6 +83,84 @@ func update[T KeyerStringer](old, fresh []T, show_addition bool) []T {
 	return old
 }
 
+// 用新抓取的数据替换旧表中相同日期的全部数据，其余日期的数据保持不变
+//
+//	按日历日期比较，旧数据中的日期可能来自不同时区
+func replace[T KeyerStringer](old, fresh []T, date func(T) time.Time) []T {
+	dates := make(map[string]bool)
+	for _, fd := range fresh {
+		dates[date(fd).Format("2006-01-02")] = true
+	}
+	result := make([]T, 0, len(old)+len(fresh))
+	removed := 0
+	for _, od := range old {
+		if dates[date(od).Format("2006-01-02")] {
+			removed++
+			continue
+		}
+		result = append(result, od)
+	}
+	log.Infof("替换 %d 天的数据：移除旧数据 %d 条，添加新数据 %d 条", len(dates), removed, len(fresh))
+	return append(result, fresh...)
+}
+
+func parseDate(s string) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// 按 --geocoders 指定的顺序组合地理编码服务
+//
+//	没有指定时，有本地地名库则只用地名库，否则只用百度地图。在线服务按 --geocode-qps 限速。
+//	offline 为 true 时（如 --replay）不访问网络，只使用缓存和本地地名库
+func newGeocoder(c *cli.Context, offline bool) (geocoder.Geocoder, error) {
+	qps, err := parseGeocodeQPS(c.StringSlice("geocode-qps"))
+	if err != nil {
+		return geocoder.Geocoder{}, err
+	}
+	names := c.StringSlice("geocoders")
+	if len(names) == 0 {
+		if len(c.String("gazetteer")) > 0 {
+			names = []string{"gazetteer"}
+		} else {
+			names = []string{"baidu"}
+		}
+	}
+	var gs []geocoder.Geocoder
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if offline && (name == "baidu" || name == "amap" || name == "tianditu") {
+			log.Infof("离线模式，不使用在线地理编码服务 %s，只使用缓存", name)
+			continue
+		}
+		switch name {
+		case "baidu":
+			gs = append(gs, geocoder.NewGeocoderBaidu(c.String("key_baidu_map"), "").WithRateLimit(qps[name]))
+		case "amap":
+			gs = append(gs, geocoder.NewGeocoderAMAP(c.String("key_amap"), "").WithRateLimit(qps[name]))
+		case "tianditu":
+			gs = append(gs, geocoder.NewGeocoderTianditu(c.String("key_tianditu"), "").WithRateLimit(qps[name]))
+		case "gazetteer":
+			g, err := geocoder.NewGeocoderGazetteer(c.String("gazetteer"))
+			if err != nil {
+				return geocoder.Geocoder{}, err
+			}
+			gs = append(gs, g.WithRateLimit(qps[name]))
+		default:
+			return geocoder.Geocoder{}, fmt.Errorf("不支持的地理编码服务 %q，可选 baidu、amap、tianditu、gazetteer", name)
+		}
+	}
+	gc := geocoder.NewGeocoderChain(c.String("geo_cache"), gs...)
+	if len(gs) == 0 {
+		log.Infof("地理编码服务：只使用缓存 %q", c.String("geo_cache"))
+	} else {
+		log.Infof("地理编码服务：%s", gc.Name())
+	}
+	return gc, nil
+}
+
 func actionCrawlDaily(c *cli.Context) error {
 	var ds model.Dailys
 	var rs model.Residents
@@ -123,39 +168,142 @@ func actionCrawlDaily(c *cli.Context) error {
 	var rs_old model.Residents
 
 	city := c.String("city")
+	info, err := crawler.GetCity(city)
+	if err != nil {
+		return err
+	}
 	file_daily := strings.ReplaceAll(c.String("daily"), "{city}", city)
 	file_daily_csv := file_daily + ".csv"
 	file_daily_json := file_daily + ".json"
 	file_residents := strings.ReplaceAll(c.String("residents"), "{city}", city)
 	file_residents_csv := file_residents + ".csv"
 	file_residents_json := file_residents + ".json"
+	file_streets := strings.ReplaceAll(c.String("streets"), "{city}", city)
+	file_streets_csv := file_streets + ".csv"
+	file_streets_json := file_streets + ".json"
+	file_zones_json := strings.ReplaceAll(c.String("zones"), "{city}", city) + ".json"
+	file_deaths_csv := strings.ReplaceAll(c.String("deaths"), "{city}", city) + ".csv"
+	file_chains := strings.ReplaceAll(c.String("chains"), "{city}", city)
+	file_chains_csv := file_chains + ".csv"
+	file_chains_graphml := file_chains + ".graphml"
+
+	since, err := parseDate(c.String("since"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --since=%q: %s", c.String("since"), err)
+	}
+	until, err := parseDate(c.String("until"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --until=%q: %s", c.String("until"), err)
+	}
+	has_range := !since.IsZero() || !until.IsZero()
+
+	file_record := c.String("record")
+	file_replay := c.String("replay")
+
+	var archive_record, archive_replay *crawler.Archive
+	if len(file_replay) > 0 {
+		var err error
+		if archive_replay, err = crawler.OpenArchive(file_replay); err != nil {
+			return fmt.Errorf("无法打开存档 %q: %s", file_replay, err)
+		}
+	}
+	if len(file_record) > 0 {
+		var err error
+		if archive_record, err = crawler.NewArchive(file_record); err != nil {
+			return fmt.Errorf("无法建立存档 %q: %s", file_record, err)
+		}
+	}
 
 	ds_old.LoadFromJSON(file_daily_json)
 	rs_old.LoadFromJSON(file_residents_json)
-
-	var districts []string
-	switch city {
-	case "beijing":
-		districts = crawler.DailyParserBeijing{}.GetDistricts()
-	case "shanghai":
-		districts = crawler.DailyParserShanghai{}.GetDistricts()
+	//	旧数据可能没有街道，先从居住地补充，避免仅因街道不同而报告数据不一致
+	crawler.FillStreets(rs_old)
+	//	封控分级在保存前按名单重新标记，这里清除，以免仅因分级不同而报告数据不一致
+	for i := range rs_old {
+		rs_old[i].Zone = ""
 	}
 
+	districts := info.Districts
+
 	stats := make(map[time.Time]int, 0)
 	ch := make(chan model.Resident)
 
 	// log.Tracef("geo_cache: %q, web_cache: %q", c.String("geo_cache"), c.String("web_cache"))
 
-	gc := geocoder.NewGeocoderBaidu(c.String("key_baidu_map"), c.String("geo_cache"))
+	gc, err := newGeocoder(c, len(file_replay) > 0)
+	if err != nil {
+		return err
+	}
 	defer gc.Close()
 
-	go consume(&gc, &rs, &stats, ch)
+	var bs *geocoder.Boundaries
+	if file_boundaries := c.String("boundaries"); len(file_boundaries) > 0 {
+		bs, err = geocoder.LoadBoundariesFromFile(file_boundaries)
+	} else {
+		bs, err = geocoder.LoadBoundaries(city)
+	}
+	if err != nil {
+		return err
+	}
+	if bs.Len() == 0 {
+		log.Warnf("没有 %s 的区界，不检查坐标是否在所属的区内", city)
+		bs = nil
+	} else {
+		var missing []string
+		for _, d := range info.Districts {
+			if !bs.Has(d) {
+				missing = append(missing, d)
+			}
+		}
+		if len(missing) > 0 {
+			log.Warnf("%s 的区界缺少 %d 个区，这些区不检查坐标：%s", city, len(missing), strings.Join(missing, "、"))
+		}
+	}
+
+	//	地理编码全部完成后关闭 done
+	done := make(chan struct{})
+	go func() {
+		consume(&gc, bs, c.Int("geocode-workers"), &rs, &stats, ch)
+		close(done)
+	}()
 
 	var web_cache string
 	if !c.Bool("no-cache") {
 		web_cache = c.String("web_cache")
 	}
-	crawler := crawler.NewDailyCrawler(c.String("city"), web_cache)
+	crawler, err := crawler.NewDailyCrawler(city, web_cache)
+	if err != nil {
+		return err
+	}
+	if len(file_replay) > 0 {
+		crawler.Replay(archive_replay)
+		log.Infof("从存档重放页面：%s", file_replay)
+	}
+	if has_range {
+		crawler.SetDateRange(since, until)
+		log.Infof("抓取日期范围：%s ~ %s", c.String("since"), c.String("until"))
+	}
+	if c.Bool("incremental") {
+		if latest := ds_old.Latest(); !latest.IsZero() {
+			crawler.SetIncremental(latest)
+			log.Infof("增量抓取：已有数据最新日期为 %s", latest.Format("2006-01-02"))
+		} else {
+			log.Warnf("增量抓取：%q 中没有已有数据，将进行完整抓取", file_daily_json)
+		}
+	}
+	if len(file_record) > 0 {
+		crawler.Record(archive_record)
+		log.Infof("记录页面到存档：%s", file_record)
+	}
+	file_coverage := c.String("coverage")
+	if len(file_coverage) > 0 {
+		switch strings.ToLower(filepath.Ext(file_coverage)) {
+		case ".csv", ".md", ".markdown":
+		default:
+			return fmt.Errorf("不支持的报告格式 %q，请使用 .csv 或 .md", file_coverage)
+		}
+		crawler.EnableCoverage()
+	}
 	crawler.AddOnDailyListener(func(cs model.Daily) {
 		d := ds.Find(cs.Date)
 		if d == nil {
@@ -192,12 +340,19 @@ func actionCrawlDaily(c *cli.Context) error {
 	})
 	crawler.Collect()
 
-	//	爬虫结束
+	//	爬虫结束，等待地理编码完成
 	close(ch)
+	<-done
 
 	// bar.Finish()
 	log.Infof("总共得到 %d 天疫情数据。", len(ds))
 
+	if len(file_coverage) > 0 {
+		if err := saveCoverage(crawler.EnableCoverage(), file_coverage); err != nil {
+			return err
+		}
+	}
+
 	//	排序
 	ds.Sort()
 
@@ -239,8 +394,18 @@ func actionCrawlDaily(c *cli.Context) error {
 	}
 
 	//	用新的数据更新旧的，以增加新的数据，但是要检查旧数据是否有所改动
-	ds = update(ds_old, ds, true)
-	rs = update(rs_old, rs, false)
+	if has_range {
+		//	指定了日期范围，说明需要重新合并该范围内的数据，以新抓取的为准
+		ds = replace(ds_old, ds, func(d model.Daily) time.Time { return d.Date })
+		rs = replace(rs_old, rs, func(r model.Resident) time.Time { return r.Date })
+	} else {
+		//	旧数据可能没有死亡病例详情和字段来源，先合并过来，避免仅因此报告数据不一致
+		ds_old.MergeDeathCases(ds)
+		ds_old.MergeProvenance(ds)
+		ds = update(ds_old, ds, true)
+		rs_old.MergeLinkage(rs)
+		rs = update(rs_old, rs, false)
+	}
 
 	//	将最终结果写入文件
 	ds.Sort()
@@ -252,7 +417,12 @@ func actionCrawlDaily(c *cli.Context) error {
 		return fmt.Errorf("无法写入文件(daily) %q: %s", file_daily_json, err)
 	}
 
+	if err := ds.DeathCases().SaveToCSV(file_deaths_csv); err != nil {
+		return fmt.Errorf("无法写入文件(deaths) %q: %s", file_deaths_csv, err)
+	}
+
 	rs.Sort()
+	joinZones(rs, file_zones_json)
 	if err := rs.SaveToCSV(file_residents_csv); err != nil {
 		return fmt.Errorf("无法写入文件(resident) %q: %s", file_residents_csv, err)
 	}
@@ -260,5 +430,483 @@ func actionCrawlDaily(c *cli.Context) error {
 		return fmt.Errorf("无法写入文件(resident) %q: %s", file_residents_json, err)
 	}
 
+	//	按街道/乡镇汇总
+	ss := rs.Streets()
+	if err := ss.SaveToCSV(file_streets_csv); err != nil {
+		return fmt.Errorf("无法写入文件(street) %q: %s", file_streets_csv, err)
+	}
+	if err := ss.SaveToJSON(file_streets_json); err != nil {
+		return fmt.Errorf("无法写入文件(street) %q: %s", file_streets_json, err)
+	}
+
+	//	传播链
+	es := rs.ChainEdges()
+	if err := es.SaveToCSV(file_chains_csv); err != nil {
+		return fmt.Errorf("无法写入文件(chains) %q: %s", file_chains_csv, err)
+	}
+	if err := es.SaveToGraphML(file_chains_graphml); err != nil {
+		return fmt.Errorf("无法写入文件(chains) %q: %s", file_chains_graphml, err)
+	}
+
+	return nil
+}
+
+func actionCrawlRiskAreas(c *cli.Context) error {
+	var as_old model.RiskAreas
+	var ns model.RiskAreaNotices
+	var lock sync.Mutex
+
+	city := c.String("city")
+	file_output := strings.ReplaceAll(c.String("output"), "{city}", city)
+	file_output_csv := file_output + ".csv"
+	file_output_json := file_output + ".json"
+
+	since, err := parseDate(c.String("since"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --since=%q: %s", c.String("since"), err)
+	}
+	until, err := parseDate(c.String("until"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --until=%q: %s", c.String("until"), err)
+	}
+
+	var web_cache string
+	if !c.Bool("no-cache") {
+		web_cache = c.String("web_cache")
+	}
+	dc, err := crawler.NewDailyCrawler(city, web_cache)
+	if err != nil {
+		return err
+	}
+	if err := dc.SetMode(crawler.CRAWLER_MODE_RISK_AREAS); err != nil {
+		return fmt.Errorf("城市 %q: %s", city, err)
+	}
+	if file_replay := c.String("replay"); len(file_replay) > 0 {
+		archive, err := crawler.OpenArchive(file_replay)
+		if err != nil {
+			return fmt.Errorf("无法打开存档 %q: %s", file_replay, err)
+		}
+		dc.Replay(archive)
+		log.Infof("从存档重放页面：%s", file_replay)
+	}
+	if !since.IsZero() || !until.IsZero() {
+		dc.SetDateRange(since, until)
+		log.Infof("抓取日期范围：%s ~ %s", c.String("since"), c.String("until"))
+	}
+
+	dc.AddOnRiskAreasListener(func(ns2 model.RiskAreaNotices) {
+		lock.Lock()
+		defer lock.Unlock()
+		ns = append(ns, ns2...)
+	})
+	dc.Collect()
+	log.Infof("总共得到 %d 条风险地区调整。", len(ns))
+
+	//	与已有的风险时期合并，保留历史
+	as_old.LoadFromJSON(file_output_json)
+	as := as_old.Merge(ns)
+
+	if err := as.SaveToCSV(file_output_csv); err != nil {
+		return fmt.Errorf("无法写入文件(risk areas) %q: %s", file_output_csv, err)
+	}
+	if err := as.SaveToJSON(file_output_json); err != nil {
+		return fmt.Errorf("无法写入文件(risk areas) %q: %s", file_output_json, err)
+	}
+	return nil
+}
+
+func actionCrawlZones(c *cli.Context) error {
+	var zs_old model.Zones
+	var zs model.Zones
+	var lock sync.Mutex
+
+	city := c.String("city")
+	file_output := strings.ReplaceAll(c.String("output"), "{city}", city)
+	file_output_csv := file_output + ".csv"
+	file_output_json := file_output + ".json"
+	file_residents := strings.ReplaceAll(c.String("residents"), "{city}", city)
+
+	since, err := parseDate(c.String("since"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --since=%q: %s", c.String("since"), err)
+	}
+	until, err := parseDate(c.String("until"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --until=%q: %s", c.String("until"), err)
+	}
+
+	var web_cache string
+	if !c.Bool("no-cache") {
+		web_cache = c.String("web_cache")
+	}
+	dc, err := crawler.NewDailyCrawler(city, web_cache)
+	if err != nil {
+		return err
+	}
+	if err := dc.SetMode(crawler.CRAWLER_MODE_ZONES); err != nil {
+		return fmt.Errorf("城市 %q: %s", city, err)
+	}
+	dc.AddItemLinks(c.StringSlice("link")...)
+	if file_replay := c.String("replay"); len(file_replay) > 0 {
+		archive, err := crawler.OpenArchive(file_replay)
+		if err != nil {
+			return fmt.Errorf("无法打开存档 %q: %s", file_replay, err)
+		}
+		dc.Replay(archive)
+		log.Infof("从存档重放页面：%s", file_replay)
+	}
+	if !since.IsZero() || !until.IsZero() {
+		dc.SetDateRange(since, until)
+		log.Infof("抓取日期范围：%s ~ %s", c.String("since"), c.String("until"))
+	}
+
+	dc.AddOnZonesListener(func(zs2 model.Zones) {
+		lock.Lock()
+		defer lock.Unlock()
+		log.Infof("[%s] %s：%d 个小区", zs2[0].Date.Format("2006-01-02"), zs2[0].District, len(zs2))
+		zs = append(zs, zs2...)
+	})
+	dc.Collect()
+	log.Infof("总共得到 %d 条封控区、管控区、防范区记录。", len(zs))
+
+	//	与已有名单合并，保留历史
+	zs_old.LoadFromJSON(file_output_json)
+	zs = zs_old.Merge(zs)
+
+	if err := zs.SaveToCSV(file_output_csv); err != nil {
+		return fmt.Errorf("无法写入文件(zones) %q: %s", file_output_csv, err)
+	}
+	if err := zs.SaveToJSON(file_output_json); err != nil {
+		return fmt.Errorf("无法写入文件(zones) %q: %s", file_output_json, err)
+	}
+
+	//	更新已有居住地信息的封控分级
+	var rs model.Residents
+	if err := rs.LoadFromJSON(file_residents + ".json"); err != nil {
+		log.Warnf("无法读取居住地信息 %q，跳过封控分级标记：%s", file_residents+".json", err)
+		return nil
+	}
+	rs.JoinZones(zs)
+	if err := rs.SaveToCSV(file_residents + ".csv"); err != nil {
+		return fmt.Errorf("无法写入文件(resident) %q: %s", file_residents+".csv", err)
+	}
+	if err := rs.SaveToJSON(file_residents + ".json"); err != nil {
+		return fmt.Errorf("无法写入文件(resident) %q: %s", file_residents+".json", err)
+	}
+	return nil
+}
+
+func actionCrawlBriefings(c *cli.Context) error {
+	var bs_old model.Briefings
+	var bs model.Briefings
+	var lock sync.Mutex
+
+	city := c.String("city")
+	file_output := strings.ReplaceAll(c.String("output"), "{city}", city)
+	file_output_csv := file_output + ".csv"
+	file_output_json := file_output + ".json"
+
+	since, err := parseDate(c.String("since"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --since=%q: %s", c.String("since"), err)
+	}
+	until, err := parseDate(c.String("until"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --until=%q: %s", c.String("until"), err)
+	}
+
+	var web_cache string
+	if !c.Bool("no-cache") {
+		web_cache = c.String("web_cache")
+	}
+	dc, err := crawler.NewDailyCrawler(city, web_cache)
+	if err != nil {
+		return err
+	}
+	if err := dc.SetMode(crawler.CRAWLER_MODE_BRIEFINGS); err != nil {
+		return fmt.Errorf("城市 %q: %s", city, err)
+	}
+	dc.AddItemLinks(c.StringSlice("link")...)
+	if file_replay := c.String("replay"); len(file_replay) > 0 {
+		archive, err := crawler.OpenArchive(file_replay)
+		if err != nil {
+			return fmt.Errorf("无法打开存档 %q: %s", file_replay, err)
+		}
+		dc.Replay(archive)
+		log.Infof("从存档重放页面：%s", file_replay)
+	}
+	if !since.IsZero() || !until.IsZero() {
+		dc.SetDateRange(since, until)
+		log.Infof("抓取日期范围：%s ~ %s", c.String("since"), c.String("until"))
+	}
+
+	dc.AddOnBriefingListener(func(b model.Briefing) {
+		lock.Lock()
+		defer lock.Unlock()
+		bs = append(bs, b)
+	})
+	dc.Collect()
+	log.Infof("总共得到 %d 场新闻发布会的数据。", len(bs))
+
+	//	同一天的数据以新抓取的为准
+	bs_old.LoadFromJSON(file_output_json)
+	bs = replace(bs_old, bs, func(b model.Briefing) time.Time { return b.Date })
+	bs.Sort()
+
+	if err := bs.SaveToCSV(file_output_csv); err != nil {
+		return fmt.Errorf("无法写入文件(briefings) %q: %s", file_output_csv, err)
+	}
+	if err := bs.SaveToJSON(file_output_json); err != nil {
+		return fmt.Errorf("无法写入文件(briefings) %q: %s", file_output_json, err)
+	}
+	return nil
+}
+
+// 从已有的居住地信息归并聚集性疫情
+func actionClusters(c *cli.Context) error {
+	var rs model.Residents
+
+	city := c.String("city")
+	file_residents_json := strings.ReplaceAll(c.String("residents"), "{city}", city) + ".json"
+	file_output := strings.ReplaceAll(c.String("output"), "{city}", city)
+	file_output_csv := file_output + ".csv"
+	file_output_json := file_output + ".json"
+
+	if err := rs.LoadFromJSON(file_residents_json); err != nil {
+		return fmt.Errorf("无法读取文件 %q: %s", file_residents_json, err)
+	}
+	date, err := parseDate(c.String("date"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --date=%q: %s", c.String("date"), err)
+	}
+	if date.IsZero() {
+		for _, r := range rs {
+			if r.Date.After(date) {
+				date = r.Date
+			}
+		}
+	}
+
+	cs := rs.Clusters()
+	if err := cs.SaveToCSV(file_output_csv); err != nil {
+		return fmt.Errorf("无法写入文件(clusters) %q: %s", file_output_csv, err)
+	}
+	if err := cs.SaveToJSON(file_output_json); err != nil {
+		return fmt.Errorf("无法写入文件(clusters) %q: %s", file_output_json, err)
+	}
+
+	active := cs.Active(date)
+	log.Infof("共 %d 起聚集性疫情，截至 %s 仍在活跃的有 %d 起", len(cs), date.Format("2006-01-02"), len(active))
+	for _, a := range active {
+		fmt.Printf("%s\t截至当日 %d 例\n", a, a.SizeOn(date))
+	}
+	return nil
+}
+
+// 导入 DataV 等来源的 GCJ-02 区界，转换为 WGS84 后作为内置区界
+func actionImportBoundaries(c *cli.Context) error {
+	city := c.String("city")
+	file_input := c.String("input")
+	file_output := strings.ReplaceAll(c.String("output"), "{city}", city)
+
+	in, err := os.Open(file_input)
+	if err != nil {
+		return fmt.Errorf("无法读取文件 %q: %s", file_input, err)
+	}
+	defer in.Close()
+	out, err := os.Create(file_output)
+	if err != nil {
+		return fmt.Errorf("无法写入文件(boundaries) %q: %s", file_output, err)
+	}
+	defer out.Close()
+
+	n, err := geocoder.ImportBoundaries(in, out)
+	if err != nil {
+		return fmt.Errorf("无法导入区界 %q: %s", file_input, err)
+	}
+	log.Infof("已导入 %d 个区的区界：%s => %s，重新编译后生效", n, file_input, file_output)
+	return nil
+}
+
+// 按名单文件标记居住地信息的封控分级，没有名单文件时不标记
+func joinZones(rs model.Residents, filename string) {
+	var zs model.Zones
+	if err := zs.LoadFromJSON(filename); err != nil {
+		return
+	}
+	rs.JoinZones(zs)
+	n := 0
+	for _, r := range rs {
+		if len(r.Zone) > 0 {
+			n++
+		}
+	}
+	log.Infof("按名单 %q 标记封控分级：%d / %d 条居住地信息在名单中", filename, n, len(rs))
+}
+
+func actionListCities(c *cli.Context) error {
+	for _, city := range crawler.Cities() {
+		fmt.Printf("%-10s\t%s\t%s\t%d 个分区：%s\n",
+			city.Key,
+			city.Name,
+			city.TimeZone,
+			len(city.Districts),
+			strings.Join(city.Districts, ", "),
+		)
+	}
+	return nil
+}
+
+func actionReconcile(c *cli.Context) error {
+	var left, right model.Dailys
+
+	file_left := c.String("left")
+	file_right := c.String("right")
+	if err := left.LoadFromJSON(file_left); err != nil {
+		return fmt.Errorf("无法读取文件 %q: %s", file_left, err)
+	}
+	if err := right.LoadFromJSON(file_right); err != nil {
+		return fmt.Errorf("无法读取文件 %q: %s", file_right, err)
+	}
+
+	fields := c.StringSlice("field")
+	if province := c.String("province"); len(province) > 0 {
+		right = right.Province(province)
+		if len(fields) == 0 {
+			//	全国通报中只有这几项能对应到省份
+			fields = []string{"LocalPositive", "LocalConfirmed", "LocalAsymptomatic", "LocalConfirmedFromAsymptomatic"}
+		}
+	}
+
+	cs := model.ReconcileDailys(left, right, fields, c.Bool("skip-zero"))
+
+	output := c.String("output")
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".csv":
+		if err := cs.SaveToCSV(output); err != nil {
+			return fmt.Errorf("无法写入文件(reconcile) %q: %s", output, err)
+		}
+	case ".md", ".markdown":
+		if err := cs.SaveToMarkdown(output); err != nil {
+			return fmt.Errorf("无法写入文件(reconcile) %q: %s", output, err)
+		}
+	default:
+		return fmt.Errorf("不支持的报告格式 %q，请使用 .csv 或 .md", output)
+	}
+
+	days := make(map[string]bool)
+	for _, conflict := range cs {
+		days[conflict.Key] = true
+	}
+	log.Infof("共有 %d 天存在 %d 处不一致，报告已写入 %q", len(days), len(cs), output)
+	return nil
+}
+
+func actionValidate(c *cli.Context) error {
+	files := c.Args().Slice()
+	if len(files) == 0 {
+		var err error
+		if files, err = filepath.Glob(DEFAULT_FILES_VALIDATE); err != nil {
+			return err
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("没有找到需要校验的文件")
+	}
+
+	var all model.Violations
+	for _, file := range files {
+		var ds model.Dailys
+		if err := ds.LoadFromJSON(file); err != nil {
+			return fmt.Errorf("无法读取文件 %q: %s", file, err)
+		}
+		vs := ds.Validate()
+		for _, v := range vs {
+			if v.Severity == model.SeverityError {
+				log.Errorf("%s: %s", file, v)
+			} else if !c.Bool("errors-only") {
+				log.Warnf("%s: %s", file, v)
+			}
+		}
+		log.Infof("%s: %d 天数据，%d 个错误，%d 个警告", file, len(ds), vs.Count(model.SeverityError), vs.Count(model.SeverityWarning))
+		all = append(all, vs...)
+	}
+
+	if output := c.String("output"); len(output) > 0 {
+		if err := all.SaveToCSV(output); err != nil {
+			return fmt.Errorf("无法写入文件(validate) %q: %s", output, err)
+		}
+	}
+
+	if all.HasErrors() {
+		return cli.Exit(fmt.Sprintf("校验失败：共 %d 个错误", all.Count(model.SeverityError)), 1)
+	}
+	return nil
+}
+
+func actionExplain(c *cli.Context) error {
+	var ds model.Dailys
+
+	city := c.String("city")
+	file_daily_json := strings.ReplaceAll(c.String("daily"), "{city}", city) + ".json"
+	if err := ds.LoadFromJSON(file_daily_json); err != nil {
+		return fmt.Errorf("无法读取文件 %q: %s", file_daily_json, err)
+	}
+
+	date, err := parseDate(c.String("date"))
+	if err != nil {
+		return fmt.Errorf("无法解析日期 --date=%q: %s", c.String("date"), err)
+	}
+	var d *model.Daily
+	for i := range ds {
+		if ds[i].Key() == date.Format("2006-01-02") {
+			d = &ds[i]
+			break
+		}
+	}
+	if d == nil {
+		return fmt.Errorf("%q 中没有 %s 的数据", file_daily_json, date.Format("2006-01-02"))
+	}
+
+	fields := c.StringSlice("field")
+	if len(fields) == 0 {
+		fields = d.PopulatedFields()
+	}
+	fmt.Printf("[%s] %s\n", d.Key(), d.Source)
+	for _, field := range fields {
+		value, ok := d.Field(field)
+		if !ok {
+			return fmt.Errorf("model.Daily 中没有字段 %q", field)
+		}
+		fmt.Printf("\n%s = %v\n", field, value)
+		if p, ok := d.Provenance[field]; ok {
+			fmt.Printf("\t来源：%s\n\t提取：%s\n\t依据：%s\n", p.Kind, p.Extractor, p.Snippet)
+		} else {
+			fmt.Printf("\t来源：未记录（请重新抓取该日期的数据）\n")
+		}
+	}
+	return nil
+}
+
+func saveCoverage(cv *crawler.Coverage, filename string) error {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		if err := cv.SaveToCSV(filename); err != nil {
+			return fmt.Errorf("无法写入文件(coverage) %q: %s", filename, err)
+		}
+	case ".md", ".markdown":
+		if err := cv.SaveToMarkdown(filename); err != nil {
+			return fmt.Errorf("无法写入文件(coverage) %q: %s", filename, err)
+		}
+	default:
+		return fmt.Errorf("不支持的报告格式 %q，请使用 .csv 或 .md", filename)
+	}
+
+	total, unmatched := cv.Summary()
+	if unmatched > 0 {
+		log.Warnf("解析覆盖率：共 %d 篇通报，其中 %d 篇存在未识别的数字句子，报告已写入 %q", total, unmatched, filename)
+	} else {
+		log.Infof("解析覆盖率：共 %d 篇通报，全部数字句子均已识别", total)
+	}
 	return nil
 }
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crawler/geocoder"
+	"crawler/model"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// 地理编码的默认并发数
+const DEFAULT_GEOCODE_WORKERS = 4
+
+// 各服务默认的每秒请求数，可以按账号的配额用 --geocode-qps 调整，本地地名库不限速
+var DEFAULT_GEOCODE_QPS = map[string]float64{
+	"baidu":    10,
+	"amap":     3,
+	"tianditu": 5,
+}
+
+// 解析 --geocode-qps，如 baidu=30,amap=10
+func parseGeocodeQPS(items []string) (map[string]float64, error) {
+	qps := make(map[string]float64, len(DEFAULT_GEOCODE_QPS))
+	for k, v := range DEFAULT_GEOCODE_QPS {
+		qps[k] = v
+	}
+	for _, item := range items {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("无法解析 --geocode-qps=%q，格式应为 服务=每秒请求数", item)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("无法解析 --geocode-qps=%q: %s", item, err)
+		}
+		qps[strings.TrimSpace(kv[0])] = v
+	}
+	return qps, nil
+}
+
+// 一组连续的居住地信息，seq 为其在输入中的顺序
+type geocodeJob struct {
+	seq int
+	rs  model.Residents
+}
+
+// 并发地理编码
+//
+//	按输入顺序把居住地信息分组（服务有批量接口时每组 BATCH_SIZE_LIMIT 条，否则每组 1 条），
+//	由 workers 个 goroutine 并发解析，再按原有顺序追加到 rs 中。限速由各服务自己负责。
+func consume(gc *geocoder.Geocoder, bs *geocoder.Boundaries, workers int, rs *model.Residents, stats *map[time.Time]int, in chan model.Resident) {
+	if workers < 1 {
+		workers = 1
+	}
+	size := 1
+	if gc != nil && gc.SupportsBatch() {
+		size = geocoder.BATCH_SIZE_LIMIT
+	}
+
+	jobs := make(chan geocodeJob, workers)
+	results := make(chan geocodeJob, workers)
+
+	//	分组
+	go func() {
+		seq := 0
+		var chunk model.Residents
+		for r := range in {
+			chunk = append(chunk, r)
+			if len(chunk) >= size {
+				jobs <- geocodeJob{seq: seq, rs: chunk}
+				seq++
+				chunk = nil
+			}
+		}
+		if len(chunk) > 0 {
+			jobs <- geocodeJob{seq: seq, rs: chunk}
+		}
+		close(jobs)
+	}()
+
+	//	解析
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				geocodeResidents(gc, bs, job.rs)
+				results <- job
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	//	按原有顺序追加
+	pending := make(map[int]model.Residents)
+	next := 0
+	count := 0
+	for job := range results {
+		pending[job.seq] = job.rs
+		for {
+			chunk, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			for _, r := range chunk {
+				if r.Longitude != 0 && r.Latitude != 0 {
+					count++
+					if count%100000 == 0 {
+						fmt.Println()
+					} else if count%10000 == 0 {
+						fmt.Print(":")
+					} else if count%1000 == 0 {
+						fmt.Print(".")
+					}
+				}
+				//	追加
+				*rs = append(*rs, r)
+				//	统计
+				(*stats)[r.Date]++
+			}
+		}
+	}
+
+	fmt.Println()
+}
+
+// 解析一组居住地信息的坐标
+func geocodeResidents(gc *geocoder.Geocoder, bs *geocoder.Boundaries, rs model.Residents) {
+	if gc == nil || len(rs) == 0 {
+		return
+	}
+	addrs := make([]string, len(rs))
+	for i, r := range rs {
+		addrs[i] = fmt.Sprintf("%s%s%s", r.City, r.District, r.Address)
+	}
+
+	as := make([]geocoder.Address, len(addrs))
+	errs := make([]error, len(addrs))
+	retry := len(addrs) == 1
+	if len(addrs) > 1 {
+		var err error
+		if as, err = gc.GeocodeInBatch(addrs); err != nil {
+			//	批量请求失败，坐标为 0 的地址逐个重试，以便仍能依次尝试其它服务
+			log.Warnf("%s，逐个重试", err)
+			retry = true
+		}
+	}
+	if retry {
+		for i := range as {
+			if as[i].Longitude != 0 && as[i].Latitude != 0 {
+				continue
+			}
+			a, err := gc.Geocode(addrs[i])
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			as[i] = *a
+		}
+	}
+
+	for i := range rs {
+		a := &as[i]
+		if errs[i] != nil {
+			log.Warnf("解析地址 %q 失败：%s", addrs[i], errs[i])
+			continue
+		}
+		if a.Longitude == 0 || a.Latitude == 0 {
+			log.Warnf("解析地址 %q 失败：坐标为 0", addrs[i])
+			continue
+		}
+		if bs != nil {
+			a = checkDistrict(gc, bs, &rs[i], addrs[i], a)
+		}
+		rs[i].Longitude = a.Longitude
+		rs[i].Latitude = a.Latitude
+		rs[i].Precision = string(a.Precision)
+		rs[i].Confidence = a.Confidence
+	}
+}
+
+// 检查坐标是否在所属的区内，不在时依次换用其它服务重新查询，仍不在则标记坐标实际所在的区
+func checkDistrict(gc *geocoder.Geocoder, bs *geocoder.Boundaries, r *model.Resident, s string, addr *geocoder.Address) *geocoder.Address {
+	if bs.Contains(r.District, addr.Longitude, addr.Latitude) {
+		return addr
+	}
+	inside := func(a geocoder.Address) bool { return bs.Contains(r.District, a.Longitude, a.Latitude) }
+	if a, err := gc.GeocodeWithin(s, inside); err == nil {
+		log.Debugf("地址 %q 的坐标不在%s内，已由 %s 重新解析", s, r.District, a.Provider)
+		return a
+	}
+	r.GeoDistrict = bs.Locate(addr.Longitude, addr.Latitude)
+	if len(r.GeoDistrict) == 0 {
+		r.GeoDistrict = "市外"
+	}
+	log.Warnf("[%s] 地址 %q 的坐标 (%.5f, %.5f) 位于%s，不在%s内", r.Date.Format("2006-01-02"), s, addr.Longitude, addr.Latitude, r.GeoDistrict, r.District)
+	return addr
+}
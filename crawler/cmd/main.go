@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crawler/crawler"
 	"io"
 	"net/http"
 	_ "net/http/pprof"
@@ -17,6 +18,16 @@ const (
 	DEFAULT_CITY           = "shanghai"
 	DEFAULT_FILE_DAILY     = "../data/{city}-daily"
 	DEFAULT_FILE_RESIDENTS = "../data/{city}-residents"
+	DEFAULT_FILE_STREETS   = "../data/{city}-streets"
+	DEFAULT_FILE_RISKAREAS = "../data/{city}-risk-areas"
+	DEFAULT_FILE_ZONES     = "../data/{city}-zones"
+	DEFAULT_FILE_BRIEFINGS = "../data/{city}-briefings"
+	DEFAULT_FILE_DEATHS    = "../data/{city}-deaths"
+	DEFAULT_FILE_CHAINS    = "../data/{city}-chains"
+	DEFAULT_FILE_CLUSTERS  = "../data/{city}-clusters"
+	DEFAULT_FILE_BOUNDARY  = "./geocoder/boundaries/{city}.geojson"
+	DEFAULT_FILE_RECONCILE = "../data/reconcile.md"
+	DEFAULT_FILES_VALIDATE = "../data/*-daily.json"
 	DEFAULT_FILE_LOG       = "../data/crawler.log"
 )
 
@@ -59,6 +70,32 @@ func main() {
 				Name:  "geo_cache",
 				Value: "../data/.geo_cache",
 			},
+			&cli.StringFlag{
+				Name:    "gazetteer",
+				Usage:   "本地地名库（.csv 或 .geojson），指定且没有指定 --geocoders 时不再调用在线地图 API",
+				EnvVars: []string{"GAZETTEER"},
+			},
+			&cli.StringFlag{
+				Name:  "boundaries",
+				Usage: "区界 GeoJSON，用于检查坐标是否在所属的区内；不指定时使用内置的区界",
+			},
+			&cli.StringSliceFlag{
+				Name:  "geocoders",
+				Usage: "按顺序尝试的地理编码服务，前一个失败或坐标为 0 时改用下一个，可选 baidu、amap、tianditu、gazetteer，如 --geocoders=baidu,amap,tianditu",
+			},
+			&cli.IntFlag{
+				Name:  "geocode-workers",
+				Value: DEFAULT_GEOCODE_WORKERS,
+				Usage: "并发地理编码的数量",
+			},
+			&cli.StringSliceFlag{
+				Name:  "geocode-qps",
+				Usage: "各地理编码服务每秒最多的请求数，批量请求算一次，0 为不限速，如 --geocode-qps=baidu=30,amap=10；默认 baidu=10、amap=3、tianditu=5",
+			},
+			&cli.StringFlag{
+				Name:  "parsers",
+				Usage: "从目录中加载 YAML/JSON 解析器定义，同名城市会覆盖内置解析器",
+			},
 		},
 		Commands: []*cli.Command{
 			{
@@ -75,6 +112,31 @@ func main() {
 						Name:  "no-cache",
 						Value: false,
 					},
+					&cli.StringFlag{
+						Name:  "since",
+						Usage: "只抓取该日期及以后的通报，格式如 2022-04-01",
+					},
+					&cli.StringFlag{
+						Name:  "until",
+						Usage: "只抓取该日期及以前的通报，格式如 2022-04-30",
+					},
+					&cli.BoolFlag{
+						Name:  "incremental",
+						Usage: "增量抓取，只抓取已有数据中最新日期及以后的通报",
+						Value: false,
+					},
+					&cli.StringFlag{
+						Name:  "record",
+						Usage: "将抓取到的页面记录到指定存档目录",
+					},
+					&cli.StringFlag{
+						Name:  "replay",
+						Usage: "不访问网络，只从指定存档目录重放页面",
+					},
+					&cli.StringFlag{
+						Name:  "coverage",
+						Usage: "统计解析覆盖率，将未识别的数字句子写入报告文件，按扩展名输出 .csv 或 .md",
+					},
 					&cli.StringFlag{
 						Name:    "daily",
 						Aliases: []string{"d"},
@@ -87,9 +149,279 @@ func main() {
 						Value:   DEFAULT_FILE_RESIDENTS,
 						// Required:    true,
 					},
+					&cli.StringFlag{
+						Name:  "streets",
+						Usage: "按街道/乡镇汇总的居住地信息，不含扩展名",
+						Value: DEFAULT_FILE_STREETS,
+					},
+					&cli.StringFlag{
+						Name:  "zones",
+						Usage: "封控区、管控区、防范区名单，不含扩展名，文件存在时用于标记居住地信息的封控分级",
+						Value: DEFAULT_FILE_ZONES,
+					},
+					&cli.StringFlag{
+						Name:  "deaths",
+						Usage: "死亡病例详情，不含扩展名，输出 .csv",
+						Value: DEFAULT_FILE_DEATHS,
+					},
+					&cli.StringFlag{
+						Name:  "chains",
+						Usage: "传播链，不含扩展名，输出边列表 .csv 和 .graphml",
+						Value: DEFAULT_FILE_CHAINS,
+					},
 				},
 				Action: actionCrawlDaily,
 			},
+			{
+				Name:  "risk-areas",
+				Usage: "抓取中高风险地区调整通告，整理出各地区的风险时期",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "city",
+						Aliases: []string{"c"},
+						Value:   DEFAULT_CITY,
+					},
+					&cli.BoolFlag{
+						Name:  "no-cache",
+						Value: false,
+					},
+					&cli.StringFlag{
+						Name:  "since",
+						Usage: "只抓取该日期及以后的通告，格式如 2022-04-01",
+					},
+					&cli.StringFlag{
+						Name:  "until",
+						Usage: "只抓取该日期及以前的通告，格式如 2022-04-30",
+					},
+					&cli.StringFlag{
+						Name:  "replay",
+						Usage: "不访问网络，只从指定存档目录重放页面",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "风险地区文件，不含扩展名，已有的历史会被保留",
+						Value:   DEFAULT_FILE_RISKAREAS,
+					},
+				},
+				Action: actionCrawlRiskAreas,
+			},
+			{
+				Name:  "zones",
+				Usage: "抓取封控区、管控区、防范区名单，并标记已有居住地信息的封控分级",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "city",
+						Aliases: []string{"c"},
+						Value:   DEFAULT_CITY,
+					},
+					&cli.StringSliceFlag{
+						Name:  "link",
+						Usage: "名单页面链接，可多次指定，用于索引中没有的公众号文章",
+					},
+					&cli.BoolFlag{
+						Name:  "no-cache",
+						Value: false,
+					},
+					&cli.StringFlag{
+						Name:  "since",
+						Usage: "只抓取该日期及以后的名单，格式如 2022-04-01",
+					},
+					&cli.StringFlag{
+						Name:  "until",
+						Usage: "只抓取该日期及以前的名单，格式如 2022-04-30",
+					},
+					&cli.StringFlag{
+						Name:  "replay",
+						Usage: "不访问网络，只从指定存档目录重放页面",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "名单文件，不含扩展名，已有的历史会被保留",
+						Value:   DEFAULT_FILE_ZONES,
+					},
+					&cli.StringFlag{
+						Name:    "residents",
+						Aliases: []string{"r"},
+						Value:   DEFAULT_FILE_RESIDENTS,
+					},
+				},
+				Action: actionCrawlZones,
+			},
+			{
+				Name:  "briefings",
+				Usage: "抓取疫情防控新闻发布会实录，提取死亡病例、方舱医院、检测量等数据",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "city",
+						Aliases: []string{"c"},
+						Value:   DEFAULT_CITY,
+					},
+					&cli.StringSliceFlag{
+						Name:  "link",
+						Usage: "发布会实录页面链接，可多次指定，用于索引中没有的文章",
+					},
+					&cli.BoolFlag{
+						Name:  "no-cache",
+						Value: false,
+					},
+					&cli.StringFlag{
+						Name:  "since",
+						Usage: "只抓取该日期及以后的发布会，格式如 2022-04-01",
+					},
+					&cli.StringFlag{
+						Name:  "until",
+						Usage: "只抓取该日期及以前的发布会，格式如 2022-04-30",
+					},
+					&cli.StringFlag{
+						Name:  "replay",
+						Usage: "不访问网络，只从指定存档目录重放页面",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "发布会数据文件，不含扩展名",
+						Value:   DEFAULT_FILE_BRIEFINGS,
+					},
+				},
+				Action: actionCrawlBriefings,
+			},
+			{
+				Name:  "clusters",
+				Usage: "由居住地信息归并聚集性疫情，列出截至某日仍在活跃的聚集性疫情",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "city",
+						Aliases: []string{"c"},
+						Value:   DEFAULT_CITY,
+					},
+					&cli.StringFlag{
+						Name:  "date",
+						Usage: "列出截至该日期仍在活跃的聚集性疫情，如 2022-05-01；不指定则取最新的居住地信息日期",
+					},
+					&cli.StringFlag{
+						Name:    "residents",
+						Aliases: []string{"r"},
+						Value:   DEFAULT_FILE_RESIDENTS,
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "全部聚集性疫情，不含扩展名",
+						Value:   DEFAULT_FILE_CLUSTERS,
+					},
+				},
+				Action: actionClusters,
+			},
+			{
+				Name:  "boundaries",
+				Usage: "导入 GCJ-02 坐标的区界（如 DataV 的 310000_full.json），转换为 WGS84 后作为内置区界",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "city",
+						Aliases: []string{"c"},
+						Value:   DEFAULT_CITY,
+					},
+					&cli.StringFlag{
+						Name:     "input",
+						Aliases:  []string{"i"},
+						Usage:    "GCJ-02 坐标的区界 GeoJSON，每个区为一个 Feature，properties.name 为区名",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Value:   DEFAULT_FILE_BOUNDARY,
+					},
+				},
+				Action: actionImportBoundaries,
+			},
+			{
+				Name:   "cities",
+				Usage:  "列出支持的城市",
+				Action: actionListCities,
+			},
+			{
+				Name:  "reconcile",
+				Usage: "对比两个来源同一天的每日统计，输出逐字段的冲突报告",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "left",
+						Usage:    "左侧数据文件，如 ../data/shanghai-daily.json",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "right",
+						Usage:    "右侧数据文件，如 ../data/national-daily.json",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "province",
+						Usage: "右侧为全国通报时，取该省份的数据进行对比，如 上海",
+					},
+					&cli.StringSliceFlag{
+						Name:  "field",
+						Usage: "只对比指定字段，可多次指定，如 --field=LocalConfirmed",
+					},
+					&cli.BoolFlag{
+						Name:  "skip-zero",
+						Usage: "任一方为 0 的字段不视为冲突",
+						Value: false,
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "冲突报告文件，按扩展名输出 .csv 或 .md",
+						Value:   DEFAULT_FILE_RECONCILE,
+					},
+				},
+				Action: actionReconcile,
+			},
+			{
+				Name:      "validate",
+				Usage:     "校验每日统计数据的一致性，存在错误时以非零状态退出",
+				ArgsUsage: "[*-daily.json ...]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "errors-only",
+						Usage: "只显示错误，不显示警告",
+						Value: false,
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "将校验结果写入 CSV 文件",
+					},
+				},
+				Action: actionValidate,
+			},
+			{
+				Name:  "explain",
+				Usage: "显示每日统计中字段的值是如何得到的",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "city",
+						Aliases: []string{"c"},
+						Value:   DEFAULT_CITY,
+					},
+					&cli.StringFlag{
+						Name:     "date",
+						Usage:    "通报日期，如 2022-04-21",
+						Required: true,
+					},
+					&cli.StringSliceFlag{
+						Name:  "field",
+						Usage: "只显示指定字段，可多次指定，如 --field=LocalConfirmed；不指定则显示全部有值的字段",
+					},
+					&cli.StringFlag{
+						Name:    "daily",
+						Aliases: []string{"d"},
+						Value:   DEFAULT_FILE_DAILY,
+					},
+				},
+				Action: actionExplain,
+			},
 		},
 		Before: func(c *cli.Context) error {
 			//	profile
@@ -126,6 +458,12 @@ func main() {
 				log.SetLevel(log.DebugLevel)
 			}
 			// log.SetFormatter(&log.TextFormatter{PadLevelText: true, ForceColors: true, FullTimestamp: false})
+			//	声明式解析器
+			if dir := c.String("parsers"); len(dir) > 0 {
+				if err := crawler.LoadParserDefinitions(dir); err != nil {
+					return err
+				}
+			}
 			return nil
 		},
 		After: func(c *cli.Context) error {
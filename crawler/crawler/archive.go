@@ -0,0 +1,180 @@
+package crawler
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+//	页面存档
+//
+//	存档为一个目录，结构如下：
+//		<archive>/manifest.json				存档版本及创建时间
+//		<archive>/pages/<sha1(url)>.json	每个页面一个文件，包含 URL、抓取时间、响应头及内容
+//
+//	与 colly 的 CacheDir 不同，存档是可读、有版本的，并且可以在完全离线的情况下重放。
+
+const ARCHIVE_VERSION = 1
+
+var ErrArchivePageNotFound = errors.New("存档中不存在该页面")
+
+type ArchiveManifest struct {
+	Version int
+	Created time.Time
+}
+
+type ArchivePage struct {
+	URL        string
+	FetchedAt  time.Time
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+}
+
+type Archive struct {
+	dir      string
+	manifest ArchiveManifest
+	lock     sync.Mutex
+}
+
+// 建立存档，如果存档已存在则打开并继续追加
+func NewArchive(dir string) (*Archive, error) {
+	a, err := OpenArchive(dir)
+	if err == nil {
+		return a, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(path.Join(dir, "pages"), 0755); err != nil {
+		return nil, err
+	}
+	a = &Archive{dir: dir, manifest: ArchiveManifest{Version: ARCHIVE_VERSION, Created: time.Now()}}
+	f, err := os.Create(path.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	e := json.NewEncoder(f)
+	e.SetIndent("", "  ")
+	if err := e.Encode(a.manifest); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// 打开已存在的存档，并检查版本
+func OpenArchive(dir string) (*Archive, error) {
+	f, err := os.Open(path.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	a := &Archive{dir: dir}
+	if err := json.NewDecoder(f).Decode(&a.manifest); err != nil {
+		return nil, fmt.Errorf("无法读取存档清单 %q: %s", dir, err)
+	}
+	if a.manifest.Version != ARCHIVE_VERSION {
+		return nil, fmt.Errorf("不支持的存档版本 %q: %d (当前版本：%d)", dir, a.manifest.Version, ARCHIVE_VERSION)
+	}
+	return a, nil
+}
+
+func (a *Archive) Dir() string {
+	return a.dir
+}
+
+func (a *Archive) Version() int {
+	return a.manifest.Version
+}
+
+func (a *Archive) filename(link string) string {
+	sum := sha1.Sum([]byte(link))
+	return path.Join(a.dir, "pages", hex.EncodeToString(sum[:])+".json")
+}
+
+func (a *Archive) Put(p ArchivePage) error {
+	buf := new(bytes.Buffer)
+	e := json.NewEncoder(buf)
+	e.SetIndent("", "  ")
+	if err := e.Encode(p); err != nil {
+		return err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	//	先写入临时文件再改名，避免中断时留下不完整的页面
+	filename := a.filename(p.URL)
+	if err := os.WriteFile(filename+"~", buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(filename+"~", filename)
+}
+
+func (a *Archive) Get(link string) (*ArchivePage, error) {
+	f, err := os.Open(a.filename(link))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrArchivePageNotFound, link)
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var p ArchivePage
+	if err := json.NewDecoder(f).Decode(&p); err != nil {
+		return nil, fmt.Errorf("无法读取存档页面 %q: %s", link, err)
+	}
+	return &p, nil
+}
+
+// 用于重放的 http.RoundTripper，只从存档中读取页面，不访问网络
+type ArchiveTransport struct {
+	archive *Archive
+}
+
+func NewArchiveTransport(a *Archive) *ArchiveTransport {
+	return &ArchiveTransport{archive: a}
+}
+
+func (t *ArchiveTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p, err := t.archive.Get(req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	header := p.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	//	记录时页面内容已经由 colly 转换为 UTF-8，因此重放时需修正字符集
+	header.Set("Content-Type", "text/html; charset=utf-8")
+
+	status := p.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(p.Body)),
+		ContentLength: int64(len(p.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,115 @@
+package crawler
+
+import (
+	"crawler/model"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//	疫情防控新闻发布会
+//
+//	发布会实录中有一些每日通报里没有的数据，如：
+//		新增死亡12例，平均年龄80.3岁，最小年龄60岁，最大年龄99岁，均合并有冠心病、高血压等严重基础疾病。
+//		全市已建成方舱医院100余个，床位16万张。
+//		昨天完成核酸检测2500万人次，抗原检测1000万份。
+
+// 可以解析新闻发布会实录的解析器
+type BriefingParser interface {
+	GetBriefingIndexLinks() []string
+	IsBriefing(title string) bool
+	ParseBriefing(b *model.Briefing, title, content string) error
+}
+
+var (
+	reBriefingDate                  = regexp.MustCompile(`(?P<date>(?:\d+年)?\d+月\d+日)`)
+	reBriefingDeaths                = regexp.MustCompile(`新增(?:本土)?死亡(?:病例)?(?P<number>\d+)例`)
+	reBriefingDeathAgeAverage       = regexp.MustCompile(`平均年龄(?P<number>\d+(?:\.\d+)?)岁`)
+	reBriefingDeathAgeMin           = regexp.MustCompile(`最小(?:年龄)?(?:的)?(?:为)?(?P<number>\d+)岁`)
+	reBriefingDeathAgeMax           = regexp.MustCompile(`最大(?:年龄)?(?:的)?(?:为)?(?P<number>\d+)岁`)
+	reBriefingDeathAgeRange         = regexp.MustCompile(`平均年龄[\d.]+岁[（(](?P<min>\d+)岁?[-—~～至](?P<max>\d+)岁[）)]`)
+	reBriefingDeathsWithComorbidity = regexp.MustCompile(`(?P<number>\d+)例(?:合并|患有|伴有)[^。，；]*基础疾病`)
+	reBriefingDeathsAllComorbidity  = regexp.MustCompile(`死亡[^。]*?(?:均|全部)(?:合并|患有|伴有)`)
+	reBriefingComorbidities         = regexp.MustCompile(`(?:合并|患有|伴有)有?(?P<list>[^。；：]+?)等(?:严重)?基础疾病`)
+	reBriefingShelterHospitals      = regexp.MustCompile(`方舱医院(?:共)?(?P<number>\d+)(?:余)?(?:个|家|所|座)`)
+	reBriefingShelterBeds           = regexp.MustCompile(`方舱[^。]*?床位(?:数)?(?:达到?|共)?(?P<number>\d+(?:\.\d+)?[万]?)(?:余)?张`)
+	reBriefingNucleicAcidTests      = regexp.MustCompile(`核酸(?:检测|筛查)(?:采样)?(?P<number>\d+(?:\.\d+)?[万亿]?)(?:余)?(?:人次|管|份)`)
+	reBriefingAntigenTests          = regexp.MustCompile(`抗原(?:检测)?(?P<number>\d+(?:\.\d+)?[万亿]?)(?:余)?(?:人次|份)`)
+)
+
+// 解析新闻发布会实录，日期取自标题，标题中没有时取自内容中第一次出现的日期
+func parseBriefing(b *model.Briefing, city, title, content string) error {
+	if b == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+	b.City = city
+	b.Title = title
+
+	m := reBriefingDate.FindStringSubmatch(title)
+	if m == nil {
+		m = reBriefingDate.FindStringSubmatch(content)
+	}
+	if m == nil {
+		return fmt.Errorf("无法解析发布会日期：%q", title)
+	}
+	s := m[1]
+	if !strings.Contains(s, "年") {
+		s = fmt.Sprintf("2022年%s", s)
+	}
+	date, err := time.Parse("2006年1月2日", s)
+	if err != nil {
+		return fmt.Errorf("无法解析发布会日期：%q", m[1])
+	}
+	b.Date = date
+
+	count := func(re *regexp.Regexp, name string) int {
+		m := re.FindStringSubmatch(content)
+		if m == nil {
+			return 0
+		}
+		n, err := parseAmount(m[re.SubexpIndex("number")])
+		if err != nil {
+			log.Warnf("[%s] 无法解析发布会中的%s：%q", date.Format("2006-01-02"), name, m[0])
+		}
+		return n
+	}
+
+	//	死亡病例
+	b.Deaths = count(reBriefingDeaths, "新增死亡")
+	if m := reBriefingDeathAgeAverage.FindStringSubmatch(content); m != nil {
+		b.DeathAgeAverage, _ = strconv.ParseFloat(m[1], 64)
+	}
+	if m := reBriefingDeathAgeRange.FindStringSubmatch(content); m != nil {
+		b.DeathAgeMin, _ = strconv.Atoi(m[1])
+		b.DeathAgeMax, _ = strconv.Atoi(m[2])
+	} else {
+		b.DeathAgeMin = count(reBriefingDeathAgeMin, "死亡病例最小年龄")
+		b.DeathAgeMax = count(reBriefingDeathAgeMax, "死亡病例最大年龄")
+	}
+	b.DeathsWithComorbidity = count(reBriefingDeathsWithComorbidity, "合并基础疾病的死亡病例")
+	if b.DeathsWithComorbidity == 0 && reBriefingDeathsAllComorbidity.MatchString(content) {
+		//	“均合并有基础疾病”
+		b.DeathsWithComorbidity = b.Deaths
+	}
+	if m := reBriefingComorbidities.FindStringSubmatch(content); m != nil {
+		for _, c := range strings.FieldsFunc(m[1], func(r rune) bool { return r == '、' || r == '，' || r == '和' || r == '及' }) {
+			if c = strings.TrimSpace(c); len(c) > 0 {
+				b.Comorbidities = append(b.Comorbidities, c)
+			}
+		}
+	}
+
+	//	方舱医院
+	b.ShelterHospitals = count(reBriefingShelterHospitals, "方舱医院数量")
+	b.ShelterBeds = count(reBriefingShelterBeds, "方舱医院床位")
+
+	//	检测量
+	b.NucleicAcidTests = count(reBriefingNucleicAcidTests, "核酸检测量")
+	b.AntigenTests = count(reBriefingAntigenTests, "抗原检测量")
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package crawler
+
+import (
+	"crawler/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBriefing(t *testing.T) {
+	title := "上海市新冠肺炎疫情防控新闻发布会（第170场）"
+	content := NormalizeNumbers("4月24日（周日）上午10时，市政府新闻办举行市疫情防控工作新闻发布会。\n" +
+		"4月23日0—24时，新增死亡12例，平均年龄80.3岁（60—99岁），其中11例合并有冠心病、高血压和糖尿病等严重基础疾病。\n" +
+		"全市已建成方舱医院100余个，方舱医院床位16.2万张。\n" +
+		"昨天全市完成核酸筛查２５００万人次，抗原检测1,000万份。")
+
+	var b model.Briefing
+	assert.NoError(t, DailyParserShanghai{}.ParseBriefing(&b, title, content))
+	assert.Equal(t, s2date("2022-04-24"), b.Date)
+	assert.Equal(t, "上海市", b.City)
+	assert.Equal(t, 12, b.Deaths)
+	assert.Equal(t, 80.3, b.DeathAgeAverage)
+	assert.Equal(t, 60, b.DeathAgeMin)
+	assert.Equal(t, 99, b.DeathAgeMax)
+	assert.Equal(t, 11, b.DeathsWithComorbidity)
+	assert.Equal(t, []string{"冠心病", "高血压", "糖尿病"}, b.Comorbidities)
+	assert.Equal(t, 100, b.ShelterHospitals)
+	assert.Equal(t, 162000, b.ShelterBeds)
+	assert.Equal(t, 25000000, b.NucleicAcidTests)
+	assert.Equal(t, 10000000, b.AntigenTests)
+}
+
+func TestParseBriefing_AllComorbidity(t *testing.T) {
+	content := "5月1日0—24时，新增死亡38例，最小年龄48岁，最大年龄101岁，死亡病例均合并有严重基础疾病。"
+	var b model.Briefing
+	assert.NoError(t, DailyParserShanghai{}.ParseBriefing(&b, "上海市疫情防控新闻发布会", content))
+	assert.Equal(t, s2date("2022-05-01"), b.Date)
+	assert.Equal(t, 38, b.Deaths)
+	assert.Equal(t, 48, b.DeathAgeMin)
+	assert.Equal(t, 101, b.DeathAgeMax)
+	assert.Equal(t, 38, b.DeathsWithComorbidity)
+	assert.Empty(t, b.Comorbidities)
+	assert.Equal(t, 0, b.ShelterBeds)
+
+	assert.Error(t, DailyParserShanghai{}.ParseBriefing(&b, "新闻发布会", "没有日期"))
+}
@@ -0,0 +1,56 @@
+package crawler
+
+import (
+	"crawler/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResidents_Clusters(t *testing.T) {
+	rs := model.Residents{
+		{Date: s2date("2022-04-25"), Name: "确诊病例1", City: "北京市", District: "朝阳区", Address: "双井街道广和南里二条", Cluster: "朝阳区双井街道聚集性疫情"},
+		{Date: s2date("2022-04-26"), Name: "确诊病例3", City: "北京市", District: "东城区", Address: "东花市北里", Cluster: "朝阳区双井街道聚集性疫情"},
+		{Date: s2date("2022-04-26"), Name: "确诊病例4", City: "北京市", District: "朝阳区", Address: "劲松街道", Cluster: "朝阳区双井街道聚集性疫情"},
+		{Date: s2date("2022-04-27"), Name: "确诊病例7", City: "北京市", District: "朝阳区", Venue: "护国寺小吃（光明桥店）"},
+		//	同一居住地，前两例相隔不超过时间窗口，第三例相隔太久
+		{Date: s2date("2022-04-20"), Name: "确诊病例2", City: "北京市", District: "房山区", Address: "窦店镇于庄村"},
+		{Date: s2date("2022-04-24"), Name: "确诊病例5", City: "北京市", District: "房山区", Address: "窦店镇于庄村"},
+		{Date: s2date("2022-05-10"), Name: "确诊病例9", City: "北京市", District: "房山区", Address: "窦店镇于庄村"},
+		//	只有区，没有居住地
+		{Date: s2date("2022-04-24"), Name: "确诊病例6", City: "北京市", District: "朝阳区"},
+		{Date: s2date("2022-04-24"), Name: "确诊病例8", City: "北京市", District: "朝阳区"},
+	}
+	cs := rs.Clusters()
+	if !assert.Len(t, cs, 3) {
+		return
+	}
+
+	assert.Equal(t, "护国寺小吃（光明桥店）", cs[0].Name)
+	assert.Equal(t, 1, cs[0].Size)
+
+	c := cs[1]
+	assert.Equal(t, "朝阳区双井街道聚集性疫情", c.Name)
+	assert.True(t, c.Named)
+	assert.Equal(t, s2date("2022-04-25"), c.First)
+	assert.Equal(t, s2date("2022-04-26"), c.Last)
+	assert.Equal(t, 3, c.Size)
+	assert.Equal(t, []string{"东城区", "朝阳区"}, c.Districts)
+	assert.Equal(t, []model.ClusterDay{
+		{Date: s2date("2022-04-25"), Count: 1, Total: 1},
+		{Date: s2date("2022-04-26"), Count: 2, Total: 3},
+	}, c.Timeline)
+	assert.Equal(t, 1, c.SizeOn(s2date("2022-04-25")))
+
+	c = cs[2]
+	assert.Equal(t, "房山区窦店镇于庄村", c.Name)
+	assert.False(t, c.Named)
+	assert.Equal(t, 2, c.Size)
+	assert.Equal(t, []string{"2022-04-20.确诊病例2", "2022-04-24.确诊病例5"}, c.Cases)
+
+	//	活跃的聚集性疫情
+	assert.Len(t, cs.Active(s2date("2022-04-22")), 1)
+	assert.Len(t, cs.Active(s2date("2022-05-08")), 3)
+	assert.Len(t, cs.Active(s2date("2022-05-10")), 2)
+	assert.Empty(t, cs.Active(s2date("2022-06-01")))
+}
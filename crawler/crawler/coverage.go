@@ -0,0 +1,148 @@
+package crawler
+
+import (
+	"crawler/model"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//	解析覆盖率
+//
+//	卫健委修改通报模板的措辞后，原有的正则往往会静默地匹配失败。
+//	覆盖率统计列出通报中没有被任何提取器匹配到、又含有数字和“例”的句子，以便及时发现这种变化。
+
+// 可以统计解析覆盖率的解析器
+//
+//	GetExtractors 返回解析内容时用于提取数量的正则。日期、分区列表项这类只在局部使用、
+//	几乎能匹配任何句子的正则不应包括在内，否则所有句子都会被视为已识别
+type CoverageParser interface {
+	GetExtractors() []*regexp.Regexp
+}
+
+// 解析前需要先整理内容格式的解析器
+type ContentNormalizer interface {
+	NormalizeContent(content string) string
+}
+
+var reCoverageNumeric = regexp.MustCompile(`\d+\s*例`)
+
+// 内容中没有被任何提取器匹配到的数字句子（含有数字和“例”的行）
+//
+//	解析器没有实现 CoverageParser 时无法判断，返回 nil
+func UnmatchedLines(p DailyParser, content string) []string {
+	cp, ok := p.(CoverageParser)
+	if !ok {
+		return nil
+	}
+	if n, ok := p.(ContentNormalizer); ok {
+		content = n.NormalizeContent(content)
+	}
+
+	//	所有提取器在内容中匹配到的区间
+	var spans [][]int
+	for _, re := range cp.GetExtractors() {
+		spans = append(spans, re.FindAllStringIndex(content, -1)...)
+	}
+
+	var lines []string
+	start := 0
+	for _, line := range strings.Split(content, "\n") {
+		end := start + len(line)
+		if reCoverageNumeric.MatchString(line) && !overlaps(spans, start, end) {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+		start = end + 1
+	}
+	return lines
+}
+
+func overlaps(spans [][]int, start, end int) bool {
+	for _, s := range spans {
+		if s[0] < end && s[1] > start {
+			return true
+		}
+	}
+	return false
+}
+
+// 一篇通报的解析覆盖率
+type CoverageItem struct {
+	Date      time.Time
+	Title     string
+	Source    string
+	Lines     int      // 内容总行数
+	Unmatched []string // 未识别的数字句子
+}
+
+// 一次抓取中全部通报的解析覆盖率
+type Coverage struct {
+	lock  sync.Mutex
+	Items []CoverageItem
+}
+
+func (cv *Coverage) Add(it CoverageItem) {
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+	cv.Items = append(cv.Items, it)
+}
+
+// 通报总数，以及存在未识别数字句子的通报数
+func (cv *Coverage) Summary() (total, unmatched int) {
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+	for _, it := range cv.Items {
+		if len(it.Unmatched) > 0 {
+			unmatched++
+		}
+	}
+	return len(cv.Items), unmatched
+}
+
+// 按日期排序，只保留存在未识别数字句子的通报
+func (cv *Coverage) Unmatched() []CoverageItem {
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+	var items []CoverageItem
+	for _, it := range cv.Items {
+		if len(it.Unmatched) > 0 {
+			items = append(items, it)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].Date.Equal(items[j].Date) {
+			return items[i].Date.Before(items[j].Date)
+		}
+		return items[i].Source < items[j].Source
+	})
+	return items
+}
+
+func (cv *Coverage) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"日期", "标题", "来源", "未识别句子"},
+	}
+	for _, it := range cv.Unmatched() {
+		for _, line := range it.Unmatched {
+			records = append(records, []string{it.Date.Format("2006-01-02"), it.Title, it.Source, line})
+		}
+	}
+	return model.SaveToCSV(filename, records)
+}
+
+func (cv *Coverage) SaveToMarkdown(filename string) error {
+	var b strings.Builder
+	total, unmatched := cv.Summary()
+	fmt.Fprintf(&b, "# 解析覆盖率报告\n\n共 %d 篇通报，其中 %d 篇存在未识别的数字句子。\n", total, unmatched)
+	for _, it := range cv.Unmatched() {
+		fmt.Fprintf(&b, "\n## %s %s\n\n- 来源：%s\n- 未识别：%d / %d 行\n\n", it.Date.Format("2006-01-02"), it.Title, it.Source, len(it.Unmatched), it.Lines)
+		for _, line := range it.Unmatched {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+	return os.WriteFile(filename, []byte(b.String()), 0644)
+}
@@ -0,0 +1,42 @@
+package crawler
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmatchedLines(t *testing.T) {
+	content := "4月16日0时至24时，新增本土确诊病例3例，无新增境外输入确诊病例。\n" +
+		"另有12例核酸检测初筛阳性人员正在复核中。\n" +
+		"请市民朋友继续做好个人防护。"
+
+	for _, city := range []string{"shanghai", "beijing", "guangzhou"} {
+		c, err := GetCity(city)
+		assert.NoError(t, err)
+		assert.Equalf(t, []string{"另有12例核酸检测初筛阳性人员正在复核中。"}, UnmatchedLines(c.Parser, content), "城市：%s", city)
+	}
+
+	//	上海分区列表被拆成多行时，应先整理格式再判断
+	c, _ := GetCity("shanghai")
+	assert.Empty(t, UnmatchedLines(c.Parser, "病例1—病例510，居住于\n浦东新区，\n病例511—病例683，居住于黄浦区，\n均为本市闭环隔离管控人员，其间新冠病毒核酸检测结果异常，诊断为确诊病例。"))
+}
+
+func TestCoverage_Golden(t *testing.T) {
+	//	回归测试语料中的通报应当全部被识别
+	for city, pages := range goldenPages(t) {
+		for _, page := range pages {
+			body, err := os.ReadFile(page)
+			assert.NoError(t, err)
+			dc := newGoldenCrawler(t, city, goldenLink(page), body)
+			cv := dc.EnableCoverage()
+			dc.cItem.Visit(goldenLink(page))
+			dc.cItem.Wait()
+
+			total, unmatched := cv.Summary()
+			assert.Equalf(t, 1, total, "%s", page)
+			assert.Zerof(t, unmatched, "%s => %v", page, cv.Unmatched())
+		}
+	}
+}
@@ -2,6 +2,7 @@ package crawler
 
 import (
 	"crawler/model"
+	"fmt"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -20,15 +21,37 @@ const (
 	CRAWLER_PARALLELISM     = 5
 )
 
+// 抓取模式
+type CrawlerMode int
+
+const (
+	CRAWLER_MODE_DAILY      CrawlerMode = iota // 每日通报和居住地信息
+	CRAWLER_MODE_RISK_AREAS                    // 风险地区调整通告，见 RiskAreaParser
+	CRAWLER_MODE_ZONES                         // 封控区、管控区、防范区名单，见 ZoneParser
+	CRAWLER_MODE_BRIEFINGS                     // 新闻发布会实录，见 BriefingParser
+)
+
 type DailyCrawler struct {
 	PageVisited int32
 	PageTotal   int32
 
 	parser             DailyParser
+	location           *time.Location // 通报所在城市的时区
+	mode               CrawlerMode
+	itemLinks          []string // 解析器之外另行指定的内容页面
+	replay             bool
+	since              time.Time // 只抓取该日期及以后的通报
+	until              time.Time // 只抓取该日期及以前的通报
+	reachedSince       int32     // 索引页中已出现早于 since 的通报
+	incremental        bool      // 增量模式：since 来自已有数据
 	cItem              *colly.Collector
 	cIndex             *colly.Collector
 	listenersDaily     []func(model.Daily)
 	listenersResidents []func(model.Residents)
+	listenersRiskAreas []func(model.RiskAreaNotices)
+	listenersZones     []func(model.Zones)
+	listenersBriefing  []func(model.Briefing)
+	coverage           *Coverage // 解析覆盖率，为 nil 时不统计
 }
 
 type OnDailyListener interface {
@@ -39,16 +62,14 @@ type OnResidentsListener interface {
 	OnResidents(model.Residents)
 }
 
-func NewDailyCrawler(city, cache_dir string) *DailyCrawler {
-	dc := DailyCrawler{}
-	switch city {
-	case "shanghai":
-		dc.parser = DailyParserShanghai{}
-	case "beijing":
-		dc.parser = DailyParserBeijing{}
+func NewDailyCrawler(city, cache_dir string) (*DailyCrawler, error) {
+	c, err := GetCity(city)
+	if err != nil {
+		return nil, err
 	}
+	dc := DailyCrawler{parser: c.Parser, location: c.TimeZone}
 	dc.init(cache_dir)
-	return &dc
+	return &dc, nil
 }
 
 func (dc *DailyCrawler) init(cache_dir string) {
@@ -75,6 +96,10 @@ func (dc *DailyCrawler) init(cache_dir string) {
 
 	dc.cItem.OnError(func(resp *colly.Response, err error) {
 		log.Warnf("DailyCrawler.OnError(): [Item] (%s) => '%s'", resp.Request.URL, err)
+		if dc.replay {
+			//	重放模式下页面只来自存档，重试没有意义
+			return
+		}
 		//	重试。在另外线程等待一段时间，以不阻碍当前线程（爬虫）运行。
 		go func(link string) {
 			time.Sleep(CRAWLER_RETRY_TIMEOUT)
@@ -94,6 +119,10 @@ func (dc *DailyCrawler) init(cache_dir string) {
 	})
 	dc.cIndex.OnError(func(resp *colly.Response, err error) {
 		log.Warnf("DailyCrawler.OnError(): [Index] (%s) => '%s'", resp.Request.URL, err)
+		if dc.replay {
+			//	重放模式下页面只来自存档，重试没有意义
+			return
+		}
 		//	重试。在另外线程等待一段时间，以不阻碍当前线程（爬虫）运行。
 		go func(link string) {
 			time.Sleep(CRAWLER_RETRY_TIMEOUT)
@@ -104,31 +133,168 @@ func (dc *DailyCrawler) init(cache_dir string) {
 	dc.cIndex.OnHTML(dc.parser.GetSelector("index"), dc.ParseIndex)
 }
 
+// 将抓取到的每个页面（包括来自缓存的页面）记录到存档
+func (c *DailyCrawler) Record(a *Archive) {
+	record := func(r *colly.Response) {
+		p := ArchivePage{
+			URL:        r.Request.URL.String(),
+			FetchedAt:  time.Now(),
+			StatusCode: r.StatusCode,
+			Body:       r.Body,
+		}
+		if r.Headers != nil {
+			p.Headers = r.Headers.Clone()
+		}
+		if err := a.Put(p); err != nil {
+			log.Errorf("DailyCrawler.Record(): 无法写入存档 %q: %s", p.URL, err)
+		}
+	}
+	c.cItem.OnResponse(record)
+	c.cIndex.OnResponse(record)
+}
+
+// 只从存档中重放页面，不访问网络，也不使用网页缓存
+func (c *DailyCrawler) Replay(a *Archive) {
+	c.replay = true
+	c.cItem.CacheDir = ""
+	c.cIndex.CacheDir = ""
+	//	cIndex 由 cItem 克隆而来，两者共享同一个 HTTP 后端
+	c.cItem.WithTransport(NewArchiveTransport(a))
+}
+
+// 统计每篇通报的解析覆盖率，抓取结束后从返回的 Coverage 中读取结果
+func (c *DailyCrawler) EnableCoverage() *Coverage {
+	if c.coverage == nil {
+		c.coverage = &Coverage{}
+	}
+	return c.coverage
+}
+
+// 抓取模式，默认抓取每日通报；其它模式要求解析器实现对应的接口，如风险地区模式要求实现 RiskAreaParser
+func (c *DailyCrawler) SetMode(mode CrawlerMode) error {
+	switch mode {
+	case CRAWLER_MODE_RISK_AREAS:
+		if _, ok := c.parser.(RiskAreaParser); !ok {
+			return fmt.Errorf("该城市的解析器不支持风险地区通告")
+		}
+	case CRAWLER_MODE_ZONES:
+		if _, ok := c.parser.(ZoneParser); !ok {
+			return fmt.Errorf("该城市的解析器不支持封控区、管控区、防范区名单")
+		}
+	case CRAWLER_MODE_BRIEFINGS:
+		if _, ok := c.parser.(BriefingParser); !ok {
+			return fmt.Errorf("该城市的解析器不支持新闻发布会实录")
+		}
+	}
+	c.mode = mode
+	return nil
+}
+
+// 另行指定需要抓取的内容页面，如索引中没有的公众号文章
+func (c *DailyCrawler) AddItemLinks(links ...string) {
+	c.itemLinks = append(c.itemLinks, links...)
+}
+
+// 日期范围：只抓取 [since, until] 内的通报，零值表示不限制
+func (c *DailyCrawler) SetDateRange(since, until time.Time) {
+	c.since = c.inTimeZone(since)
+	c.until = c.inTimeZone(until)
+}
+
+// 增量模式：只抓取 latest 当天及以后的通报，索引翻页遇到更早的通报即停止
+func (c *DailyCrawler) SetIncremental(latest time.Time) {
+	latest = c.inTimeZone(latest)
+	if latest.After(c.since) {
+		c.since = latest
+		c.incremental = true
+	}
+}
+
+// 日期是否在抓取范围内，无法确定日期的通报视为在范围内
+func (c *DailyCrawler) InDateRange(date time.Time) bool {
+	if date.IsZero() {
+		return true
+	}
+	date = c.inTimeZone(date)
+	if !c.since.IsZero() && date.Before(c.since) {
+		return false
+	}
+	if !c.until.IsZero() && date.After(c.until) {
+		return false
+	}
+	return true
+}
+
 func (c *DailyCrawler) Collect() {
 	//	先抓取指定内容页面
-	for _, l := range c.parser.GetItemLinks() {
+	if c.incremental && c.until.IsZero() {
+		//	指定内容页面都是历史通报，增量模式下跳过
+		log.Debugf("DailyCrawler.Collect(): 增量模式，跳过 %d 个指定内容页面", len(c.parser.GetItemLinks()))
+	} else {
+		for _, l := range c.parser.GetItemLinks() {
+			c.cItem.Visit(l)
+		}
+	}
+	for _, l := range c.itemLinks {
 		c.cItem.Visit(l)
 	}
 	//	再抓取索引页面
-	for _, l := range c.parser.GetIndexLinks() {
-		c.cIndex.Visit(l)
+	if c.since.IsZero() {
+		for _, l := range c.indexLinks() {
+			c.cIndex.Visit(l)
+		}
+	} else {
+		//	索引页面由新到旧，逐页抓取，直到遇到早于 since 的通报
+		for i, l := range c.indexLinks() {
+			c.cIndex.Visit(l)
+			c.cIndex.Wait()
+			if atomic.LoadInt32(&c.reachedSince) > 0 {
+				log.Infof("DailyCrawler.Collect(): 第 %d 页索引已出现 %s 之前的通报，停止翻页", i+1, c.since.Format("2006-01-02"))
+				break
+			}
+		}
 	}
 	//	等待结束
 	c.cIndex.Wait()
 	c.cItem.Wait()
 }
 
+// 当前抓取模式的索引页面
+func (c *DailyCrawler) indexLinks() []string {
+	if c.mode == CRAWLER_MODE_BRIEFINGS {
+		return c.parser.(BriefingParser).GetBriefingIndexLinks()
+	}
+	return c.parser.GetIndexLinks()
+}
+
 func (c *DailyCrawler) ParseItem(e *colly.HTMLElement) {
 	var d model.Daily
 	d.Source = e.Request.URL.String()
+	d.EnableProvenance()
 
 	// 标题
-	title := strings.TrimSpace(e.ChildText(c.parser.GetSelector("title")))
+	normalized_title := normalizeNumbers(strings.TrimSpace(e.ChildText(c.parser.GetSelector("title"))))
+	title := normalized_title.text
 	// log.Tracef("DailyCrawler.parseItem(%s): %s => %q\n", e.Attr("id"), e.Request.URL, title)
 
+	switch c.mode {
+	case CRAWLER_MODE_RISK_AREAS:
+		c.parseRiskAreasItem(e, title)
+		return
+	case CRAWLER_MODE_ZONES:
+		c.parseZonesItem(e, title)
+		return
+	case CRAWLER_MODE_BRIEFINGS:
+		c.parseBriefingItem(e, title)
+		return
+	}
+
 	if err := c.parser.ParseDailyTitle(&d, title); err != nil {
 		log.Errorf("解析文章标题失败：%s => %q", err, title)
 	}
+	if !c.InDateRange(d.Date) {
+		return
+	}
 
 	// log.Tracef("DailyCrawler.parseItem(): [%s] 本土 (新增:%d, 无症状: %d), 境外输入 (新增:%d, 无症状: %d), 出院: %d, 解除医学观察: %d",
 	// 	d.Date.Format("2006-01-02"),
@@ -140,16 +306,9 @@ func (c *DailyCrawler) ParseItem(e *colly.HTMLElement) {
 	// 	d.DischargedFromMedicalObservation,
 	// )
 
-	// content := strings.TrimSpace(e.ChildText("#ivs_content"))
-	//	上面的代码会去掉所有换行，导致匹配失败，因此用下面的方式行于行之间用 '\n' 链接
-	content_lines := []string{}
-	e.ForEach(c.parser.GetSelector("content"), func(i int, h *colly.HTMLElement) {
-		t := strings.TrimSpace(h.Text)
-		if len(t) > 0 {
-			content_lines = append(content_lines, t)
-		}
-	})
-	content := strings.Join(content_lines, "\n")
+	content_lines := c.contentLines(e)
+	normalized_content := normalizeNumbers(strings.Join(content_lines, "\n"))
+	content := normalized_content.text
 
 	// log.Tracef("[%s] <%s>: %s", d.Date.Format("2006-01-02"), title, d.Source)
 
@@ -168,17 +327,27 @@ func (c *DailyCrawler) ParseItem(e *colly.HTMLElement) {
 		// 	d.ImportedDischargedFromMedicalObservation,
 		// )
 
+		//	标题中没有日期时，日期来自内容，需再次检查
+		if !c.InDateRange(d.Date) {
+			return
+		}
+
 		c.FixDaily(&d)
 
 		//	通知 OnDailyListeners
 		//	使用 defer 将提交推迟到整个函数运行结束，这样给 c.FixDailyByResidents() 一个机会来修复缺失数据
-		defer func() { c.OnDaily(d) }()
+		defer func() {
+			//	解析器匹配的是规范化后的文本，来源中记录通报原文
+			restoreSnippets(&d, normalized_title, normalized_content)
+			c.OnDaily(d)
+		}()
 	}
 	if c.parser.IsResidents(d.Date, title) {
 		// log.Tracef("[%s] <%s>: 居住地信息统计", d.Date.Format("2006-01-02"), title)
 		//	居住地信息统计
+		//	居住地信息中的地名、场所不做数字规范化，以免“第一人民医院”被改写后进入地址
 		rs := make(model.Residents, 0)
-		if err := c.parser.ParseResidents(&rs, d.Date, content); err != nil {
+		if err := c.parser.ParseResidents(&rs, d.Date, normalized_content.source); err != nil {
 			log.Errorf("[%s] 解析感染者居住地信息失败：%s => %q", d.Date.Format("2006-01-02"), err, title)
 		} else {
 			// log.Infof("[%s] 解析到 %d 个感染病例居住地信息。", d.Date.Format("2006-01-02"), len(rs))
@@ -189,62 +358,148 @@ func (c *DailyCrawler) ParseItem(e *colly.HTMLElement) {
 		}
 		// log.Tracef("[%s] <%s>: 居住地信息统计: rs => [%d]", d.Date.Format("2006-01-02"), title, len(rs))
 	}
+
+	//	解析覆盖率
+	if c.coverage != nil && (c.parser.IsDaily(d.Date, title) || c.parser.IsResidents(d.Date, title)) {
+		c.coverage.Add(CoverageItem{
+			Date:      d.Date,
+			Title:     title,
+			Source:    d.Source,
+			Lines:     len(content_lines),
+			Unmatched: UnmatchedLines(c.parser, content),
+		})
+	}
+}
+
+// 页面内容的各行
+func (c *DailyCrawler) contentLines(e *colly.HTMLElement) []string {
+	// content := strings.TrimSpace(e.ChildText("#ivs_content"))
+	//	上面的代码会去掉所有换行，导致匹配失败，因此用下面的方式行于行之间用 '\n' 链接
+	content_lines := []string{}
+	e.ForEach(c.parser.GetSelector("content"), func(i int, h *colly.HTMLElement) {
+		t := strings.TrimSpace(h.Text)
+		if len(t) > 0 {
+			content_lines = append(content_lines, t)
+		}
+	})
+	return content_lines
+}
+
+// 解析风险地区调整通告
+func (c *DailyCrawler) parseRiskAreasItem(e *colly.HTMLElement, title string) {
+	rp := c.parser.(RiskAreaParser)
+	if !rp.IsRiskAreas(title) {
+		return
+	}
+	//	标题中不一定有日期，没有时以内容中的生效日期为准
+	var d model.Daily
+	c.parser.ParseDailyTitle(&d, title)
+
+	content := NormalizeNumbers(strings.Join(c.contentLines(e), "\n"))
+	ns := make(model.RiskAreaNotices, 0)
+	if err := rp.ParseRiskAreas(&ns, d.Date, content); err != nil {
+		log.Errorf("解析风险地区通告失败：%s => %q", err, title)
+		return
+	}
+	source := e.Request.URL.String()
+	in_range := ns[:0]
+	for _, n := range ns {
+		if c.InDateRange(n.Date) {
+			n.Source = source
+			in_range = append(in_range, n)
+		}
+	}
+	if len(in_range) > 0 {
+		c.OnRiskAreas(in_range)
+	}
+}
+
+// 解析封控区、管控区、防范区名单
+func (c *DailyCrawler) parseZonesItem(e *colly.HTMLElement, title string) {
+	zp := c.parser.(ZoneParser)
+	if !zp.IsZones(title) {
+		return
+	}
+	content := NormalizeNumbers(strings.Join(c.contentLines(e), "\n"))
+	zs := make(model.Zones, 0)
+	if err := zp.ParseZones(&zs, title, content); err != nil {
+		log.Errorf("解析封控区名单失败：%s => %q", err, title)
+		return
+	}
+	if !c.InDateRange(zs[0].Date) {
+		return
+	}
+	source := e.Request.URL.String()
+	for i := range zs {
+		zs[i].Source = source
+	}
+	c.OnZones(zs)
+}
+
+// 解析新闻发布会实录
+func (c *DailyCrawler) parseBriefingItem(e *colly.HTMLElement, title string) {
+	bp := c.parser.(BriefingParser)
+	if !bp.IsBriefing(title) {
+		return
+	}
+	content := NormalizeNumbers(strings.Join(c.contentLines(e), "\n"))
+	b := model.Briefing{Source: e.Request.URL.String()}
+	if err := bp.ParseBriefing(&b, title, content); err != nil {
+		log.Errorf("解析新闻发布会实录失败：%s => %q", err, title)
+		return
+	}
+	if !c.InDateRange(b.Date) {
+		return
+	}
+	c.OnBriefing(b)
+}
+
+// 索引中的标题是否需要抓取
+func (c *DailyCrawler) isWantedTitle(title string) bool {
+	switch c.mode {
+	case CRAWLER_MODE_RISK_AREAS:
+		return c.parser.(RiskAreaParser).IsRiskAreas(title)
+	case CRAWLER_MODE_ZONES:
+		return c.parser.(ZoneParser).IsZones(title)
+	case CRAWLER_MODE_BRIEFINGS:
+		return c.parser.(BriefingParser).IsBriefing(title)
+	}
+	return c.parser.IsValidTitle(title)
 }
 
 func (c *DailyCrawler) ParseIndex(e *colly.HTMLElement) {
 	link := e.Request.AbsoluteURL(strings.TrimSpace(e.Attr("href")))
-	title := e.Text
+	title := NormalizeNumbers(e.Text)
 	// log.Tracef("DailyCrawler.ParseIndex(): %s => %s", title, link)
-	if c.parser.IsValidTitle(title) {
+	if c.isWantedTitle(title) {
+		if !c.since.IsZero() || !c.until.IsZero() {
+			var d model.Daily
+			if err := c.parser.ParseDailyTitle(&d, title); err == nil && !c.InDateRange(d.Date) {
+				if c.inTimeZone(d.Date).Before(c.since) {
+					atomic.StoreInt32(&c.reachedSince, 1)
+				}
+				//	不在抓取范围内，不必抓取
+				return
+			}
+		}
 		//	告知 cItem 抓取该链接
 		c.cItem.Visit(link)
 	}
 }
 
+// 补全可以由其它字段计算得到的数据，然后用 model.DailyRules 校验一致性
 func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 	//	无症状
 	if d.Asymptomatic == 0 {
 		if d.LocalAsymptomatic != 0 || d.ImportedAsymptomatic != 0 {
 			d.Asymptomatic = d.LocalAsymptomatic + d.ImportedAsymptomatic
-		}
-	} else {
-		if d.Asymptomatic != (d.LocalAsymptomatic + d.ImportedAsymptomatic) {
-			log.Warnf("[%s] 无症状数据不匹配：总共:%d (本土:%d / 境外输入:%d)", d.Date.Format("2006-01-02"), d.Asymptomatic, d.LocalAsymptomatic, d.ImportedAsymptomatic)
-		}
-	}
-	if d.LocalAsymptomaticFromBubble != 0 {
-		//	检查分区数量
-		c := 0
-		for _, v := range d.DistrictAsymptomaticFromBubble {
-			c += v
-		}
-		if d.LocalAsymptomaticFromBubble != c {
-			log.Warnf("[%s] 无症状(来自隔离管控)数据不匹配：总共:%d => %d: (分区: %v)",
-				d.Date.Format("2006-01-02"),
-				d.LocalAsymptomaticFromBubble,
-				c,
-				d.DistrictAsymptomaticFromBubble)
+			d.Derived("Asymptomatic", "FixDaily", "LocalAsymptomatic + ImportedAsymptomatic")
 		}
 	}
 	if d.LocalAsymptomaticFromRisk == 0 {
 		if d.LocalAsymptomaticFromBubble > 0 {
 			d.LocalAsymptomaticFromRisk = d.LocalAsymptomatic - d.LocalAsymptomaticFromBubble
-			if d.LocalAsymptomaticFromRisk < 0 {
-				log.Warnf("[%s] 无症状(来自风险人群)数据不合理：本土无症状: %d => 来自隔离管控: %d + 来自风险人群: %d", d.Date.Format("2006-01-02"), d.LocalAsymptomatic, d.LocalAsymptomaticFromBubble, d.LocalAsymptomaticFromRisk)
-			}
-		}
-	} else {
-		//	检查分区数量
-		r := 0
-		for _, v := range d.DistrictAsymptomaticFromRisk {
-			r += v
-		}
-		if d.LocalAsymptomaticFromRisk != r {
-			log.Warnf("[%s] 无症状(来自风险人群)数据不匹配：总共:%d => %d: (分区: %v)",
-				d.Date.Format("2006-01-02"),
-				d.LocalAsymptomaticFromRisk,
-				r,
-				d.DistrictAsymptomaticFromRisk)
+			d.Derived("LocalAsymptomaticFromRisk", "FixDaily", "LocalAsymptomatic - LocalAsymptomaticFromBubble")
 		}
 	}
 	if d.DistrictAsymptomatic == nil {
@@ -259,81 +514,29 @@ func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 				d.DistrictAsymptomatic[r] = v
 			}
 		}
+		if len(d.DistrictAsymptomatic) > 0 {
+			d.Derived("DistrictAsymptomatic", "FixDaily", "DistrictAsymptomaticFromBubble + DistrictAsymptomaticFromRisk")
+		}
 	}
 
 	//	确诊
-	mcsc := d.Mild + d.Common + d.Severe + d.Critical
 	if d.LocalConfirmed == 0 {
 		if d.Mild != 0 || d.Common != 0 || d.Severe != 0 || d.Critical != 0 {
-			d.LocalConfirmed = mcsc
-		}
-	} else {
-		//	当临床分型数据存在，但是本地确诊数据却不一致的时候报错
-		if (d.Mild != 0 || d.Common != 0 || d.Severe != 0 || d.Critical != 0) &&
-			d.LocalConfirmed != mcsc {
-			log.Warnf("[%s] 本土确诊数据不匹配：本土确诊:%d => %d: (轻型:%d / 普通型:%d / 重型:%d / 危重型:%d)",
-				d.Date.Format("2006-01-02"),
-				d.LocalConfirmed,
-				mcsc,
-				d.Mild, d.Common, d.Severe, d.Critical)
+			d.LocalConfirmed = d.Mild + d.Common + d.Severe + d.Critical
+			d.Derived("LocalConfirmed", "FixDaily", "Mild + Common + Severe + Critical")
 		}
 	}
 	if d.Confirmed == 0 {
 		if d.LocalConfirmed != 0 || d.ImportedConfirmed != 0 {
 			d.Confirmed = d.LocalConfirmed + d.ImportedConfirmed
-		}
-	} else {
-		if d.Confirmed != (d.LocalConfirmed + d.ImportedConfirmed) {
-			log.Warnf("[%s] 确诊数据不匹配：总共:%d (本土:%d / 境外输入:%d)", d.Date.Format("2006-01-02"), d.Confirmed, d.LocalConfirmed, d.ImportedConfirmed)
-		}
-	}
-	if d.LocalConfirmedFromAsymptomatic != 0 {
-		//	检查分区数量
-		c := 0
-		for _, v := range d.DistrictConfirmedFromAsymptomatic {
-			c += v
-		}
-		if d.LocalConfirmedFromAsymptomatic != c {
-			log.Warnf("[%s] 确证病例(来自无症状)数据不匹配：总共:%d => %d: (分区: %v)",
-				d.Date.Format("2006-01-02"),
-				d.LocalConfirmedFromAsymptomatic,
-				c,
-				d.DistrictConfirmedFromAsymptomatic)
-		}
-	}
-	if d.LocalConfirmedFromBubble != 0 {
-		//	检查分区数量
-		c := 0
-		for _, v := range d.DistrictConfirmedFromBubble {
-			c += v
-		}
-		if d.LocalConfirmedFromBubble != c {
-			log.Warnf("[%s] 确证病例(来自隔离管控)数据不匹配：总共:%d => %d: (分区: %v)",
-				d.Date.Format("2006-01-02"),
-				d.LocalConfirmedFromBubble,
-				c,
-				d.DistrictConfirmedFromBubble)
+			d.Derived("Confirmed", "FixDaily", "LocalConfirmed + ImportedConfirmed")
 		}
 	}
 	if d.LocalConfirmedFromRisk == 0 {
-		if d.LocalConfirmedFromBubble > 0 || d.LocalConfirmedFromAsymptomatic > 0 {
+		//	全国通报不区分隔离管控中和风险人群中发现的病例，无症状感染者转确诊以外的不都来自风险人群
+		if _, ok := c.parser.(DailyParserNational); !ok && (d.LocalConfirmedFromBubble > 0 || d.LocalConfirmedFromAsymptomatic > 0) {
 			d.LocalConfirmedFromRisk = d.LocalConfirmed - (d.LocalConfirmedFromBubble + d.LocalConfirmedFromAsymptomatic)
-			if d.LocalConfirmedFromRisk < 0 {
-				log.Warnf("[%s] 确证病例(来自风险人群)数据不合理：本土确证病例: %d => 来自隔离管控: %d + 来自风险人群: %d", d.Date.Format("2006-01-02"), d.LocalConfirmed, d.LocalConfirmedFromBubble, d.LocalConfirmedFromRisk)
-			}
-		}
-	} else {
-		//	检查分区数量
-		r := 0
-		for _, v := range d.DistrictConfirmedFromRisk {
-			r += v
-		}
-		if d.LocalConfirmedFromRisk != r {
-			log.Warnf("[%s] 确证病例(来自风险人群)数据不匹配：总共:%d => %d: (分区: %v)",
-				d.Date.Format("2006-01-02"),
-				d.LocalConfirmedFromRisk,
-				r,
-				d.DistrictConfirmedFromRisk)
+			d.Derived("LocalConfirmedFromRisk", "FixDaily", "LocalConfirmed - (LocalConfirmedFromBubble + LocalConfirmedFromAsymptomatic)")
 		}
 	}
 	if d.DistrictConfirmed == nil {
@@ -355,70 +558,34 @@ func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 				d.DistrictConfirmed[r] = v
 			}
 		}
+		if len(d.DistrictConfirmed) > 0 {
+			d.Derived("DistrictConfirmed", "FixDaily", "DistrictConfirmedFromBubble + DistrictConfirmedFromRisk + DistrictConfirmedFromAsymptomatic")
+		}
 	}
 
 	//	阳性感染者
 	if d.LocalPositive == 0 {
 		if d.LocalConfirmed != 0 || d.LocalAsymptomatic != 0 {
 			d.LocalPositive = d.LocalConfirmed + d.LocalAsymptomatic
-		}
-	} else {
-		if d.LocalPositive != (d.LocalConfirmed + d.LocalAsymptomatic) {
-			log.Warnf("[%s] 本土阳性感染者数据不匹配：本土阳性:%d (确诊:%d / 无症状:%d)", d.Date.Format("2006-01-02"), d.LocalPositive, d.LocalConfirmed, d.LocalAsymptomatic)
+			d.Derived("LocalPositive", "FixDaily", "LocalConfirmed + LocalAsymptomatic")
 		}
 	}
 	if d.ImportedPositive == 0 {
 		if d.ImportedConfirmed != 0 || d.ImportedAsymptomatic != 0 {
 			d.ImportedPositive = d.ImportedConfirmed + d.ImportedAsymptomatic
-		}
-	} else {
-		if d.ImportedPositive != (d.ImportedConfirmed + d.ImportedAsymptomatic) {
-			log.Warnf("[%s] 境外输入阳性感染者数据不匹配：境外输入阳性:%d (确诊:%d / 无症状:%d)", d.Date.Format("2006-01-02"), d.ImportedPositive, d.ImportedConfirmed, d.ImportedAsymptomatic)
+			d.Derived("ImportedPositive", "FixDaily", "ImportedConfirmed + ImportedAsymptomatic")
 		}
 	}
-
 	if d.Positive == 0 {
 		if d.Confirmed != 0 || d.Asymptomatic != 0 {
 			d.Positive = d.Confirmed + d.Asymptomatic
-		}
-	} else {
-		if d.Positive != (d.Confirmed + d.Asymptomatic) {
-			log.Warnf("[%s] 阳性感染者数据不匹配：总共:%d (确诊:%d / 无症状:%d)", d.Date.Format("2006-01-02"), d.Positive, d.Confirmed, d.Asymptomatic)
-		}
-	}
-	if d.LocalPositiveFromBubble != 0 {
-		//	检查分区数量
-		c := 0
-		for _, v := range d.DistrictPositiveFromBubble {
-			c += v
-		}
-		if d.LocalPositiveFromBubble != c {
-			log.Warnf("[%s] 阳性感染者(来自隔离管控)数据不匹配：总共:%d => (%d): (分区: %v)",
-				d.Date.Format("2006-01-02"),
-				d.LocalPositiveFromBubble,
-				c,
-				d.DistrictPositiveFromBubble)
+			d.Derived("Positive", "FixDaily", "Confirmed + Asymptomatic")
 		}
 	}
 	if d.LocalPositiveFromRisk == 0 {
 		if d.LocalPositiveFromBubble > 0 {
 			d.LocalPositiveFromRisk = d.LocalPositive - d.LocalPositiveFromBubble
-			if d.LocalPositiveFromRisk < 0 {
-				log.Warnf("[%s] 阳性感染者(来自风险人群)数据不合理：本土阳性感染者: %d => 来自隔离管控: %d + 来自风险人群: %d", d.Date.Format("2006-01-02"), d.LocalPositive, d.LocalPositiveFromBubble, d.LocalPositiveFromRisk)
-			}
-		}
-	} else {
-		//	检查分区数量
-		r := 0
-		for _, v := range d.DistrictPositiveFromRisk {
-			r += v
-		}
-		if d.LocalPositiveFromRisk != r {
-			log.Warnf("[%s] 阳性感染者(来自风险人群)数据不匹配：总共:%d => (%d): (分区: %v)",
-				d.Date.Format("2006-01-02"),
-				d.LocalPositiveFromRisk,
-				r,
-				d.DistrictPositiveFromRisk)
+			d.Derived("LocalPositiveFromRisk", "FixDaily", "LocalPositive - LocalPositiveFromBubble")
 		}
 	}
 	if len(d.DistrictPositive) == 0 {
@@ -435,6 +602,7 @@ func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 					d.DistrictPositive[r] = v
 				}
 			}
+			d.Derived("DistrictPositive", "FixDaily", "DistrictPositiveFromBubble + DistrictPositiveFromRisk")
 		} else if len(d.DistrictConfirmed) > 0 || len(d.DistrictAsymptomatic) > 0 {
 			//	如果存在确诊和无症状分区数据，从这里计算阳性数据
 			for r, v := range d.DistrictConfirmed {
@@ -447,6 +615,7 @@ func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 					d.DistrictPositive[r] = v
 				}
 			}
+			d.Derived("DistrictPositive", "FixDaily", "DistrictConfirmed + DistrictAsymptomatic")
 		}
 	}
 
@@ -454,19 +623,17 @@ func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 	if d.DischargedFromHospital == 0 {
 		if d.LocalDischargedFromHospital != 0 || d.ImportedDischargedFromHospital != 0 {
 			d.DischargedFromHospital = d.LocalDischargedFromHospital + d.ImportedDischargedFromHospital
+			d.Derived("DischargedFromHospital", "FixDaily", "LocalDischargedFromHospital + ImportedDischargedFromHospital")
 		}
-	} else {
-		if (d.DischargedFromHospital != (d.LocalDischargedFromHospital + d.ImportedDischargedFromHospital)) && (d.LocalDischargedFromHospital > 0 || d.ImportedDischargedFromHospital > 0) {
-			if d.LocalDischargedFromHospital == 0 && d.ImportedDischargedFromHospital > 0 {
-				//	应该是没能解析出本土治愈出院，可以计算获得
-				d.LocalDischargedFromHospital = d.DischargedFromHospital - d.ImportedDischargedFromHospital
-			} else if d.LocalDischargedFromHospital > 0 && d.ImportedDischargedFromHospital == 0 {
-				//  应该是没能解析出境外输入治愈出院，可以计算获得
-				d.ImportedDischargedFromHospital = d.DischargedFromHospital - d.LocalDischargedFromHospital
-			} else {
-				//	三者均不为0，因此必然是出错了。
-				log.Warnf("[%s] 治愈出院数据不匹配：总共:%d (本土:%d / 境外输入:%d)", d.Date.Format("2006-01-02"), d.DischargedFromHospital, d.LocalDischargedFromHospital, d.ImportedDischargedFromHospital)
-			}
+	} else if d.DischargedFromHospital != (d.LocalDischargedFromHospital + d.ImportedDischargedFromHospital) {
+		if d.LocalDischargedFromHospital == 0 && d.ImportedDischargedFromHospital > 0 {
+			//	应该是没能解析出本土治愈出院，可以计算获得
+			d.LocalDischargedFromHospital = d.DischargedFromHospital - d.ImportedDischargedFromHospital
+			d.Derived("LocalDischargedFromHospital", "FixDaily", "DischargedFromHospital - ImportedDischargedFromHospital")
+		} else if d.LocalDischargedFromHospital > 0 && d.ImportedDischargedFromHospital == 0 {
+			//  应该是没能解析出境外输入治愈出院，可以计算获得
+			d.ImportedDischargedFromHospital = d.DischargedFromHospital - d.LocalDischargedFromHospital
+			d.Derived("ImportedDischargedFromHospital", "FixDaily", "DischargedFromHospital - LocalDischargedFromHospital")
 		}
 	}
 
@@ -474,10 +641,7 @@ func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 	if d.DischargedFromMedicalObservation == 0 {
 		if d.LocalDischargedFromMedicalObservation != 0 || d.ImportedDischargedFromMedicalObservation != 0 {
 			d.DischargedFromMedicalObservation = d.LocalDischargedFromMedicalObservation + d.ImportedDischargedFromMedicalObservation
-		}
-	} else {
-		if d.DischargedFromMedicalObservation != (d.LocalDischargedFromMedicalObservation + d.ImportedDischargedFromMedicalObservation) {
-			log.Warnf("[%s] 解除医学观察数据不匹配：总共:%d (本土:%d / 境外输入:%d)", d.Date.Format("2006-01-02"), d.DischargedFromMedicalObservation, d.LocalDischargedFromMedicalObservation, d.ImportedDischargedFromMedicalObservation)
+			d.Derived("DischargedFromMedicalObservation", "FixDaily", "LocalDischargedFromMedicalObservation + ImportedDischargedFromMedicalObservation")
 		}
 	}
 
@@ -485,10 +649,7 @@ func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 	if d.Death == 0 {
 		if d.LocalDeath != 0 || d.ImportedDeath != 0 {
 			d.Death = d.LocalDeath + d.ImportedDeath
-		}
-	} else {
-		if d.Death != (d.LocalDeath + d.ImportedDeath) {
-			log.Warnf("[%s] 死亡数据不匹配：总共:%d (本土:%d / 境外输入:%d)", d.Date.Format("2006-01-02"), d.Death, d.LocalDeath, d.ImportedDeath)
+			d.Derived("Death", "FixDaily", "LocalDeath + ImportedDeath")
 		}
 	}
 
@@ -496,10 +657,7 @@ func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 	if d.CurrentInHospital == 0 {
 		if d.CurrentLocalInHospital != 0 || d.CurrentImportedInHospital != 0 {
 			d.CurrentInHospital = d.CurrentLocalInHospital + d.CurrentImportedInHospital
-		}
-	} else {
-		if d.CurrentInHospital != (d.CurrentLocalInHospital + d.CurrentImportedInHospital) {
-			log.Warnf("[%s] 在院治疗数据不匹配：总共:%d (本土:%d / 境外输入:%d)", d.Date.Format("2006-01-02"), d.CurrentInHospital, d.CurrentLocalInHospital, d.CurrentImportedInHospital)
+			d.Derived("CurrentInHospital", "FixDaily", "CurrentLocalInHospital + CurrentImportedInHospital")
 		}
 	}
 
@@ -507,38 +665,43 @@ func (c *DailyCrawler) FixDaily(d *model.Daily) error {
 	if d.UnderMedicalObservation == 0 {
 		if d.LocalUnderMedicalObservation != 0 || d.ImportedUnderMedicalObservation != 0 {
 			d.UnderMedicalObservation = d.LocalUnderMedicalObservation + d.ImportedUnderMedicalObservation
-		}
-	} else {
-		if d.UnderMedicalObservation != (d.LocalUnderMedicalObservation + d.ImportedUnderMedicalObservation) {
-			log.Warnf("[%s] 尚在医疗观察数据不匹配：总共:%d (本土:%d / 境外输入:%d)", d.Date.Format("2006-01-02"),
-				d.UnderMedicalObservation, d.LocalUnderMedicalObservation, d.ImportedUnderMedicalObservation)
+			d.Derived("UnderMedicalObservation", "FixDaily", "LocalUnderMedicalObservation + ImportedUnderMedicalObservation")
 		}
 	}
 
-	//	本土确诊、出院、死亡、住院
-	if d.CurrentLocalInHospital+d.TotalLocalDischargedFromHospital+d.TotalLocalDeath != d.TotalLocalConfirmed {
-		log.Warnf("[%s] 本土确诊、出院、死亡、住院数据不匹配：累计本土确诊(%d) => (%d): 本土在院治疗(%d) + 累计本土治愈出院(%d) + 累计本土死亡(%d)",
-			d.Date.Format("2006-01-02"),
-			d.TotalLocalConfirmed,
-			d.CurrentLocalInHospital+d.TotalLocalDischargedFromHospital+d.TotalLocalDeath,
-			d.CurrentLocalInHospital, d.TotalLocalDischargedFromHospital, d.TotalLocalDeath)
-	}
-
-	//	境外输入确诊、出院、死亡、住院
-	if d.CurrentImportedInHospital+d.TotalImportedDischargedFromHospital != d.TotalImportedConfirmed {
-		log.Warnf("[%s] 境外输入确诊、出院、死亡、住院数据不匹配：累计境外输入确诊(%d) => (%d): 境外输入在院治疗(%d) + 累计境外输入治愈出院(%d)",
-			d.Date.Format("2006-01-02"),
-			d.TotalImportedConfirmed,
-			d.CurrentImportedInHospital+d.TotalImportedDischargedFromHospital,
-			d.CurrentImportedInHospital, d.TotalImportedDischargedFromHospital)
+	//	一致性检查
+	for _, v := range d.Validate() {
+		log.Warnf("[%s] %s (%s)", v.Key, v.Message, v.Rule)
 	}
 
 	return nil
 }
 
+// 将解析时记录的匹配文本还原为通报原文，如“新增0例”还原为“无新增”
+func restoreSnippets(d *model.Daily, texts ...normalizedText) {
+	for field, p := range d.Provenance {
+		if p.Kind != model.ProvenanceParsed {
+			continue
+		}
+		//	过长的匹配文本已被截断
+		snippet := strings.TrimSuffix(p.Snippet, "…")
+		for _, t := range texts {
+			if original, ok := t.Original(snippet); ok {
+				if original != snippet {
+					d.Parsed(field, p.Extractor, original)
+				}
+				break
+			}
+		}
+	}
+}
+
 func (c *DailyCrawler) FixDailyByResidents(d *model.Daily, rs model.Residents) error {
 	// 可以从居住地信息统计分区数据
-	if len(d.DistrictConfirmed) == 0 || len(d.DistrictAsymptomatic) == 0 {
+	if _, ok := c.parser.(DailyParserGuangzhou); ok && len(d.DistrictPositive) > 0 {
+		//	广州的通报直接给出了分区阳性总数，以通报为准，只用居住地信息补充分类型的分区数据
+		c.fixDistrictsByResidentsGuangzhou(d, rs)
+	} else if len(d.DistrictConfirmed) == 0 || len(d.DistrictAsymptomatic) == 0 {
 		// log.Tracef("FixDailyByResidents(): %#v", *d)
 		if d.DistrictAsymptomatic == nil {
 			d.DistrictAsymptomatic = make(map[string]int)
@@ -552,8 +715,10 @@ func (c *DailyCrawler) FixDailyByResidents(d *model.Daily, rs model.Residents) e
 			d.DistrictPositive = make(map[string]int)
 			// log.Tracef("FixDailyByResidents() - DistrictPositive: %#v", *d)
 		}
+		n := 0
 		for _, r := range rs {
 			if r.Date.Equal(d.Date) && len(r.District) > 0 && len(r.Type) > 0 {
+				n++
 				if r.Type == "无症状感染者" {
 					//	无症状感染者
 					if val, ok := d.DistrictAsymptomatic[r.District]; ok {
@@ -575,6 +740,12 @@ func (c *DailyCrawler) FixDailyByResidents(d *model.Daily, rs model.Residents) e
 				}
 			}
 		}
+		if n > 0 {
+			snippet := fmt.Sprintf("%d 条居住地信息", n)
+			d.Inferred("DistrictConfirmed", "FixDailyByResidents", snippet)
+			d.Inferred("DistrictAsymptomatic", "FixDailyByResidents", snippet)
+			d.Inferred("DistrictPositive", "FixDailyByResidents", snippet)
+		}
 	}
 	// 从居住地信息统计分型数据
 	if d.Confirmed > 0 && d.Mild == 0 && d.Common == 0 && d.Severe == 0 && d.Critical == 0 {
@@ -592,13 +763,56 @@ func (c *DailyCrawler) FixDailyByResidents(d *model.Daily, rs model.Residents) e
 				}
 			}
 		}
+		for field, v := range map[string]int{"Mild": d.Mild, "Common": d.Common, "Severe": d.Severe, "Critical": d.Critical} {
+			if v > 0 {
+				d.Inferred(field, "FixDailyByResidents", fmt.Sprintf("%d 条居住地信息", v))
+			}
+		}
 	}
 	return nil
 }
 
+// 从居住地信息统计广州缺失的确诊、无症状分区数据，保留通报中的分区阳性总数
+func (c *DailyCrawler) fixDistrictsByResidentsGuangzhou(d *model.Daily, rs model.Residents) {
+	confirmed := make(map[string]int)
+	asymptomatic := make(map[string]int)
+	n := 0
+	for _, r := range rs {
+		if r.Date.Equal(d.Date) && len(r.District) > 0 && len(r.Type) > 0 {
+			n++
+			if r.Type == "无症状感染者" {
+				asymptomatic[r.District]++
+			} else {
+				confirmed[r.District]++
+			}
+		}
+	}
+	if n == 0 {
+		return
+	}
+	snippet := fmt.Sprintf("%d 条居住地信息", n)
+	if len(d.DistrictConfirmed) == 0 {
+		d.DistrictConfirmed = confirmed
+		d.Inferred("DistrictConfirmed", "FixDailyByResidents", snippet)
+	}
+	if len(d.DistrictAsymptomatic) == 0 {
+		d.DistrictAsymptomatic = asymptomatic
+		d.Inferred("DistrictAsymptomatic", "FixDailyByResidents", snippet)
+	}
+}
+
+// 解析器按日历日期生成日期（时区为 UTC），将其改为所在城市时区的同一日期、时间
+func (c *DailyCrawler) inTimeZone(t time.Time) time.Time {
+	if t.IsZero() || c.location == nil {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), c.location)
+}
+
 //	Listener functions
 
 ///	OnDailyListener
+
 func (c *DailyCrawler) AddOnDailyListener(f func(model.Daily)) {
 	if f == nil {
 		log.Warn("DailyCrawler.AddOnDailyListener(): couldn't add 'nil' as listener.")
@@ -613,6 +827,13 @@ func (c *DailyCrawler) ClearOnDailyListener() {
 }
 
 func (c *DailyCrawler) OnDaily(h model.Daily) {
+	h.Date = c.inTimeZone(h.Date)
+	if len(h.DeathCases) > 0 {
+		h.DeathCases = append(model.DeathCases(nil), h.DeathCases...)
+		for i := range h.DeathCases {
+			h.DeathCases[i].Date = c.inTimeZone(h.DeathCases[i].Date)
+		}
+	}
 	for _, listener := range c.listenersDaily {
 		listener(h)
 	}
@@ -633,7 +854,80 @@ func (c *DailyCrawler) ClearOnResidentsListener() {
 }
 
 func (c *DailyCrawler) OnResidents(h model.Residents) {
+	h = append(model.Residents(nil), h...)
+	for i := range h {
+		h[i].Date = c.inTimeZone(h[i].Date)
+	}
 	for _, listener := range c.listenersResidents {
 		listener(h)
 	}
 }
+
+/// OnRiskAreasListener
+func (c *DailyCrawler) AddOnRiskAreasListener(f func(model.RiskAreaNotices)) {
+	if f == nil {
+		log.Warn("DailyCrawler.AddOnRiskAreasListener(): couldn't add 'nil' as listener.")
+		return
+	}
+
+	c.listenersRiskAreas = append(c.listenersRiskAreas, f)
+}
+
+func (c *DailyCrawler) ClearOnRiskAreasListener() {
+	c.listenersRiskAreas = []func(model.RiskAreaNotices){}
+}
+
+func (c *DailyCrawler) OnRiskAreas(h model.RiskAreaNotices) {
+	h = append(model.RiskAreaNotices(nil), h...)
+	for i := range h {
+		h[i].Date = c.inTimeZone(h[i].Date)
+	}
+	for _, listener := range c.listenersRiskAreas {
+		listener(h)
+	}
+}
+
+/// OnZonesListener
+func (c *DailyCrawler) AddOnZonesListener(f func(model.Zones)) {
+	if f == nil {
+		log.Warn("DailyCrawler.AddOnZonesListener(): couldn't add 'nil' as listener.")
+		return
+	}
+
+	c.listenersZones = append(c.listenersZones, f)
+}
+
+func (c *DailyCrawler) ClearOnZonesListener() {
+	c.listenersZones = []func(model.Zones){}
+}
+
+func (c *DailyCrawler) OnZones(h model.Zones) {
+	h = append(model.Zones(nil), h...)
+	for i := range h {
+		h[i].Date = c.inTimeZone(h[i].Date)
+	}
+	for _, listener := range c.listenersZones {
+		listener(h)
+	}
+}
+
+/// OnBriefingListener
+func (c *DailyCrawler) AddOnBriefingListener(f func(model.Briefing)) {
+	if f == nil {
+		log.Warn("DailyCrawler.AddOnBriefingListener(): couldn't add 'nil' as listener.")
+		return
+	}
+
+	c.listenersBriefing = append(c.listenersBriefing, f)
+}
+
+func (c *DailyCrawler) ClearOnBriefingListener() {
+	c.listenersBriefing = []func(model.Briefing){}
+}
+
+func (c *DailyCrawler) OnBriefing(h model.Briefing) {
+	h.Date = c.inTimeZone(h.Date)
+	for _, listener := range c.listenersBriefing {
+		listener(h)
+	}
+}
@@ -1 +1,372 @@
 package crawler
+
+import (
+	"crawler/model"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchive(t *testing.T) {
+	a, err := NewArchive(t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, ARCHIVE_VERSION, a.Version())
+
+	p := ArchivePage{
+		URL:        "http://wjw.beijing.gov.cn/wjwh/ztzl/xxgzbd/gzbdyqtb/202205/t20220502_2699935.html",
+		FetchedAt:  time.Date(2022, 5, 2, 10, 0, 0, 0, time.UTC),
+		StatusCode: 200,
+		Headers:    http.Header{"Content-Type": []string{"text/html; charset=gb2312"}},
+		Body:       []byte("<html></html>"),
+	}
+	assert.NoError(t, a.Put(p))
+
+	//	重新打开存档
+	b, err := OpenArchive(a.Dir())
+	assert.NoError(t, err)
+	p2, err := b.Get(p.URL)
+	assert.NoError(t, err)
+	assert.EqualValues(t, p, *p2)
+
+	_, err = b.Get("http://example.com/")
+	assert.ErrorIs(t, err, ErrArchivePageNotFound)
+}
+
+func beijingIndexPage(links map[string]string) []byte {
+	html := `<html><body><ul class="listLk">`
+	for link, title := range links {
+		html += `<li><a href="` + link + `">` + title + `</a></li>`
+	}
+	html += `</ul></body></html>`
+	return []byte(html)
+}
+
+func beijingItemPage(title, content string) []byte {
+	return []byte(`<html><body><div class="article0"><div class="articleTitle">` + title + `</div><div class="article"><p>` + content + `</p></div></div></body></html>`)
+}
+
+func collectDailys(dc *DailyCrawler) model.Dailys {
+	var lock sync.Mutex
+	var ds model.Dailys
+	dc.AddOnDailyListener(func(d model.Daily) {
+		lock.Lock()
+		defer lock.Unlock()
+		ds = append(ds, d)
+	})
+	dc.Collect()
+	ds.Sort()
+	return ds
+}
+
+// 北京的两页索引，共4天的通报
+func newBeijingArchive(t *testing.T) *Archive {
+	const (
+		LINK_INDEX_0 = "http://wjw.beijing.gov.cn/wjwh/ztzl/xxgzbd/gzbdyqtb/index.html"
+		LINK_INDEX_1 = "http://wjw.beijing.gov.cn/wjwh/ztzl/xxgzbd/gzbdyqtb/index_1.html"
+		LINK_ITEM    = "http://wjw.beijing.gov.cn/wjwh/ztzl/xxgzbd/gzbdyqtb/"
+	)
+	items := []struct {
+		link  string
+		title string
+	}{
+		{"202205/t20220503.html", "北京5月2日新增41例本土确诊病例、 9例本土无症状感染者 治愈出院20例"},
+		{"202205/t20220502.html", "北京5月1日新增36例本土确诊病例、 5例本土无症状感染者 治愈出院10例"},
+		{"202205/t20220501.html", "北京4月30日新增53例本土确诊病例、6例本土无症状感染者和1例境外输入无症状感染者 治愈出院11例"},
+		{"202204/t20220430.html", "北京4月29日新增49例本土确诊病例、 1例本土无症状感染者 治愈出院9例"},
+	}
+
+	a, err := NewArchive(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, a.Put(ArchivePage{URL: LINK_INDEX_0, Body: beijingIndexPage(map[string]string{
+		"/wjwh/ztzl/xxgzbd/gzbdyqtb/" + items[0].link: items[0].title,
+		"/wjwh/ztzl/xxgzbd/gzbdyqtb/" + items[1].link: items[1].title,
+		"/wjwh/ztzl/xxgzbd/gzbdyqtb/" + items[2].link: items[2].title,
+	})}))
+	assert.NoError(t, a.Put(ArchivePage{URL: LINK_INDEX_1, Body: beijingIndexPage(map[string]string{
+		"/wjwh/ztzl/xxgzbd/gzbdyqtb/" + items[3].link: items[3].title,
+	})}))
+	for _, it := range items {
+		assert.NoError(t, a.Put(ArchivePage{URL: LINK_ITEM + it.link, Body: beijingItemPage(it.title, "")}))
+	}
+	return a
+}
+
+func TestDailyCrawler_Incremental(t *testing.T) {
+	dc, err := NewDailyCrawler("beijing", "")
+	assert.NoError(t, err)
+	dc.Replay(newBeijingArchive(t))
+	dc.SetIncremental(s2date("2022-05-01"))
+	ds := collectDailys(dc)
+
+	//	已有数据的最新一天会重新抓取，更早的通报以及之后的索引页都不再抓取
+	if assert.Len(t, ds, 2) {
+		assert.Equal(t, s2localdate("2022-05-02"), ds[0].Date)
+		assert.Equal(t, s2localdate("2022-05-01"), ds[1].Date)
+	}
+	assert.EqualValues(t, 2, dc.PageVisited)
+}
+
+// 带有历史内容页面的北京解析器
+type dailyParserBeijingWithItems struct {
+	DailyParserBeijing
+}
+
+func (p dailyParserBeijingWithItems) GetItemLinks() []string {
+	return []string{"http://wjw.beijing.gov.cn/wjwh/ztzl/xxgzbd/gzbdyqtb/202204/t20220430.html"}
+}
+
+func TestDailyCrawler_IncrementalStopsPaging(t *testing.T) {
+	dc, err := NewDailyCrawler("beijing", "")
+	assert.NoError(t, err)
+	dc.parser = dailyParserBeijingWithItems{}
+	dc.Replay(newBeijingArchive(t))
+
+	var lock sync.Mutex
+	var visited []string
+	dc.cIndex.OnRequest(func(r *colly.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		visited = append(visited, r.URL.String())
+	})
+	dc.cItem.OnRequest(func(r *colly.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		visited = append(visited, r.URL.String())
+	})
+	dc.SetIncremental(s2date("2022-05-01"))
+	ds := collectDailys(dc)
+
+	//	第一页索引已出现更早的通报，不再翻到第二页；指定的历史内容页面也不抓取
+	assert.Len(t, ds, 2)
+	assert.NotContains(t, visited, "http://wjw.beijing.gov.cn/wjwh/ztzl/xxgzbd/gzbdyqtb/index_1.html")
+	assert.NotContains(t, visited, "http://wjw.beijing.gov.cn/wjwh/ztzl/xxgzbd/gzbdyqtb/202204/t20220430.html")
+	assert.Len(t, visited, 3)
+
+	//	同时指定了 until 时，仍然抓取指定内容页面
+	dc, err = NewDailyCrawler("beijing", "")
+	assert.NoError(t, err)
+	dc.parser = dailyParserBeijingWithItems{}
+	dc.Replay(newBeijingArchive(t))
+	dc.SetDateRange(time.Time{}, s2date("2022-05-02"))
+	dc.SetIncremental(s2date("2022-05-01"))
+	collectDailys(dc)
+	assert.EqualValues(t, 3, dc.PageVisited)
+}
+
+func TestDailyCrawler_DateRange(t *testing.T) {
+	dc, err := NewDailyCrawler("beijing", "")
+	assert.NoError(t, err)
+	dc.Replay(newBeijingArchive(t))
+	dc.SetDateRange(s2date("2022-04-29"), s2date("2022-04-30"))
+	ds := collectDailys(dc)
+
+	if assert.Len(t, ds, 2) {
+		assert.Equal(t, s2localdate("2022-04-30"), ds[0].Date)
+		assert.Equal(t, s2localdate("2022-04-29"), ds[1].Date)
+	}
+	assert.EqualValues(t, 2, dc.PageVisited)
+}
+
+func TestDailyCrawler_Replay(t *testing.T) {
+	const (
+		LINK_INDEX = "http://wjw.beijing.gov.cn/wjwh/ztzl/xxgzbd/gzbdyqtb/index.html"
+		LINK_ITEM  = "http://wjw.beijing.gov.cn/wjwh/ztzl/xxgzbd/gzbdyqtb/202205/t20220502_2699935.html"
+		TITLE      = "北京5月1日新增36例本土确诊病例、 5例本土无症状感染者 治愈出院10例"
+	)
+
+	a, err := NewArchive(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, a.Put(ArchivePage{URL: LINK_INDEX, Body: beijingIndexPage(map[string]string{
+		"/wjwh/ztzl/xxgzbd/gzbdyqtb/202205/t20220502_2699935.html": TITLE,
+	})}))
+	assert.NoError(t, a.Put(ArchivePage{URL: LINK_ITEM, Body: beijingItemPage(TITLE,
+		"5月1日0时至24时，新增36例本土确诊病例和5例无症状感染者，无新增疑似病例；无新增境外输入确诊病例、疑似病例和无症状感染者。治愈出院10例。")}))
+
+	dc, err := NewDailyCrawler("beijing", "")
+	assert.NoError(t, err)
+	dc.Replay(a)
+	ds := collectDailys(dc)
+
+	if assert.Len(t, ds, 1) {
+		assert.Equal(t, s2localdate("2022-05-01"), ds[0].Date)
+		assert.Equal(t, 36, ds[0].LocalConfirmed)
+		assert.Equal(t, 5, ds[0].LocalAsymptomatic)
+		assert.Equal(t, 10, ds[0].DischargedFromHospital)
+		assert.Equal(t, LINK_ITEM, ds[0].Source)
+	}
+}
+
+func TestCities(t *testing.T) {
+	for _, key := range []string{"shanghai", "beijing"} {
+		c, err := GetCity(key)
+		if assert.NoError(t, err) {
+			assert.Equal(t, key, c.Key)
+			assert.NotNil(t, c.Parser)
+			assert.EqualValues(t, c.Parser.GetDistricts(), c.Districts)
+			assert.Equal(t, TIMEZONE_CHINA, c.TimeZone)
+		}
+	}
+
+	_, err := NewDailyCrawler("atlantis", "")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "atlantis")
+	}
+}
+
+func TestDailyCrawler_TimeZone(t *testing.T) {
+	dc, err := NewDailyCrawler("shanghai", "")
+	assert.NoError(t, err)
+
+	//	按城市时区的日历日期比较，与输入的时区无关
+	dc.SetDateRange(s2date("2022-05-11"), s2date("2022-05-11"))
+	assert.True(t, dc.InDateRange(s2date("2022-05-11")))
+	assert.True(t, dc.InDateRange(s2localdate("2022-05-11")))
+	assert.False(t, dc.InDateRange(s2date("2022-05-10")))
+	assert.False(t, dc.InDateRange(s2localdate("2022-05-12")))
+
+	//	输出的日期为城市时区的同一日期，不修改解析器产生的数据
+	rs := model.Residents{{Date: s2date("2022-05-11"), District: "黄浦区"}}
+	var got model.Residents
+	dc.AddOnResidentsListener(func(fresh model.Residents) { got = fresh })
+	dc.OnResidents(rs)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, s2localdate("2022-05-11"), got[0].Date)
+		assert.Equal(t, "2022-05-11", got[0].Date.Format("2006-01-02"))
+	}
+	assert.Equal(t, s2date("2022-05-11"), rs[0].Date)
+}
+
+func TestFixDaily_Validate(t *testing.T) {
+	dc, err := NewDailyCrawler("shanghai", "")
+	assert.NoError(t, err)
+
+	//	补全计算得到的字段后，数据应当一致
+	d := model.Daily{
+		Date:                     s2date("2022-05-11"),
+		LocalConfirmed:           144,
+		LocalAsymptomatic:        1305,
+		ImportedAsymptomatic:     1,
+		LocalConfirmedFromBubble: 38,
+		DistrictConfirmedFromBubble: map[string]int{
+			"浦东新区": 30,
+			"徐汇区":  8,
+		},
+	}
+	assert.NoError(t, dc.FixDaily(&d))
+	assert.Equal(t, 1450, d.Positive)
+	assert.Equal(t, 106, d.LocalConfirmedFromRisk)
+	assert.Empty(t, d.Validate())
+
+	//	分区之和不匹配为警告，总数不匹配为错误
+	d.DistrictConfirmedFromBubble["徐汇区"] = 7
+	d.Asymptomatic = 1300
+	vs := d.Validate()
+	if assert.Len(t, vs, 3) {
+		rules := map[string]model.Severity{}
+		for _, v := range vs {
+			rules[v.Rule] = v.Severity
+			assert.Equal(t, "2022-05-11", v.Key)
+		}
+		assert.Equal(t, model.SeverityError, rules["asymptomatic-sum"])
+		assert.Equal(t, model.SeverityError, rules["positive-sum"])
+		assert.Equal(t, model.SeverityWarning, rules["confirmed-from-bubble-district"])
+	}
+	assert.True(t, vs.HasErrors())
+	assert.Equal(t, 1, vs.Count(model.SeverityWarning))
+}
+
+func TestFixDaily_ConfirmedFromRisk(t *testing.T) {
+	//	4月14日全国通报：本土病例3000例，含1979例由无症状感染者转为确诊病例，没有隔离管控中发现的数量
+	national := model.Daily{Date: s2date("2022-04-14"), LocalConfirmed: 3000, LocalConfirmedFromAsymptomatic: 1979}
+	dc, err := NewDailyCrawler("national", "")
+	assert.NoError(t, err)
+	assert.NoError(t, dc.FixDaily(&national))
+	assert.Zero(t, national.LocalConfirmedFromRisk)
+
+	//	城市通报中，其余的来自风险人群
+	shanghai := model.Daily{Date: s2date("2022-05-11"), LocalConfirmed: 144, LocalConfirmedFromAsymptomatic: 106, LocalConfirmedFromBubble: 30}
+	dc, err = NewDailyCrawler("shanghai", "")
+	assert.NoError(t, err)
+	assert.NoError(t, dc.FixDaily(&shanghai))
+	assert.Equal(t, 8, shanghai.LocalConfirmedFromRisk)
+}
+
+func TestFixDaily_Provenance(t *testing.T) {
+	dc, err := NewDailyCrawler("shanghai", "")
+	assert.NoError(t, err)
+
+	var d model.Daily
+	d.EnableProvenance()
+	content := "2022年5月11日0—24时，新增本土新冠肺炎确诊病例144例和无症状感染者1305例，其中38例确诊病例在隔离管控中发现。"
+	assert.NoError(t, DailyParserShanghai{}.ParseDailyContent(&d, content))
+	assert.NoError(t, dc.FixDaily(&d))
+
+	p := d.Provenance["LocalConfirmed"]
+	assert.Equal(t, model.ProvenanceParsed, p.Kind)
+	assert.Equal(t, "reDailyLocalConfirmed", p.Extractor)
+	assert.Contains(t, p.Snippet, "144")
+
+	p = d.Provenance["LocalPositive"]
+	assert.Equal(t, model.ProvenanceDerived, p.Kind)
+	assert.Equal(t, "FixDaily", p.Extractor)
+	assert.Equal(t, "LocalConfirmed + LocalAsymptomatic", p.Snippet)
+
+	//	每个有值的字段都应当有来源
+	for _, field := range d.PopulatedFields() {
+		assert.Contains(t, d.Provenance, field)
+	}
+
+	//	居住地信息推算的分区数据
+	d.DistrictConfirmed = nil
+	d.DistrictAsymptomatic = nil
+	rs := model.Residents{
+		{Date: d.Date, District: "浦东新区", Type: "无症状感染者"},
+		{Date: d.Date, District: "徐汇区", Type: "轻型"},
+	}
+	assert.NoError(t, dc.FixDailyByResidents(&d, rs))
+	assert.Equal(t, model.ProvenanceResidents, d.Provenance["DistrictAsymptomatic"].Kind)
+	assert.Equal(t, model.ProvenanceResidents, d.Provenance["Mild"].Kind)
+
+	//	未开启时不记录
+	var o model.Daily
+	o.Parsed("LocalConfirmed", "reDailyLocalConfirmed", content)
+	assert.Nil(t, o.Provenance)
+}
+
+func TestFixDailyByResidents_Guangzhou(t *testing.T) {
+	dc, err := NewDailyCrawler("guangzhou", "")
+	assert.NoError(t, err)
+
+	date := s2date("2022-04-10")
+	rs := model.Residents{
+		{Date: date, District: "白云区", Type: "无症状感染者"},
+		{Date: date, District: "白云区", Type: "无症状感染者"},
+		{Date: date, District: "白云区", Type: "确诊病例"},
+		{Date: date, District: "番禺区", Type: "确诊病例"},
+		{Date: date.AddDate(0, 0, 1), District: "番禺区", Type: "确诊病例"},
+	}
+
+	//	通报中的分区阳性数不被覆盖，只补充分类型的分区数据
+	d := model.Daily{Date: date, DistrictPositive: map[string]int{"白云区": 3, "番禺区": 2}}
+	d.EnableProvenance()
+	d.Parsed("DistrictPositive", "reDailyRegionListGuangzhou", "白云区3例，番禺区2例")
+	assert.NoError(t, dc.FixDailyByResidents(&d, rs))
+	assert.Equal(t, map[string]int{"白云区": 3, "番禺区": 2}, d.DistrictPositive)
+	assert.Equal(t, model.ProvenanceParsed, d.Provenance["DistrictPositive"].Kind)
+	assert.Equal(t, map[string]int{"白云区": 1, "番禺区": 1}, d.DistrictConfirmed)
+	assert.Equal(t, map[string]int{"白云区": 2}, d.DistrictAsymptomatic)
+	assert.Equal(t, model.ProvenanceResidents, d.Provenance["DistrictConfirmed"].Kind)
+
+	//	通报与病例列表不一致时由校验报告
+	vs := d.Validate()
+	if assert.Len(t, vs, 1) {
+		assert.Equal(t, "positive-district-by-type", vs[0].Rule)
+		assert.Equal(t, 2, vs[0].Actual)
+		assert.Equal(t, 1, vs[0].Expected)
+		assert.Contains(t, vs[0].Message, "番禺区")
+	}
+}
@@ -0,0 +1,46 @@
+package crawler
+
+import (
+	"crawler/model"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//	死亡病例详情
+//
+//	2022年4月起，上海的每日通报逐一描述死亡病例，如：
+//		死亡病例1，女，89岁，患有冠心病、高血压、糖尿病等基础疾病。
+//		患者2：男，73岁，既往有肺癌病史。
+
+var (
+	reDailyDeathCase           = regexp.MustCompile(`(?:死亡病例|患者)(?P<number>\d+)[：:，,]\s*(?P<gender>男|女)性?[，,、]\s*(?P<age>\d+)岁(?P<detail>[^。\n]*)`)
+	reDailyDeathCaseConditions = regexp.MustCompile(`(?:患有|合并有?|既往有?)(?P<list>[^。；，]+?)(?:等(?:多种|严重)?(?:基础疾病|病史)|基础疾病|病史|[，；]|$)`)
+)
+
+// 解析通报中的死亡病例详情，没有时返回 nil
+func parseDeathCases(d *model.Daily, city, content string) model.DeathCases {
+	var cs model.DeathCases
+	for _, m := range reDailyDeathCase.FindAllStringSubmatch(content, -1) {
+		c := model.DeathCase{
+			Date:   d.Date,
+			City:   city,
+			Gender: m[2],
+			Source: d.Source,
+		}
+		c.Number, _ = strconv.Atoi(m[1])
+		c.Age, _ = strconv.Atoi(m[3])
+		if mc := reDailyDeathCaseConditions.FindStringSubmatch(m[4]); mc != nil {
+			for _, s := range strings.FieldsFunc(mc[1], func(r rune) bool { return r == '、' || r == '和' || r == '及' }) {
+				if s = strings.TrimSpace(s); len(s) > 0 {
+					c.Conditions = append(c.Conditions, s)
+				}
+			}
+		}
+		cs = append(cs, c)
+	}
+	if len(cs) > 0 {
+		d.Parsed("DeathCases", "reDailyDeathCase", strings.Join(reDailyDeathCase.FindAllString(content, -1), "\n"))
+	}
+	return cs
+}
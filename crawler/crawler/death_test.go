@@ -0,0 +1,42 @@
+package crawler
+
+import (
+	"crawler/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeathCases(t *testing.T) {
+	content := "4月18日0—24时，新增本土死亡3例。\n" +
+		"死亡病例1，女，89岁，患有冠心病、高血压、糖尿病和脑梗后遗症等基础疾病。\n" +
+		"死亡病例2，男，91岁，既往有肺癌病史。\n" +
+		"患者3：女性，88岁，入院后经全力救治无效死亡。"
+
+	d := model.Daily{Date: s2date("2022-04-18"), Source: "http://example.com/1"}
+	d.EnableProvenance()
+	cs := parseDeathCases(&d, "上海市", content)
+	assert.Equal(t, model.DeathCases{
+		{Date: s2date("2022-04-18"), City: "上海市", Number: 1, Gender: "女", Age: 89, Conditions: []string{"冠心病", "高血压", "糖尿病", "脑梗后遗症"}, Source: "http://example.com/1"},
+		{Date: s2date("2022-04-18"), City: "上海市", Number: 2, Gender: "男", Age: 91, Conditions: []string{"肺癌"}, Source: "http://example.com/1"},
+		{Date: s2date("2022-04-18"), City: "上海市", Number: 3, Gender: "女", Age: 88, Source: "http://example.com/1"},
+	}, cs)
+	assert.Equal(t, model.ProvenanceParsed, d.Provenance["DeathCases"].Kind)
+
+	//	居住地信息中的“病例2，男，73岁”不是死亡病例
+	assert.Nil(t, parseDeathCases(&d, "上海市", "病例2，男，73岁，居住于黄浦区顺昌路612弄，"))
+}
+
+func TestDaily_Validate_DeathCases(t *testing.T) {
+	d := model.Daily{Date: s2date("2022-04-18"), LocalDeath: 3, Death: 3}
+	d.DeathCases = model.DeathCases{{Number: 1}, {Number: 2}}
+	vs := d.Validate()
+	if assert.Len(t, vs, 1) {
+		assert.Equal(t, "death-cases-count", vs[0].Rule)
+		assert.Equal(t, 2, vs[0].Expected)
+		assert.Equal(t, 3, vs[0].Actual)
+	}
+
+	d.DeathCases = append(d.DeathCases, model.DeathCase{Number: 3})
+	assert.Empty(t, d.Validate())
+}
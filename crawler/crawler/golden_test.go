@@ -0,0 +1,111 @@
+package crawler
+
+import (
+	"crawler/model"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//	回归测试语料
+//
+//	testdata/<city>/ 下每个 <name>.html 是一篇保存下来的通报页面，<name>.json 是期望的解析结果。
+//	测试以真实的 ParseItem 流程（包括选择器）解析每个页面，并与期望结果对比。
+//	修改正则或解析逻辑后，确认差异无误，可以用下面的命令重新生成期望结果：
+//		go test ./crawler/ -run TestGolden -update
+
+var update = flag.Bool("update", false, "重新生成 testdata 中的期望结果")
+
+type golden struct {
+	Dailys    model.Dailys
+	Residents model.Residents
+}
+
+// 只能从存档中读取该页面的爬虫
+func newGoldenCrawler(t *testing.T, city, link string, body []byte) *DailyCrawler {
+	a, err := NewArchive(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, a.Put(ArchivePage{URL: link, Body: body}))
+
+	dc, err := NewDailyCrawler(city, "")
+	assert.NoError(t, err)
+	dc.Replay(a)
+	return dc
+}
+
+// 每个城市的语料页面
+func goldenPages(t *testing.T) map[string][]string {
+	cities, err := os.ReadDir("testdata")
+	assert.NoError(t, err)
+	pages := make(map[string][]string)
+	for _, city := range cities {
+		if !city.IsDir() {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join("testdata", city.Name(), "*.html"))
+		assert.NoError(t, err)
+		pages[city.Name()] = files
+	}
+	return pages
+}
+
+func goldenLink(page string) string {
+	return "http://" + filepath.ToSlash(page)
+}
+
+// 以 city 的解析器解析一个页面，返回 ParseItem 产生的全部数据
+func parseGoldenPage(t *testing.T, city, link string, body []byte) golden {
+	dc := newGoldenCrawler(t, city, link, body)
+
+	var lock sync.Mutex
+	var g golden
+	dc.AddOnDailyListener(func(d model.Daily) {
+		lock.Lock()
+		defer lock.Unlock()
+		g.Dailys = append(g.Dailys, d)
+	})
+	dc.AddOnResidentsListener(func(rs model.Residents) {
+		lock.Lock()
+		defer lock.Unlock()
+		g.Residents = append(g.Residents, rs...)
+	})
+	dc.cItem.Visit(link)
+	dc.cItem.Wait()
+
+	g.Dailys.Sort()
+	g.Residents.Sort()
+	return g
+}
+
+func TestGolden(t *testing.T) {
+	for city, pages := range goldenPages(t) {
+		for _, page := range pages {
+			city, page := city, page
+			t.Run(strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(page), "testdata/"), ".html"), func(t *testing.T) {
+				body, err := os.ReadFile(page)
+				assert.NoError(t, err)
+
+				g := parseGoldenPage(t, city, goldenLink(page), body)
+				actual, err := json.MarshalIndent(g, "", "  ")
+				assert.NoError(t, err)
+
+				file_golden := strings.TrimSuffix(page, ".html") + ".json"
+				if *update {
+					assert.NoError(t, os.WriteFile(file_golden, append(actual, '\n'), 0644))
+					return
+				}
+				expected, err := os.ReadFile(file_golden)
+				if !assert.NoErrorf(t, err, "缺少期望结果，请用 -update 生成：%s", file_golden) {
+					return
+				}
+				assert.JSONEq(t, string(expected), string(actual))
+			})
+		}
+	}
+}
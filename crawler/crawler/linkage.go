@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	"crawler/model"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//	病例之间的关联
+//
+//	北京的通报中会说明病例与之前病例、聚集性疫情或场所的关系，如：
+//		4月23日作为感染者146的密切接触者进行集中隔离
+//		为4月25日通报的确诊病例5的同住人员
+//		与朝阳区双井街道聚集性疫情有关联
+//		均为朝阳区护国寺小吃（光明桥店）员工
+//		曾到访朝阳区潘家园旧货市场
+
+var (
+	reLinkInfector = regexp.MustCompile(`(?:为|系|作为)(?:(?P<date>(?:\d+年)?\d+月\d+日)(?:已)?(?:通报|报告|公布)的)?(?P<infector>(?:确诊病例|无症状感染者|感染者)\s*\d+)的?(?:密切接触者|密接|次密接|同住人员|家庭成员|同事|同学)`)
+	reLinkRelated  = regexp.MustCompile(`与(?P<target>[^，。；、]{2,40}?)(?:有|存在|相)关联`)
+	reLinkCase     = regexp.MustCompile(`^(?:(?P<date>(?:\d+年)?\d+月\d+日)(?:已)?(?:通报|报告|公布)的)?(?P<infector>(?:确诊病例|无症状感染者|感染者)\s*\d+)$`)
+	reLinkCluster  = regexp.MustCompile(`(?:均为|为|系|属于|属)?(?P<cluster>[^，。；：、\s]{2,30}?聚集性疫情)`)
+	reLinkWorker   = regexp.MustCompile(`为(?P<venue>[^，。；：、\s]{2,30}?)的?(?:员工|工作人员|从业人员|顾客|就餐人员)`)
+	reLinkVisit    = regexp.MustCompile(`(?:曾到访|到访过|曾前往|曾去过)(?P<venue>[^，。；：、\s]{2,30}?)(?:就餐|购物|消费|工作)?[，。；]`)
+)
+
+// 通报中提到的病例，注明日期时与 model.Resident.Key() 一致
+func linkCase(date time.Time, m []string, re *regexp.Regexp) string {
+	name := strings.ReplaceAll(m[re.SubexpIndex("infector")], " ", "")
+	s := m[re.SubexpIndex("date")]
+	if len(s) == 0 {
+		return name
+	}
+	if !strings.Contains(s, "年") {
+		year := 2022
+		if !date.IsZero() {
+			year = date.Year()
+		}
+		s = fmt.Sprintf("%d年%s", year, s)
+	}
+	d, err := time.Parse("2006年1月2日", s)
+	if err != nil {
+		return name
+	}
+	return d.Format("2006-01-02") + "." + name
+}
+
+// 从一段病例描述中解析传染源、聚集性疫情和暴露场所
+func parseLinkage(r *model.Resident, date time.Time, text string) {
+	if m := reLinkInfector.FindStringSubmatch(text); m != nil {
+		r.Infector = linkCase(date, m, reLinkInfector)
+	}
+
+	//	“与……有关联”，可能是病例，也可能是聚集性疫情
+	if m := reLinkRelated.FindStringSubmatch(text); m != nil {
+		target := strings.TrimSpace(m[1])
+		if mc := reLinkCase.FindStringSubmatch(target); mc != nil {
+			if len(r.Infector) == 0 {
+				r.Infector = linkCase(date, mc, reLinkCase)
+			}
+		} else {
+			r.Cluster = target
+		}
+	}
+	if len(r.Cluster) == 0 {
+		if m := reLinkCluster.FindStringSubmatch(text); m != nil {
+			r.Cluster = m[1]
+		}
+	}
+
+	if m := reLinkWorker.FindStringSubmatch(text); m != nil {
+		r.Venue = m[1]
+	} else if m := reLinkVisit.FindStringSubmatch(text); m != nil {
+		r.Venue = m[1]
+	}
+}
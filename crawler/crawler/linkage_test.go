@@ -0,0 +1,61 @@
+package crawler
+
+import (
+	"crawler/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinkage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want model.Resident
+	}{
+		{"密切接触者", "感染者231、234：现住址均位于朝阳区，在校学生。4月23日作为感染者146的密切接触者进行集中隔离，4月25日报告核酸检测结果均为阳性。", model.Resident{Infector: "感染者146"}},
+		{"注明日期的传染源", "确诊病例3：现住丰台区。为4月25日通报的确诊病例5的同住人员，4月26日诊断为确诊病例。", model.Resident{Infector: "2022-04-25.确诊病例5"}},
+		{"与病例有关联", "确诊病例7：现住房山区。与确诊病例12有关联，当日诊断为确诊病例。", model.Resident{Infector: "确诊病例12"}},
+		{"与聚集性疫情有关联", "确诊病例8：现住朝阳区双井街道。与朝阳区双井街道聚集性疫情有关联，当日诊断为确诊病例。", model.Resident{Cluster: "朝阳区双井街道聚集性疫情"}},
+		{"聚集性疫情关联病例", "确诊病例9：为房山区燕山地区聚集性疫情关联病例，现住房山区燕山地区。", model.Resident{Cluster: "房山区燕山地区聚集性疫情"}},
+		{"场所员工", "确诊病例14、15：均为朝阳区护国寺小吃（光明桥店）员工。4月25日报告核酸检测结果均为阳性。", model.Resident{Venue: "朝阳区护国寺小吃（光明桥店）"}},
+		{"到访场所", "确诊病例20：现住朝阳区。曾到访朝阳区潘家园旧货市场，5月1日诊断为确诊病例。", model.Resident{Venue: "朝阳区潘家园旧货市场"}},
+		{"没有关联", "确诊病例2：现住朝阳区松榆东里。4月30日诊断为确诊病例，临床分型为轻型。", model.Resident{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r model.Resident
+			parseLinkage(&r, s2date("2022-04-27"), tt.text)
+			assert.Equal(t, tt.want, r)
+		})
+	}
+}
+
+func TestResidents_ChainEdges(t *testing.T) {
+	rs := model.Residents{
+		{Date: s2date("2022-04-26"), Name: "确诊病例5", City: "北京市"},
+		{Date: s2date("2022-04-26"), Name: "确诊病例6", City: "北京市", Infector: "确诊病例5"},
+		{Date: s2date("2022-04-27"), Name: "确诊病例3", City: "北京市", Infector: "2022-04-26.确诊病例6", Cluster: "朝阳区双井街道聚集性疫情"},
+		{Date: s2date("2022-04-27"), Name: "确诊病例4", City: "北京市", Venue: "朝阳区潘家园旧货市场"},
+	}
+	es := rs.ChainEdges()
+	assert.Equal(t, model.ChainEdges{
+		{Date: s2date("2022-04-27"), City: "北京市", From: "2022-04-26.确诊病例6", FromType: model.ChainCase, To: "2022-04-27.确诊病例3"},
+		{Date: s2date("2022-04-27"), City: "北京市", From: "朝阳区双井街道聚集性疫情", FromType: model.ChainCluster, To: "2022-04-27.确诊病例3"},
+		{Date: s2date("2022-04-27"), City: "北京市", From: "朝阳区潘家园旧货市场", FromType: model.ChainVenue, To: "2022-04-27.确诊病例4"},
+		//	没有注明日期的传染源关联到同一天的病例
+		{Date: s2date("2022-04-26"), City: "北京市", From: "2022-04-26.确诊病例5", FromType: model.ChainCase, To: "2022-04-26.确诊病例6"},
+	}, es)
+
+	file := filepath.Join(t.TempDir(), "chains.graphml")
+	assert.NoError(t, es.SaveToGraphML(file))
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	s := string(b)
+	assert.Equal(t, 6, strings.Count(s, "<node "))
+	assert.Equal(t, 4, strings.Count(s, "<edge "))
+	assert.Contains(t, s, `<edge source="场所:朝阳区潘家园旧货市场" target="2022-04-27.确诊病例4">`)
+}
@@ -0,0 +1,251 @@
+package crawler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//	数字规范化
+//
+//	各解析器的正则都以 \d+ 提取数量，而通报中偶尔会出现其它写法：
+//		一例、两例、十二例		中文数字
+//		１２例					全角数字
+//		1,234例					千位分隔符
+//		无新增境外输入性确诊病例	即 0 例
+//	NormalizeNumbers 在解析前把这些写法统一为阿拉伯数字，parseCount 用于解析提取到的数量。
+//	中文数字只在数量的上下文中改写，“第一人民医院”、“一人巷”、“同一人”这类地名和词语保持不变。
+
+var (
+	reNumberThousands = regexp.MustCompile(`(\d),(\d{3})(\D|$)`)
+	reNumberChinese   = regexp.MustCompile(`([零〇一二两三四五六七八九十百千万]+)(例|人|名|位)`)
+	reNumberNone      = regexp.MustCompile(`无新增((?:本土|境外输入性?)?(?:新冠肺炎)?(?:确诊病例|无症状感染者))([，。；\s]|$)`)
+)
+
+// 中文数字前面是这些词语时才视为数量，如“新增两例”、“治愈出院十二例”、“重型二十例”
+var chineseCountContexts = []string{
+	"新增", "累计", "报告", "确诊", "病例", "感染者", "出院", "死亡", "观察", "治疗",
+	"重型", "危重型", "其中", "含", "共", "和", "及", "、", "（", "(",
+}
+
+var chineseDigits = map[rune]int{
+	'零': 0, '〇': 0,
+	'一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+var chineseUnits = map[rune]int{
+	'十': 10, '百': 100, '千': 1000,
+}
+
+// 将中文数字、全角数字、千位分隔符和“无新增”统一为阿拉伯数字的写法
+func NormalizeNumbers(text string) string {
+	return normalizeNumbers(text).text
+}
+
+// 规范化后的文本
+//
+//	记录每个字节来自原文的哪一段，以便把在规范化文本中匹配到的片段还原为原文，
+//	如“新增0例境外输入性新冠肺炎确诊病例”还原为“无新增境外输入性新冠肺炎确诊病例”
+type normalizedText struct {
+	source string
+	text   string
+	starts []int // text 中每个字节在原文中对应片段的起点
+	ends   []int // text 中每个字节在原文中对应片段的终点
+}
+
+// 将原文中 [start, end) 替换为 repl
+type textEdit struct {
+	start, end int
+	repl       string
+}
+
+func newNormalizedText(source string) normalizedText {
+	t := normalizedText{source: source, text: source, starts: make([]int, len(source)), ends: make([]int, len(source))}
+	for i := 0; i < len(source); i++ {
+		t.starts[i], t.ends[i] = i, i+1
+	}
+	return t
+}
+
+// 按顺序应用不重叠的替换
+func (t *normalizedText) apply(edits []textEdit) {
+	if len(edits) == 0 {
+		return
+	}
+	var b strings.Builder
+	starts := make([]int, 0, len(t.starts))
+	ends := make([]int, 0, len(t.ends))
+	last := 0
+	for _, e := range edits {
+		b.WriteString(t.text[last:e.start])
+		starts = append(starts, t.starts[last:e.start]...)
+		ends = append(ends, t.ends[last:e.start]...)
+		b.WriteString(e.repl)
+		for range []byte(e.repl) {
+			starts = append(starts, t.starts[e.start])
+			ends = append(ends, t.ends[e.end-1])
+		}
+		last = e.end
+	}
+	b.WriteString(t.text[last:])
+	starts = append(starts, t.starts[last:]...)
+	ends = append(ends, t.ends[last:]...)
+	t.text, t.starts, t.ends = b.String(), starts, ends
+}
+
+// 规范化文本中的片段对应的原文，片段不在规范化文本中时返回 false
+func (t normalizedText) Original(snippet string) (string, bool) {
+	if len(snippet) == 0 {
+		return "", false
+	}
+	i := strings.Index(t.text, snippet)
+	if i < 0 {
+		return "", false
+	}
+	return t.source[t.starts[i]:t.ends[i+len(snippet)-1]], true
+}
+
+func normalizeNumbers(source string) normalizedText {
+	t := newNormalizedText(source)
+
+	//	全角数字
+	var edits []textEdit
+	for i, r := range t.text {
+		if r >= '０' && r <= '９' {
+			edits = append(edits, textEdit{i, i + utf8.RuneLen(r), string(r - '０' + '0')})
+		}
+	}
+	t.apply(edits)
+
+	//	千位分隔符，如 1,234,567 需要多次替换，只去掉逗号
+	for {
+		edits = edits[:0]
+		for _, m := range reNumberThousands.FindAllStringSubmatchIndex(t.text, -1) {
+			edits = append(edits, textEdit{m[3], m[3] + 1, ""})
+		}
+		if len(edits) == 0 {
+			break
+		}
+		t.apply(edits)
+	}
+
+	//	中文数字，只处理在数量上下文中、后面跟着量词的，以免误改“统一”、“万人空巷”、“第一人民医院”这类词语；只替换数字部分
+	edits = edits[:0]
+	for _, m := range reNumberChinese.FindAllStringSubmatchIndex(t.text, -1) {
+		numeral, unit := t.text[m[2]:m[3]], t.text[m[4]:m[5]]
+		if !isChineseCountContext(t.text[:m[2]]) {
+			continue
+		}
+		if unit == "例" && strings.HasPrefix(t.text[m[1]:], "行") {
+			//	“例行检测”
+			continue
+		}
+		n, err := parseChineseNumber(numeral)
+		if err != nil {
+			continue
+		}
+		edits = append(edits, textEdit{m[2], m[3], strconv.Itoa(n)})
+	}
+	t.apply(edits)
+
+	//	“无新增境外输入性新冠肺炎确诊病例”，只处理单独一项的情况，
+	//	“无新增本土确诊病例、疑似病例和无症状感染者”这种列表保持不变；只替换“无新增”
+	edits = edits[:0]
+	for _, m := range reNumberNone.FindAllStringSubmatchIndex(t.text, -1) {
+		edits = append(edits, textEdit{m[0], m[2], "新增0例"})
+	}
+	t.apply(edits)
+
+	return t
+}
+
+// 中文数字前面的文本是否表明这是一个数量
+func isChineseCountContext(prefix string) bool {
+	prefix = strings.TrimRight(prefix, " \t")
+	for _, w := range chineseCountContexts {
+		if strings.HasSuffix(prefix, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// 解析中文数字，如 十二、二十、一百零五、一万二千
+//
+//	必须以数字或“十”开头，单独的“百”、“千”、“万”不视为数字
+func parseChineseNumber(s string) (int, error) {
+	rs := []rune(s)
+	if len(rs) == 0 {
+		return 0, fmt.Errorf("空的中文数字")
+	}
+	if _, ok := chineseDigits[rs[0]]; !ok && rs[0] != '十' {
+		return 0, fmt.Errorf("无法解析中文数字：%q", s)
+	}
+
+	total, section, digit := 0, 0, 0
+	has_digit := false
+	for i, r := range rs {
+		if d, ok := chineseDigits[r]; ok {
+			if has_digit && digit != 0 {
+				//	“一二”这样连续的数字
+				return 0, fmt.Errorf("无法解析中文数字：%q", s)
+			}
+			digit = d
+			has_digit = true
+			continue
+		}
+		if u, ok := chineseUnits[r]; ok {
+			if !has_digit {
+				if i != 0 || r != '十' {
+					return 0, fmt.Errorf("无法解析中文数字：%q", s)
+				}
+				//	“十二”省略了“一”
+				digit = 1
+			}
+			section += digit * u
+			digit, has_digit = 0, false
+			continue
+		}
+		if r == '万' {
+			total += (section + digit) * 10000
+			section, digit, has_digit = 0, 0, false
+			continue
+		}
+		return 0, fmt.Errorf("无法解析中文数字：%q", s)
+	}
+	return total + section + digit, nil
+}
+
+// 解析提取到的数量，可以是阿拉伯数字、全角数字、中文数字，或“无”
+func parseCount(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "无", "无新增":
+		return 0, nil
+	}
+	s = NormalizeNumbers(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+	return parseChineseNumber(s)
+}
+
+// 解析带有“万”、“亿”单位的数量，如 2.5万 => 25000
+func parseAmount(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	unit := 1.0
+	switch {
+	case strings.HasSuffix(s, "亿"):
+		unit, s = 1e8, strings.TrimSuffix(s, "亿")
+	case strings.HasSuffix(s, "万"):
+		unit, s = 1e4, strings.TrimSuffix(s, "万")
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析数量：%q", s)
+	}
+	return int(f*unit + 0.5), nil
+}
@@ -0,0 +1,214 @@
+package crawler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 读取 testdata 中保存的通报页面
+func readSavedPage(t *testing.T, page string) string {
+	body, err := os.ReadFile(filepath.Join("testdata", page))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return string(body)
+}
+
+func TestNormalizeNumbers(t *testing.T) {
+	//	摘自 testdata 中保存的上海、北京通报原文
+	testcases := []struct {
+		page     string
+		text     string
+		expected string
+	}{
+		{"shanghai/2022-05-11.html", "2022年5月11日0—24时，无新增境外输入性新冠肺炎确诊病例。治愈出院1例，来自加拿大。", "2022年5月11日0—24时，新增0例境外输入性新冠肺炎确诊病例。治愈出院1例，来自加拿大。"},
+		{"shanghai/2022-04-24.html", "无新增境外输入性新冠肺炎确诊病例 新增境外输入性无症状感染者1例", "新增0例境外输入性新冠肺炎确诊病例 新增境外输入性无症状感染者1例"},
+		//	不应改动的写法
+		{"shanghai/2022-04-24.html", "您可关注所在区的官方微信，第一时间了解本区阳性感染者的居住信息", "您可关注所在区的官方微信，第一时间了解本区阳性感染者的居住信息"},
+		{"beijing/2022-04-16.html", "北京4月16日无新增本土确诊病例和无症状感染者 新增3例境外输入无症状感染者 治愈出院4例", "北京4月16日无新增本土确诊病例和无症状感染者 新增3例境外输入无症状感染者 治愈出院4例"},
+		{"beijing/2022-05-09.html", "无新增疑似病例；新增1例境外输入确诊病例，无新增疑似病例和无症状感染者。", "无新增疑似病例；新增1例境外输入确诊病例，无新增疑似病例和无症状感染者。"},
+	}
+	for i, c := range testcases {
+		assert.Containsf(t, readSavedPage(t, c.page), c.text, "(%d) 原文不在 %s 中", i, c.page)
+		assert.Equalf(t, c.expected, NormalizeNumbers(c.text), "(%d) %q", i, c.text)
+	}
+}
+
+func TestNormalizeNumbers_Variants(t *testing.T) {
+	//	把保存的通报原文改写为中文数字、全角数字和千位分隔符，规范化后应与原文一致
+	testcases := []struct {
+		page     string
+		written  string
+		original string
+	}{
+		{
+			"shanghai/2022-05-11.html",
+			"新增本土新冠肺炎确诊病例一百四十四例和无症状感染者１３０５例",
+			"新增本土新冠肺炎确诊病例144例和无症状感染者1305例",
+		},
+		{
+			"shanghai/2022-05-11.html",
+			"累计本土确诊56,527例，治愈出院50,629例，在院治疗5,333例（其中重型三百四十九例，危重型六十一例），死亡565例。",
+			"累计本土确诊56527例，治愈出院50629例，在院治疗5333例（其中重型349例，危重型61例），死亡565例。",
+		},
+		{
+			"shanghai/2022-04-24.html",
+			"80岁以上高龄老人共三十七位",
+			"80岁以上高龄老人共37位",
+		},
+		{
+			"beijing/2022-05-09.html",
+			"新增六十一例本土确诊病例(含一例5月7日、两例5月8日诊断的无症状感染者转确诊病例)和十三例无症状感染者",
+			"新增61例本土确诊病例(含1例5月7日、2例5月8日诊断的无症状感染者转确诊病例)和13例无症状感染者",
+		},
+		{
+			"beijing/2022-04-16.html",
+			"新增３例境外输入无症状感染者 治愈出院四例",
+			"新增3例境外输入无症状感染者 治愈出院4例",
+		},
+	}
+	for i, c := range testcases {
+		assert.Containsf(t, readSavedPage(t, c.page), c.original, "(%d) 原文不在 %s 中", i, c.page)
+		assert.Equalf(t, c.original, NormalizeNumbers(c.written), "(%d) %q", i, c.written)
+	}
+}
+
+func TestNormalizeNumbers_Addresses(t *testing.T) {
+	//	地名、场所和普通词语中的中文数字不是数量，应原样保留
+	testcases := []string{
+		//	北京通报原文，见 TestParseDailyContentBeijing、TestDailyParserBeijing_ParseResidents
+		"两人4月25日至5月3日期间5次报告核酸检测结果均为阴性",
+		"四人4月26日至5月4日多次报告核酸检测结果均为阴性",
+		"一是朝阳区涉及疫情，累计报告675例；二是丰台中西医结合医院涉及疫情",
+		"确诊病例2：现住朝阳区松榆东里。",
+		//	地址和场所
+		"上海市第一人民医院",
+		"居住于黄浦区一人巷",
+		"黄浦区：一人巷、四川中路",
+		"为同一人",
+		"其间例行核酸检测异常，统一例行筛查",
+		"数万人参与核酸筛查",
+		"病例1、9：为同一家庭成员",
+		"无新增本土确诊病例、疑似病例和无症状感染者",
+	}
+	for _, text := range testcases {
+		assert.Equalf(t, text, NormalizeNumbers(text), "%q", text)
+	}
+}
+
+func TestNormalizeNumbers_Original(t *testing.T) {
+	//	上海 2022-05-11 通报原文的改写
+	source := "无新增境外输入性新冠肺炎确诊病例。新增本土无症状感染者１３０５例，治愈出院四百三十二例，累计本土确诊56,527例。"
+	nt := normalizeNumbers(source)
+	assert.Equal(t, NormalizeNumbers(source), nt.text)
+
+	testcases := map[string]string{
+		"新增0例境外输入性新冠肺炎确诊病例": "无新增境外输入性新冠肺炎确诊病例",
+		"新增本土无症状感染者1305例":   "新增本土无症状感染者１３０５例",
+		"治愈出院432例":          "治愈出院四百三十二例",
+		"累计本土确诊56527例":      "累计本土确诊56,527例",
+		"1305":              "１３０５",
+	}
+	for snippet, expected := range testcases {
+		original, ok := nt.Original(snippet)
+		if assert.Truef(t, ok, "%q", snippet) {
+			assert.Equalf(t, expected, original, "%q", snippet)
+		}
+	}
+
+	_, ok := nt.Original("新增1例")
+	assert.False(t, ok)
+}
+
+func TestParseCount(t *testing.T) {
+	testcases := map[string]int{
+		"144":   144,
+		"１４４":   144,
+		"1,305": 1305,
+		"十":     10,
+		"十二":    12,
+		"两":     2,
+		"一百零五":  105,
+		"无":     0,
+		"零":     0,
+	}
+	for s, expected := range testcases {
+		n, err := parseCount(s)
+		assert.NoErrorf(t, err, "%q", s)
+		assert.Equalf(t, expected, n, "%q", s)
+	}
+	for _, s := range []string{"", "万", "百余", "一二", "abc"} {
+		_, err := parseCount(s)
+		assert.Errorf(t, err, "%q", s)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	testcases := map[string]int{
+		"2500":   2500,
+		"2.5万":   25000,
+		"2500万":  25000000,
+		"1.2亿":   120000000,
+		"0.35万 ": 3500,
+	}
+	for s, expected := range testcases {
+		n, err := parseAmount(s)
+		assert.NoErrorf(t, err, "%q", s)
+		assert.Equalf(t, expected, n, "%q", s)
+	}
+	for _, s := range []string{"", "万", "abc"} {
+		_, err := parseAmount(s)
+		assert.Errorf(t, err, "%q", s)
+	}
+}
+
+func TestParseItem_Numbers(t *testing.T) {
+	//	保存的通报页面中的数量改写为其它写法后，解析结果应与原页面相同
+	testcases := []struct {
+		city     string
+		page     string
+		replacer *strings.Replacer
+	}{
+		{
+			"shanghai",
+			"shanghai/2022-05-11.html",
+			strings.NewReplacer(
+				"新增本土新冠肺炎确诊病例144例", "新增本土新冠肺炎确诊病例一百四十四例",
+				"无症状感染者1305例", "无症状感染者１３０５例",
+				"累计本土确诊56527例", "累计本土确诊56,527例",
+				"治愈出院50629例", "治愈出院50,629例",
+				"其中重型349例，危重型61例", "其中重型三百四十九例，危重型六十一例",
+			),
+		},
+		{
+			"beijing",
+			"beijing/2022-05-09.html",
+			strings.NewReplacer(
+				"新增61例本土确诊病例", "新增六十一例本土确诊病例",
+				"含1例5月7日、2例5月8日", "含一例5月7日、两例5月8日",
+				"和13例无症状感染者", "和十三例无症状感染者",
+				"治愈出院26例", "治愈出院二十六例",
+			),
+		},
+	}
+	for _, c := range testcases {
+		body := readSavedPage(t, c.page)
+		written := c.replacer.Replace(body)
+		assert.NotEqualf(t, body, written, "%s 中没有可以改写的数量", c.page)
+
+		link := goldenLink(filepath.Join("testdata", c.page))
+		expected := parseGoldenPage(t, c.city, link, []byte(body))
+		actual := parseGoldenPage(t, c.city, link, []byte(written))
+		if assert.Lenf(t, actual.Dailys, len(expected.Dailys), "%s", c.page) {
+			for i := range expected.Dailys {
+				//	来源记录的是各自的原文，不必比较
+				expected.Dailys[i].Provenance, actual.Dailys[i].Provenance = nil, nil
+			}
+			assert.Equalf(t, expected.Dailys, actual.Dailys, "%s", c.page)
+		}
+	}
+}
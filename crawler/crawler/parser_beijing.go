@@ -13,6 +13,10 @@ import (
 
 type DailyParserBeijing struct{}
 
+func init() {
+	RegisterCity(City{Key: "beijing", Name: "北京市", Parser: DailyParserBeijing{}})
+}
+
 func (p DailyParserBeijing) GetSelector(t string) string {
 	selectors := map[string]string{
 		"index":   ".listLk a",
@@ -65,6 +69,54 @@ func (p DailyParserBeijing) GetDistricts() []string {
 	}
 }
 
+// 提取数量的正则，见 CoverageParser
+func (p DailyParserBeijing) GetExtractors() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		reDailyCommon,
+		reDailyCritical,
+		reDailyDischargedFromHospital,
+		reDailyDischargedFromMedicalObservation,
+		reDailyDischargedFromMedicalObservation2,
+		reDailyImportedAsymptomatic,
+		reDailyImportedConfirmed,
+		reDailyImportedDeath,
+		reDailyImportedDischargedFromHospital,
+		reDailyImportedDischargedFromMedicalObservation,
+		reDailyImportedInHospital,
+		reDailyImportedUnderMedicalObservation,
+		reDailyLocalAsymptomatic,
+		reDailyLocalAsymptomaticFromBubble,
+		reDailyLocalConfirmed,
+		reDailyLocalConfirmedFromAsymptomatic,
+		reDailyLocalConfirmedFromAsymptomaticBeijing,
+		reDailyLocalConfirmedFromBubble,
+		reDailyLocalDeath,
+		reDailyLocalDischargedFromHospital,
+		reDailyLocalDischargedFromMedicalObservation,
+		reDailyLocalInHospital,
+		reDailyLocalPositive,
+		reDailyLocalPositiveFromBubble,
+		reDailyLocalPositiveFromRisk,
+		reDailyLocalUnderMedicalObservation,
+		reDailyMild,
+		reDailyRegionAsymptomaticFromBubble,
+		reDailyRegionAsymptomaticFromRisk,
+		reDailyRegionConfirmedFromAsymptomatic,
+		reDailyRegionConfirmedFromBubble,
+		reDailyRegionConfirmedFromRisk,
+		reDailyRegionListBeijing,
+		reDailySevere,
+		reDailyTotalImportedConfirmed,
+		reDailyTotalImportedDischargedFromHospital,
+		reDailyTotalLocalConfirmed,
+		reDailyTotalLocalDeath,
+		reDailyTotalLocalDischargedFromHospital,
+		reDailyUnderMedicalObservation,
+		reResidentDistrictBeijing1,
+		reResidentDistrictBeijingSection,
+	}
+}
+
 func (p DailyParserBeijing) IsDaily(date time.Time, title string) bool {
 	return p.IsValidTitle(title)
 }
@@ -78,6 +130,15 @@ func (p DailyParserBeijing) IsValidTitle(title string) bool {
 	return strings.Contains(title, "日新增") || strings.Contains(title, "日无新增")
 }
 
+// 风险地区调整通告，见 RiskAreaParser
+func (p DailyParserBeijing) IsRiskAreas(title string) bool {
+	return strings.Contains(title, "风险地区") || strings.Contains(title, "风险区")
+}
+
+func (p DailyParserBeijing) ParseRiskAreas(ns *model.RiskAreaNotices, date time.Time, content string) error {
+	return parseRiskAreas(ns, "北京市", p.GetDistricts(), date, content)
+}
+
 //	解析 Daily
 
 //	解析 Daily 标题
@@ -117,7 +178,8 @@ func (p DailyParserBeijing) ParseDailyTitle(d *model.Daily, title string) error
 	} else {
 		///	2种情况
 		n := m[1] + m[2]
-		d.LocalConfirmed, err = strconv.Atoi(n)
+		d.Parsed("LocalConfirmed", "reDailyLocalConfirmed", m[0])
+		d.LocalConfirmed, err = parseCount(n)
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章标题中本土新增：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -130,7 +192,8 @@ func (p DailyParserBeijing) ParseDailyTitle(d *model.Daily, title string) error
 	} else {
 		// 有3种情况
 		n := m[1] + m[2] + m[3]
-		d.LocalAsymptomatic, err = strconv.Atoi(n)
+		d.Parsed("LocalAsymptomatic", "reDailyLocalAsymptomatic", m[0])
+		d.LocalAsymptomatic, err = parseCount(n)
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章标题中本土无症状感染者：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -143,7 +206,8 @@ func (p DailyParserBeijing) ParseDailyTitle(d *model.Daily, title string) error
 	} else {
 		//	数值有两个case
 		n := m[1] + m[2]
-		d.ImportedConfirmed, err = strconv.Atoi(n)
+		d.Parsed("ImportedConfirmed", "reDailyImportedConfirmed", m[0])
+		d.ImportedConfirmed, err = parseCount(n)
 		if err != nil {
 			log.Warnf("[%s] 无法解析文章标题中境外输入新增：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -156,7 +220,8 @@ func (p DailyParserBeijing) ParseDailyTitle(d *model.Daily, title string) error
 	} else {
 		/// 正则包含4个可能性
 		n := m[1] + m[2] + m[3] + m[4]
-		d.ImportedAsymptomatic, err = strconv.Atoi(n)
+		d.Parsed("ImportedAsymptomatic", "reDailyImportedAsymptomatic", m[0])
+		d.ImportedAsymptomatic, err = parseCount(n)
 		if err != nil {
 			log.Warnf("[%s] 无法解析文章标题中境外输入无症状感染者：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -167,7 +232,8 @@ func (p DailyParserBeijing) ParseDailyTitle(d *model.Daily, title string) error
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章标题中治愈出院：%q", d.Date.Format("2006-01-02"), title)
 	} else {
-		d.DischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("DischargedFromHospital", "reDailyDischargedFromHospital", m[0])
+		d.DischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			log.Warnf("[%s] 无法解析文章标题中治愈出院：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -178,7 +244,8 @@ func (p DailyParserBeijing) ParseDailyTitle(d *model.Daily, title string) error
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章标题中解除医学观察：%q", d.Date.Format("2006-01-02"), title)
 	} else {
-		d.DischargedFromMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("DischargedFromMedicalObservation", "reDailyDischargedFromMedicalObservation", m[0])
+		d.DischargedFromMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			log.Warnf("[%s] 无法解析文章标题中解除医学观察：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -187,6 +254,12 @@ func (p DailyParserBeijing) ParseDailyTitle(d *model.Daily, title string) error
 	return nil
 }
 
+// “新增61例本土确诊病例(含1例5月7日、2例5月8日诊断的无症状感染者转确诊病例)”
+var (
+	reDailyLocalConfirmedFromAsymptomaticBeijing = regexp.MustCompile(`(?:含|其中)(?P<list>(?:\d+例\d+月\d+日[、和]?)+)诊断的无症状感染者转(?:为)?确诊病例`)
+	reDailyCountBeijing                          = regexp.MustCompile(`(\d+)例`)
+)
+
 // 解析 Daily 内容
 func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) error {
 	if d == nil {
@@ -228,7 +301,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 			//	可能没有阳性数据
 			// fmt.Printf("[%s] 无法解析文章内容中本土阳性感染者：%q", d.Date.Format("2006-01-02"), content)
 		} else {
-			d.LocalPositive, err = strconv.Atoi(m[1])
+			d.Parsed("LocalPositive", "reDailyLocalPositive", m[0])
+			d.LocalPositive, err = parseCount(m[1])
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中本土阳性感染者：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -240,7 +314,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 		if m == nil {
 			fmt.Printf("[%s] 无法解析文章内容中本土轻型：%q", d.Date.Format("2006-01-02"), content)
 		} else {
-			d.Mild, err = strconv.Atoi(m[1])
+			d.Parsed("Mild", "reDailyMild", m[0])
+			d.Mild, err = parseCount(m[1])
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中本土轻型：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -252,7 +327,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 		if m == nil {
 			fmt.Printf("[%s] 无法解析文章内容中本土普通型：%q", d.Date.Format("2006-01-02"), content)
 		} else {
-			d.Common, err = strconv.Atoi(m[1])
+			d.Parsed("Common", "reDailyCommon", m[0])
+			d.Common, err = parseCount(m[1])
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中本土普通型：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -267,7 +343,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 		} else {
 			///	2种情况
 			n := m[1] + m[2]
-			d.LocalConfirmed, err = strconv.Atoi(n)
+			d.Parsed("LocalConfirmed", "reDailyLocalConfirmed", m[0])
+			d.LocalConfirmed, err = parseCount(n)
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中本土新增：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -282,7 +359,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 		} else {
 			// 有3种情况
 			n := m[1] + m[2] + m[3]
-			d.LocalAsymptomatic, err = strconv.Atoi(n)
+			d.Parsed("LocalAsymptomatic", "reDailyLocalAsymptomatic", m[0])
+			d.LocalAsymptomatic, err = parseCount(n)
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中本土无症状：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -297,7 +375,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 		} else {
 			//	数值有两个case
 			n := m[1] + m[2]
-			d.ImportedConfirmed, err = strconv.Atoi(n)
+			d.Parsed("ImportedConfirmed", "reDailyImportedConfirmed", m[0])
+			d.ImportedConfirmed, err = parseCount(n)
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中境外输入确诊：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -310,7 +389,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 		if m == nil {
 			// log.Warnf("[%s] 无法解析文章内容中境外输入无症状：%q", d.Date.Format("2006-01-02"), content)
 		} else {
-			d.ImportedAsymptomatic, err = strconv.Atoi(m[1])
+			d.Parsed("ImportedAsymptomatic", "reDailyImportedAsymptomatic", m[0])
+			d.ImportedAsymptomatic, err = parseCount(m[1])
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中境外输入无症状：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -322,7 +402,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土隔离管控中发现的阳性感染者：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalPositiveFromBubble, err = strconv.Atoi(m[1])
+		d.Parsed("LocalPositiveFromBubble", "reDailyLocalPositiveFromBubble", m[0])
+		d.LocalPositiveFromBubble, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土隔离管控中发现的阳性感染者：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -333,7 +414,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土风险人群中发现的阳性感染者：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalPositiveFromRisk, err = strconv.Atoi(m[1])
+		d.Parsed("LocalPositiveFromRisk", "reDailyLocalPositiveFromRisk", m[0])
+		d.LocalPositiveFromRisk, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土风险人群中发现的阳性感染者：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -343,8 +425,22 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	m = reDailyLocalConfirmedFromAsymptomatic.FindStringSubmatch(content)
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土无症状转为确诊病例：%q", d.Date.Format("2006-01-02"), content)
+		//	“含1例5月7日、2例5月8日诊断的无症状感染者转确诊病例”，按诊断日期分列
+		if m = reDailyLocalConfirmedFromAsymptomaticBeijing.FindStringSubmatch(content); m != nil {
+			n := 0
+			for _, it := range reDailyCountBeijing.FindAllStringSubmatch(m[1], -1) {
+				v, err := parseCount(it[1])
+				if err != nil {
+					return fmt.Errorf("[%s] 无法解析文章内容中本土无症状转为确诊病例：%q", d.Date.Format("2006-01-02"), m[0])
+				}
+				n += v
+			}
+			d.Parsed("LocalConfirmedFromAsymptomatic", "reDailyLocalConfirmedFromAsymptomaticBeijing", m[0])
+			d.LocalConfirmedFromAsymptomatic = n
+		}
 	} else {
-		d.LocalConfirmedFromAsymptomatic, err = strconv.Atoi(m[1])
+		d.Parsed("LocalConfirmedFromAsymptomatic", "reDailyLocalConfirmedFromAsymptomatic", m[0])
+		d.LocalConfirmedFromAsymptomatic, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土无症状转为确诊病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -355,7 +451,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土隔离管控中发现的确诊病例：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalConfirmedFromBubble, err = strconv.Atoi(m[1])
+		d.Parsed("LocalConfirmedFromBubble", "reDailyLocalConfirmedFromBubble", m[0])
+		d.LocalConfirmedFromBubble, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土隔离管控中发现的确诊病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -366,7 +463,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土隔离管控中发现的无症状病例：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalAsymptomaticFromBubble, err = strconv.Atoi(m[1])
+		d.Parsed("LocalAsymptomaticFromBubble", "reDailyLocalAsymptomaticFromBubble", m[0])
+		d.LocalAsymptomaticFromBubble, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土隔离管控中发现的无症状病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -378,7 +476,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 		if m == nil {
 			// log.Warnf("[%s] 无法解析文章标题中治愈出院：%q", d.Date.Format("2006-01-02"), content)
 		} else {
-			d.DischargedFromHospital, err = strconv.Atoi(m[1])
+			d.Parsed("DischargedFromHospital", "reDailyDischargedFromHospital", m[0])
+			d.DischargedFromHospital, err = parseCount(m[1])
 			if err != nil {
 				log.Warnf("[%s] 无法解析文章内容中治愈出院：%q", d.Date.Format("2006-01-02"), content)
 			}
@@ -390,7 +489,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土治愈出院：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalDischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("LocalDischargedFromHospital", "reDailyLocalDischargedFromHospital", m[0])
+		d.LocalDischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土治愈出院：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -401,7 +501,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入治愈出院：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.ImportedDischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("ImportedDischargedFromHospital", "reDailyImportedDischargedFromHospital", m[0])
+		d.ImportedDischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入治愈出院：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -413,7 +514,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 		if m == nil {
 			// log.Warnf("[%s] 无法解析文章内容中解除医学观察：%q", d.Date.Format("2006-01-02"), content)
 		} else {
-			d.DischargedFromMedicalObservation, err = strconv.Atoi(m[1])
+			d.Parsed("DischargedFromMedicalObservation", "reDailyDischargedFromMedicalObservation2", m[0])
+			d.DischargedFromMedicalObservation, err = parseCount(m[1])
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中解除医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -425,7 +527,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土解除医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalDischargedFromMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("LocalDischargedFromMedicalObservation", "reDailyLocalDischargedFromMedicalObservation", m[0])
+		d.LocalDischargedFromMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土解除医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -436,7 +539,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入解除医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.ImportedDischargedFromMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("ImportedDischargedFromMedicalObservation", "reDailyImportedDischargedFromMedicalObservation", m[0])
+		d.ImportedDischargedFromMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入解除医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -447,7 +551,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土死亡病例：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalDeath, err = strconv.Atoi(m[1])
+		d.Parsed("LocalDeath", "reDailyLocalDeath", m[0])
+		d.LocalDeath, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土死亡病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -458,7 +563,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入死亡病例：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.ImportedDeath, err = strconv.Atoi(m[1])
+		d.Parsed("ImportedDeath", "reDailyImportedDeath", m[0])
+		d.ImportedDeath, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入死亡病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -469,7 +575,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计本土确诊：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalLocalConfirmed, err = strconv.Atoi(m[1])
+		d.Parsed("TotalLocalConfirmed", "reDailyTotalLocalConfirmed", m[0])
+		d.TotalLocalConfirmed, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计本土确诊：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -480,7 +587,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计本土治愈出院：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalLocalDischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("TotalLocalDischargedFromHospital", "reDailyTotalLocalDischargedFromHospital", m[0])
+		d.TotalLocalDischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计本土治愈出院：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -491,7 +599,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土在院治疗：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.CurrentLocalInHospital, err = strconv.Atoi(m[1])
+		d.Parsed("CurrentLocalInHospital", "reDailyLocalInHospital", m[0])
+		d.CurrentLocalInHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土在院治疗：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -502,7 +611,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计境外输入确诊：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalImportedConfirmed, err = strconv.Atoi(m[1])
+		d.Parsed("TotalImportedConfirmed", "reDailyTotalImportedConfirmed", m[0])
+		d.TotalImportedConfirmed, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计境外输入确诊：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -513,7 +623,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计境外输入治愈出院：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalImportedDischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("TotalImportedDischargedFromHospital", "reDailyTotalImportedDischargedFromHospital", m[0])
+		d.TotalImportedDischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计境外输入治愈出院：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -524,7 +635,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入在院治疗：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.CurrentImportedInHospital, err = strconv.Atoi(m[1])
+		d.Parsed("CurrentImportedInHospital", "reDailyImportedInHospital", m[0])
+		d.CurrentImportedInHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入在院治疗：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -535,7 +647,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计本土死亡：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalLocalDeath, err = strconv.Atoi(m[1])
+		d.Parsed("TotalLocalDeath", "reDailyTotalLocalDeath", m[0])
+		d.TotalLocalDeath, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计本土死亡：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -546,7 +659,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中重型：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.CurrentSevere, err = strconv.Atoi(m[1])
+		d.Parsed("CurrentSevere", "reDailySevere", m[0])
+		d.CurrentSevere, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中重型：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -557,7 +671,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中危重型：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.CurrentCritical, err = strconv.Atoi(m[1])
+		d.Parsed("CurrentCritical", "reDailyCritical", m[0])
+		d.CurrentCritical, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中危重型：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -568,7 +683,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中尚在医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.UnderMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("UnderMedicalObservation", "reDailyUnderMedicalObservation", m[0])
+		d.UnderMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中尚在医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -579,7 +695,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土尚在医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalUnderMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("LocalUnderMedicalObservation", "reDailyLocalUnderMedicalObservation", m[0])
+		d.LocalUnderMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土尚在医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -590,7 +707,8 @@ func (p DailyParserBeijing) ParseDailyContent(d *model.Daily, content string) er
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入尚在医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.ImportedUnderMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("ImportedUnderMedicalObservation", "reDailyImportedUnderMedicalObservation", m[0])
+		d.ImportedUnderMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入尚在医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -618,6 +736,7 @@ func (p DailyParserBeijing) parseDailyContentRegion(d *model.Daily, content stri
 		}
 	} else {
 		d.DistrictConfirmedFromBubble = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictConfirmedFromBubble", "reDailyRegionConfirmedFromBubble", mm[0][0])
 		// log.Warnf("[%s] 城区确诊病例(来自隔离管控): %#v", cs.Date.Format("2006-01-02"), cs.DistrictConfirmedFromBubble)
 	}
 	//	风险人群 => 确诊病例
@@ -628,6 +747,7 @@ func (p DailyParserBeijing) parseDailyContentRegion(d *model.Daily, content stri
 		// }
 	} else {
 		d.DistrictConfirmedFromRisk = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictConfirmedFromRisk", "reDailyRegionConfirmedFromRisk", mm[0][0])
 		// log.Warnf("[%s] 城区确诊病例(来自风险人群): %#v", cs.Date.Format("2006-01-02"), cs.DistrictConfirmedFromRisk)
 	}
 	//	无症状 => 确诊病例
@@ -638,6 +758,7 @@ func (p DailyParserBeijing) parseDailyContentRegion(d *model.Daily, content stri
 		// }
 	} else {
 		d.DistrictConfirmedFromAsymptomatic = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictConfirmedFromAsymptomatic", "reDailyRegionConfirmedFromAsymptomatic", mm[0][0])
 		// log.Warnf("[%s] 城区确诊病例(来自无症状感染者): %#v", cs.Date.Format("2006-01-02"), cs.DistrictConfirmedFromAsymptomatic)
 	}
 	//	隔离管控 => 无症状
@@ -648,6 +769,7 @@ func (p DailyParserBeijing) parseDailyContentRegion(d *model.Daily, content stri
 		// }
 	} else {
 		d.DistrictAsymptomaticFromBubble = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictAsymptomaticFromBubble", "reDailyRegionAsymptomaticFromBubble", mm[0][0])
 		// log.Warnf("[%s] 城区无症状感染者(来自隔离管控): %#v", cs.Date.Format("2006-01-02"), cs.DistrictAsymptomaticFromBubble)
 	}
 	//	风险人群 => 无症状
@@ -658,6 +780,7 @@ func (p DailyParserBeijing) parseDailyContentRegion(d *model.Daily, content stri
 		// }
 	} else {
 		d.DistrictAsymptomaticFromRisk = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictAsymptomaticFromRisk", "reDailyRegionAsymptomaticFromRisk", mm[0][0])
 		// log.Warnf("[%s] 城区无症状感染者(来自风险人群): %#v", cs.Date.Format("2006-01-02"), cs.DistrictAsymptomaticFromRisk)
 	}
 	//	区域阳性感染者
@@ -668,6 +791,7 @@ func (p DailyParserBeijing) parseDailyContentRegion(d *model.Daily, content stri
 		// }
 	} else {
 		d.DistrictPositive = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictPositive", "reDailyRegionListBeijing", mm[0][0])
 		// log.Warnf("[%s] 城区阳性感染者(来自风险人群): %#v", cs.Date.Format("2006-01-02"), cs.DistrictPositive)
 	}
 
@@ -700,7 +824,7 @@ func (p DailyParserBeijing) parseDailyContentRegionItem(d *model.Daily, text str
 			var err error
 
 			if len(it[2]) > 0 {
-				v, err = strconv.Atoi(it[2])
+				v, err = parseCount(it[2])
 				if err != nil {
 					log.Warnf("[%s] 无法解析区域病例列表：%q", d.Date.Format("2006-01-02"), it)
 					continue
@@ -808,8 +932,11 @@ func (p DailyParserBeijing) ParseResidents(rs *model.Residents, date time.Time,
 					Type:     ts[i],
 					City:     "北京市",
 					District: strings.TrimSpace(m2[2]),
+					Street:   parseStreet(m2[3]),
 					Address:  strings.TrimSpace(m2[3]),
 				}
+				//	与之前病例、聚集性疫情、场所的关联
+				parseLinkage(&r, d, m1[0])
 				// log.Tracef("[%s] %v", date.Format("2006-01-02"), r)
 				*rs = append(*rs, r)
 			}
@@ -13,6 +13,12 @@ func s2date(s string) time.Time {
 	return t
 }
 
+// 城市时区中的日期，即爬虫输出数据中的日期
+func s2localdate(s string) time.Time {
+	t, _ := time.ParseInLocation("2006-01-02", s, TIMEZONE_CHINA)
+	return t
+}
+
 func TestDailyParserBeijing_ParseResidents(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -30,53 +36,53 @@ func TestDailyParserBeijing_ParseResidents(t *testing.T) {
 			name:    "单一病例 - 日期多了空格",
 			content: "确诊病例18：现住通州区永顺镇馨通家园。综合流行病史、临床表现、实验室检测和影像学检查等结果，5 月1日诊断为确诊病例，临床分型为轻型。",
 			rs: model.Residents{
-				{Date: s2date("2022-05-01"), Name: "确诊病例18", Type: "轻型", City: "北京市", District: "通州区", Address: "永顺镇馨通家园"},
+				{Date: s2date("2022-05-01"), Name: "确诊病例18", Type: "轻型", City: "北京市", District: "通州区", Street: "永顺镇", Address: "永顺镇馨通家园"},
 			},
 		},
 		{
 			name:    "单一病例 - 地址以‘区’为结尾",
 			content: "确诊病例42 ：现住通州区于家务乡于家务西里小区。综合流行病史、临床表现、实验室检测和影像学检查等结果，4月30日诊断为确诊病例，临床分型为轻型。",
 			rs: model.Residents{
-				{Date: s2date("2022-04-30"), Name: "确诊病例42", Type: "轻型", City: "北京市", District: "通州区", Address: "于家务乡于家务西里小区"},
+				{Date: s2date("2022-04-30"), Name: "确诊病例42", Type: "轻型", City: "北京市", District: "通州区", Street: "于家务乡", Address: "于家务乡于家务西里小区"},
 			},
 		},
 		{
 			name:    "单一病例 - 地址以四字‘区’为结尾",
 			content: "确诊病例19：现住通州区永顺镇榆东一街金地格林北区。4月26日报告核酸检测结果为阳性，综合流行病史、临床表现、实验室检测和影像学检查等结果，4月27日诊断为确诊病例，临床分型为轻型。",
 			rs: model.Residents{
-				{Date: s2date("2022-04-27"), Name: "确诊病例19", Type: "轻型", City: "北京市", District: "通州区", Address: "永顺镇榆东一街金地格林北区"},
+				{Date: s2date("2022-04-27"), Name: "确诊病例19", Type: "轻型", City: "北京市", District: "通州区", Street: "永顺镇", Address: "永顺镇榆东一街金地格林北区"},
 			},
 		},
 		{
 			name:    "2个独立病例 - 同为无症状感染者",
 			content: "无症状感染者1、2：现住房山区窦店镇于庄村。4月23日作为密切接触者进行核酸检测，当日报告结果为阳性，已转至定点医院，综合流行病史、临床表现、实验室检测和影像学检查等结果，4月24日均诊断为无症状感染者。",
 			rs: model.Residents{
-				{Date: s2date("2022-04-24"), Name: "无症状感染者1", Type: "无症状感染者", City: "北京市", District: "房山区", Address: "窦店镇于庄村"},
-				{Date: s2date("2022-04-24"), Name: "无症状感染者2", Type: "无症状感染者", City: "北京市", District: "房山区", Address: "窦店镇于庄村"},
+				{Date: s2date("2022-04-24"), Name: "无症状感染者1", Type: "无症状感染者", City: "北京市", District: "房山区", Street: "窦店镇", Address: "窦店镇于庄村"},
+				{Date: s2date("2022-04-24"), Name: "无症状感染者2", Type: "无症状感染者", City: "北京市", District: "房山区", Street: "窦店镇", Address: "窦店镇于庄村"},
 			},
 		},
 		{
 			name:    "2个独立病例 - 相同分型",
 			content: "确诊病例1、9：为同一家庭成员，现住通州区北苑街道新仓路小区。综合流行病史、临床表现、实验室检测和影像学检查等结果，4月30日诊断为确诊病例，临床分型均为轻型。",
 			rs: []model.Resident{
-				{Date: s2date("2022-04-30"), Name: "确诊病例1", Type: "轻型", City: "北京市", District: "通州区", Address: "北苑街道新仓路小区"},
-				{Date: s2date("2022-04-30"), Name: "确诊病例9", Type: "轻型", City: "北京市", District: "通州区", Address: "北苑街道新仓路小区"},
+				{Date: s2date("2022-04-30"), Name: "确诊病例1", Type: "轻型", City: "北京市", District: "通州区", Street: "北苑街道", Address: "北苑街道新仓路小区"},
+				{Date: s2date("2022-04-30"), Name: "确诊病例9", Type: "轻型", City: "北京市", District: "通州区", Street: "北苑街道", Address: "北苑街道新仓路小区"},
 			},
 		},
 		{
 			name:    "2个独立病例 - 不同分型1",
 			content: "确诊病例49 、50：现住朝阳区双井街道广和南里二条。综合流行病史、临床表现、实验室检测和影像学检查等结果，4月30日诊断为确诊病例，确诊病例49临床分型为轻型，确诊病例50临床分型为普通型。",
 			rs: []model.Resident{
-				{Date: s2date("2022-04-30"), Name: "确诊病例49", Type: "轻型", City: "北京市", District: "朝阳区", Address: "双井街道广和南里二条"},
-				{Date: s2date("2022-04-30"), Name: "确诊病例50", Type: "普通型", City: "北京市", District: "朝阳区", Address: "双井街道广和南里二条"},
+				{Date: s2date("2022-04-30"), Name: "确诊病例49", Type: "轻型", City: "北京市", District: "朝阳区", Street: "双井街道", Address: "双井街道广和南里二条"},
+				{Date: s2date("2022-04-30"), Name: "确诊病例50", Type: "普通型", City: "北京市", District: "朝阳区", Street: "双井街道", Address: "双井街道广和南里二条"},
 			},
 		},
 		// {	//	暂时不解析 “感染者xxx” 格式的页面
 		// 	name:    "2个独立病例 - 不同分型2",
 		// 	content: "感染者386、389：现住昌平区沙河镇乐乎有朋公寓，公司职员。通过社区核酸筛查发现，4月29日感染者386诊断为确诊病例，临床分型为轻型，感染者389诊断为无症状感染者。",
 		// 	rs: []model.Resident{
-		// 		{Name: "确诊病例386", Type: "轻型", City: "北京市", District: "昌平区", Address: "沙河镇乐乎有朋公寓"},
-		// 		{Name: "确诊病例389", Type: "无症状感染者", City: "北京市", District: "昌平区", Address: "沙河镇乐乎有朋公寓"},
+		// 		{Name: "确诊病例386", Type: "轻型", City: "北京市", District: "昌平区", Street: "沙河镇", Address: "沙河镇乐乎有朋公寓"},
+		// 		{Name: "确诊病例389", Type: "无症状感染者", City: "北京市", District: "昌平区", Street: "沙河镇", Address: "沙河镇乐乎有朋公寓"},
 		// 	},
 		// },
 		{
@@ -109,29 +115,29 @@ func TestDailyParserBeijing_ParseResidents(t *testing.T) {
 			name:    "3个独立病例 - 不同分型2",
 			content: "确诊病例14、15、16：均为朝阳区护国寺小吃（光明桥店）员工。4月25日报告核酸检测结果均为阳性，已转至定点医院，综合流行病史、临床表现、实验室检测和影像学检查等结果，当日诊断为确诊病例，确诊病例14临床分型为普通型，确诊病例15、16临床分型均为轻型。",
 			rs: []model.Resident{
-				{Name: "确诊病例14", Type: "普通型", City: "北京市", District: "朝阳区", Address: "护国寺小吃（光明桥店）员工"},
-				{Name: "确诊病例15", Type: "轻型", City: "北京市", District: "朝阳区", Address: "护国寺小吃（光明桥店）员工"},
-				{Name: "确诊病例16", Type: "轻型", City: "北京市", District: "朝阳区", Address: "护国寺小吃（光明桥店）员工"},
+				{Name: "确诊病例14", Type: "普通型", City: "北京市", District: "朝阳区", Address: "护国寺小吃（光明桥店）员工", Venue: "朝阳区护国寺小吃（光明桥店）"},
+				{Name: "确诊病例15", Type: "轻型", City: "北京市", District: "朝阳区", Address: "护国寺小吃（光明桥店）员工", Venue: "朝阳区护国寺小吃（光明桥店）"},
+				{Name: "确诊病例16", Type: "轻型", City: "北京市", District: "朝阳区", Address: "护国寺小吃（光明桥店）员工", Venue: "朝阳区护国寺小吃（光明桥店）"},
 			},
 		},
 		{
 			name:    "3个独立病例 - 不同分型3",
 			content: "确诊病例40、41、42：现住丰台区王佐镇鑫湖家园。5月4日诊断均为确诊病例，临床分型均为轻型。",
 			rs: []model.Resident{
-				{Date: s2date("2022-05-04"), Name: "确诊病例40", Type: "轻型", City: "北京市", District: "丰台区", Address: "王佐镇鑫湖家园"},
-				{Date: s2date("2022-05-04"), Name: "确诊病例41", Type: "轻型", City: "北京市", District: "丰台区", Address: "王佐镇鑫湖家园"},
-				{Date: s2date("2022-05-04"), Name: "确诊病例42", Type: "轻型", City: "北京市", District: "丰台区", Address: "王佐镇鑫湖家园"},
+				{Date: s2date("2022-05-04"), Name: "确诊病例40", Type: "轻型", City: "北京市", District: "丰台区", Street: "王佐镇", Address: "王佐镇鑫湖家园"},
+				{Date: s2date("2022-05-04"), Name: "确诊病例41", Type: "轻型", City: "北京市", District: "丰台区", Street: "王佐镇", Address: "王佐镇鑫湖家园"},
+				{Date: s2date("2022-05-04"), Name: "确诊病例42", Type: "轻型", City: "北京市", District: "丰台区", Street: "王佐镇", Address: "王佐镇鑫湖家园"},
 			},
 		},
 		{
 			name:    "病例范围",
 			content: "确诊病例44至48：现住朝阳区建外街道光辉里小区。5月2日诊断为确诊病例，临床分型均为轻型。",
 			rs: model.Residents{
-				{Date: s2date("2022-05-02"), Name: "确诊病例44", Type: "轻型", City: "北京市", District: "朝阳区", Address: "建外街道光辉里小区"},
-				{Date: s2date("2022-05-02"), Name: "确诊病例45", Type: "轻型", City: "北京市", District: "朝阳区", Address: "建外街道光辉里小区"},
-				{Date: s2date("2022-05-02"), Name: "确诊病例46", Type: "轻型", City: "北京市", District: "朝阳区", Address: "建外街道光辉里小区"},
-				{Date: s2date("2022-05-02"), Name: "确诊病例47", Type: "轻型", City: "北京市", District: "朝阳区", Address: "建外街道光辉里小区"},
-				{Date: s2date("2022-05-02"), Name: "确诊病例48", Type: "轻型", City: "北京市", District: "朝阳区", Address: "建外街道光辉里小区"},
+				{Date: s2date("2022-05-02"), Name: "确诊病例44", Type: "轻型", City: "北京市", District: "朝阳区", Street: "建外街道", Address: "建外街道光辉里小区"},
+				{Date: s2date("2022-05-02"), Name: "确诊病例45", Type: "轻型", City: "北京市", District: "朝阳区", Street: "建外街道", Address: "建外街道光辉里小区"},
+				{Date: s2date("2022-05-02"), Name: "确诊病例46", Type: "轻型", City: "北京市", District: "朝阳区", Street: "建外街道", Address: "建外街道光辉里小区"},
+				{Date: s2date("2022-05-02"), Name: "确诊病例47", Type: "轻型", City: "北京市", District: "朝阳区", Street: "建外街道", Address: "建外街道光辉里小区"},
+				{Date: s2date("2022-05-02"), Name: "确诊病例48", Type: "轻型", City: "北京市", District: "朝阳区", Street: "建外街道", Address: "建外街道光辉里小区"},
 			},
 		},
 		// {
@@ -193,13 +199,14 @@ func TestParseDailyContentBeijing(t *testing.T) {
 		{
 			Content: "5月9日0时至24时，新增61例本土确诊病例(含1例5月7日、2例5月8日诊断的无症状感染者转确诊病例)和13例无症状感染者，无新增疑似病例；新增1例境外输入确诊病例，无新增疑似病例和无症状感染者。治愈出院26例。",
 			Daily: model.Daily{
-				Date:              s2date("2022-05-09"),
-				LocalPositive:     0,
-				Mild:              0,
-				Common:            0,
-				LocalConfirmed:    61,
-				LocalAsymptomatic: 13,
-				ImportedConfirmed: 1,
+				Date:                           s2date("2022-05-09"),
+				LocalPositive:                  0,
+				Mild:                           0,
+				Common:                         0,
+				LocalConfirmed:                 61,
+				LocalAsymptomatic:              13,
+				LocalConfirmedFromAsymptomatic: 3,
+				ImportedConfirmed:              1,
 				// DischargedFromHospital: 26, // 可以从标题中获得
 			},
 		},
@@ -0,0 +1,525 @@
+package crawler
+
+import (
+	"crawler/model"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+//	解析定义
+//
+//	用 YAML 或 JSON 文件描述一个城市的通报格式，包括 CSS 选择器、索引页面模板、标题判断以及
+//	字段正则表，由 DailyParserGeneric 加载后即可作为 DailyParser 使用，无需重新编译爬虫。
+//	示例见 definitions/ 目录。
+
+type ParserDefinition struct {
+	City      string               `json:"city" yaml:"city"`           // 城市名，如 beijing
+	Name      string               `json:"name" yaml:"name"`           // 显示名称，如 北京市
+	Districts []string             `json:"districts" yaml:"districts"` // 分区列表
+	Selectors map[string]string    `json:"selectors" yaml:"selectors"` // index, item, title, content
+	ItemLinks []string             `json:"item_links" yaml:"item_links"`
+	Index     IndexDefinition      `json:"index" yaml:"index"`
+	Titles    TitleDefinition      `json:"titles" yaml:"titles"`
+	Year      int                  `json:"year" yaml:"year"`       // 日期中没有年份时所使用的年份，默认 2022
+	Date      string               `json:"date" yaml:"date"`       // 日期正则，需包含 date 命名分组，如 4月21日
+	Replace   []ReplaceDefinition  `json:"replace" yaml:"replace"` // 解析内容前的文本替换
+	Title     []FieldDefinition    `json:"title" yaml:"title"`     // 标题中的字段
+	Content   []FieldDefinition    `json:"content" yaml:"content"` // 内容中的字段
+	Residents *ResidentsDefinition `json:"residents" yaml:"residents"`
+}
+
+// 索引页面链接：将 Template 中的 {page} 依次替换为 Format 格式化的页码
+type IndexDefinition struct {
+	Template  string `json:"template" yaml:"template"`
+	Format    string `json:"format" yaml:"format"`         // 页码格式，如 _%d
+	First     int    `json:"first" yaml:"first"`           // 起始页码
+	Pages     int    `json:"pages" yaml:"pages"`           // 页数
+	OmitFirst bool   `json:"omit_first" yaml:"omit_first"` // 第一页不带页码，如 index.html
+}
+
+type TitleDefinition struct {
+	Valid           []string `json:"valid" yaml:"valid"`                       // 标题包含任一即为需要抓取的通报
+	Daily           []string `json:"daily" yaml:"daily"`                       // 标题包含任一即为每日通报，为空则所有有效标题均是
+	NotDaily        []string `json:"not_daily" yaml:"not_daily"`               // 标题包含任一则不是每日通报
+	Residents       []string `json:"residents" yaml:"residents"`               // 标题包含任一即为居住地信息
+	ResidentsBefore string   `json:"residents_before" yaml:"residents_before"` // 该日期之前的通报包含居住地信息
+	ResidentsAfter  string   `json:"residents_after" yaml:"residents_after"`   // 该日期之后的通报包含居住地信息
+}
+
+type ReplaceDefinition struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// 字段正则
+//
+//	Field 为 model.Daily 中的字段名。对于 int 字段，取第一个非空的 number 分组（number、number1、number2…）；
+//	对于 District* 这类 map[string]int 字段，Regexp 匹配分区列表，Item 匹配列表中的每一项，
+//	每项由 district 分组给出分区，由 number 分组或者 from/to 分组（病例号范围）给出数量。
+//	int 字段也可以指定 Item，此时取各项 number 分组之和，如 “含1例5月7日、2例5月8日诊断的无症状感染者转确诊病例” 为 3。
+type FieldDefinition struct {
+	Field   string `json:"field" yaml:"field"`
+	Regexp  string `json:"regexp" yaml:"regexp"`
+	Item    string `json:"item" yaml:"item"`       // 分区列表中每一项的正则
+	Split   string `json:"split" yaml:"split"`     // 分区名之间的分隔符，如 “通州区和顺义区各3例” 中的 和
+	Require string `json:"require" yaml:"require"` // 匹配文本必须包含的内容
+	Fill    bool   `json:"fill" yaml:"fill"`       // 只在字段为 0 时填充（补充标题缺失）
+}
+
+// 居住地信息正则，每个匹配为一个病例
+//
+//	可用命名分组：name, type, number, gender, age, district, street, address（没有 street 时从 address 开头识别街道/乡镇）
+type ResidentsDefinition struct {
+	Regexp string `json:"regexp" yaml:"regexp"`
+	City   string `json:"city" yaml:"city"`
+}
+
+func LoadParserDefinition(filename string) (ParserDefinition, error) {
+	var def ParserDefinition
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return def, err
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &def)
+	case ".json":
+		err = json.Unmarshal(data, &def)
+	default:
+		err = fmt.Errorf("不支持的文件格式")
+	}
+	if err != nil {
+		return def, fmt.Errorf("无法读取解析定义 %q: %s", filename, err)
+	}
+	return def, nil
+}
+
+// 加载目录中全部解析定义 (*.yaml, *.yml, *.json) 并注册为城市，同名城市将被覆盖
+func LoadParserDefinitions(dir string) error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		switch strings.ToLower(filepath.Ext(f.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		filename := filepath.Join(dir, f.Name())
+		def, err := LoadParserDefinition(filename)
+		if err != nil {
+			return err
+		}
+		p, err := NewDailyParserGeneric(def)
+		if err != nil {
+			return fmt.Errorf("解析定义 %q 有误: %s", filename, err)
+		}
+		if _, err := GetCity(def.City); err == nil {
+			log.Infof("使用解析定义 %q 覆盖城市 %q", filename, def.City)
+		}
+		registerCity(City{Key: def.City, Name: def.Name, Parser: p}, true)
+	}
+	return nil
+}
+
+type fieldRule struct {
+	FieldDefinition
+	re   *regexp.Regexp
+	item *regexp.Regexp
+}
+
+// 由解析定义驱动的通用解析器
+type DailyParserGeneric struct {
+	def              ParserDefinition
+	reDate           *regexp.Regexp
+	title            []fieldRule
+	content          []fieldRule
+	reResidents      *regexp.Regexp
+	residents_before time.Time
+	residents_after  time.Time
+}
+
+var (
+	typeInt         = reflect.TypeOf(0)
+	typeDistrictMap = reflect.TypeOf(map[string]int{})
+)
+
+func NewDailyParserGeneric(def ParserDefinition) (*DailyParserGeneric, error) {
+	if len(def.City) == 0 {
+		return nil, fmt.Errorf("缺少城市名 (city)")
+	}
+	if def.Year == 0 {
+		def.Year = 2022
+	}
+	p := DailyParserGeneric{def: def}
+
+	var err error
+	if len(def.Date) > 0 {
+		if p.reDate, err = regexp.Compile(def.Date); err != nil {
+			return nil, fmt.Errorf("日期正则有误：%s", err)
+		}
+		if p.reDate.SubexpIndex("date") < 0 {
+			return nil, fmt.Errorf("日期正则缺少 date 分组：%q", def.Date)
+		}
+	}
+	if p.title, err = compileFieldRules(def.Title); err != nil {
+		return nil, err
+	}
+	if p.content, err = compileFieldRules(def.Content); err != nil {
+		return nil, err
+	}
+	if def.Residents != nil {
+		if p.reResidents, err = regexp.Compile(def.Residents.Regexp); err != nil {
+			return nil, fmt.Errorf("居住地信息正则有误：%s", err)
+		}
+	}
+	if len(def.Titles.ResidentsBefore) > 0 {
+		if p.residents_before, err = time.Parse("2006-01-02", def.Titles.ResidentsBefore); err != nil {
+			return nil, fmt.Errorf("无法解析日期 residents_before: %s", err)
+		}
+	}
+	if len(def.Titles.ResidentsAfter) > 0 {
+		if p.residents_after, err = time.Parse("2006-01-02", def.Titles.ResidentsAfter); err != nil {
+			return nil, fmt.Errorf("无法解析日期 residents_after: %s", err)
+		}
+	}
+	return &p, nil
+}
+
+func compileFieldRules(defs []FieldDefinition) ([]fieldRule, error) {
+	rules := make([]fieldRule, 0, len(defs))
+	for _, d := range defs {
+		f, ok := reflect.TypeOf(model.Daily{}).FieldByName(d.Field)
+		if !ok || (f.Type != typeInt && f.Type != typeDistrictMap) {
+			return nil, fmt.Errorf("model.Daily 中没有可解析的字段 %q", d.Field)
+		}
+		r := fieldRule{FieldDefinition: d}
+		var err error
+		if r.re, err = regexp.Compile(d.Regexp); err != nil {
+			return nil, fmt.Errorf("字段 %s 的正则有误：%s", d.Field, err)
+		}
+		if f.Type == typeDistrictMap {
+			item := d.Item
+			if len(item) == 0 {
+				item = d.Regexp
+			}
+			if r.item, err = regexp.Compile(item); err != nil {
+				return nil, fmt.Errorf("字段 %s 的分区正则有误：%s", d.Field, err)
+			}
+			if r.item.SubexpIndex("district") < 0 {
+				return nil, fmt.Errorf("字段 %s 的分区正则缺少 district 分组", d.Field)
+			}
+		} else if len(d.Item) > 0 {
+			if r.item, err = regexp.Compile(d.Item); err != nil {
+				return nil, fmt.Errorf("字段 %s 的分项正则有误：%s", d.Field, err)
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (p DailyParserGeneric) Definition() ParserDefinition {
+	return p.def
+}
+
+func (p DailyParserGeneric) GetSelector(t string) string {
+	return p.def.Selectors[t]
+}
+
+func (p DailyParserGeneric) GetItemLinks() []string {
+	return p.def.ItemLinks
+}
+
+func (p DailyParserGeneric) GetIndexLinks() []string {
+	idx := p.def.Index
+	links := []string{}
+	for i := idx.First; i < idx.First+idx.Pages; i++ {
+		page := fmt.Sprintf(idx.Format, i)
+		if i == idx.First && idx.OmitFirst {
+			page = ""
+		}
+		links = append(links, strings.ReplaceAll(idx.Template, "{page}", page))
+	}
+	return links
+}
+
+func (p DailyParserGeneric) GetDistricts() []string {
+	return p.def.Districts
+}
+
+func containsAny(s string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(s, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p DailyParserGeneric) IsValidTitle(title string) bool {
+	//	未配置时所有标题都视为有效
+	return len(p.def.Titles.Valid) == 0 || containsAny(title, p.def.Titles.Valid)
+}
+
+// 定义中用于提取数量的正则，用于统计解析覆盖率
+func (p DailyParserGeneric) GetExtractors() []*regexp.Regexp {
+	var res []*regexp.Regexp
+	for _, r := range p.content {
+		res = append(res, r.re)
+	}
+	if p.reResidents != nil {
+		res = append(res, p.reResidents)
+	}
+	return res
+}
+
+func (p DailyParserGeneric) IsDaily(date time.Time, title string) bool {
+	t := p.def.Titles
+	if containsAny(title, t.NotDaily) {
+		return false
+	}
+	if len(t.Daily) == 0 {
+		return p.IsValidTitle(title)
+	}
+	return containsAny(title, t.Daily)
+}
+
+func (p DailyParserGeneric) IsResidents(date time.Time, title string) bool {
+	if !p.IsValidTitle(title) {
+		return false
+	}
+	if containsAny(title, p.def.Titles.Residents) {
+		return true
+	}
+	if !p.residents_before.IsZero() && date.Before(p.residents_before) {
+		return true
+	}
+	if !p.residents_after.IsZero() && date.After(p.residents_after) {
+		return true
+	}
+	return false
+}
+
+func (p DailyParserGeneric) parseDate(text string) (time.Time, error) {
+	if p.reDate == nil {
+		return time.Time{}, nil
+	}
+	m := p.reDate.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("无法解析日期：%q", text)
+	}
+	s := strings.ReplaceAll(m[p.reDate.SubexpIndex("date")], " ", "")
+	if !strings.Contains(s, "年") {
+		s = fmt.Sprintf("%d年%s", p.def.Year, s)
+	}
+	return time.Parse("2006年1月2日", s)
+}
+
+func (p DailyParserGeneric) ParseDailyTitle(d *model.Daily, title string) error {
+	if d == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+	date, err := p.parseDate(title)
+	if err != nil {
+		return fmt.Errorf("[%s] 无法解析文章标题中日期：%q", d.Date.Format("2006-01-02"), title)
+	}
+	d.Date = date
+	return p.applyRules(d, p.title, title)
+}
+
+func (p DailyParserGeneric) ParseDailyContent(d *model.Daily, content string) error {
+	if d == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+	for _, r := range p.def.Replace {
+		content = strings.ReplaceAll(content, r.From, r.To)
+	}
+	// 日期 (补充标题缺失)
+	if d.Date.IsZero() {
+		if date, err := p.parseDate(content); err == nil {
+			d.Date = date
+		}
+	}
+	return p.applyRules(d, p.content, content)
+}
+
+func (p DailyParserGeneric) applyRules(d *model.Daily, rules []fieldRule, text string) error {
+	v := reflect.ValueOf(d).Elem()
+	for _, r := range rules {
+		f := v.FieldByName(r.Field)
+		if f.Type() == typeDistrictMap {
+			if r.Fill && f.Len() > 0 {
+				continue
+			}
+			dict := p.parseDistricts(d, r, text)
+			if dict != nil {
+				f.Set(reflect.ValueOf(dict))
+				d.Parsed(r.Field, p.extractor(r), r.re.FindString(text))
+			}
+			continue
+		}
+
+		if r.Fill && f.Int() != 0 {
+			continue
+		}
+		m := r.re.FindStringSubmatch(text)
+		if m == nil || (len(r.Require) > 0 && !strings.Contains(m[0], r.Require)) {
+			continue
+		}
+		var value int
+		if r.item != nil {
+			//	各项之和
+			items := r.item.FindAllStringSubmatch(m[0], -1)
+			if items == nil {
+				continue
+			}
+			for _, it := range items {
+				n, ok := submatchNumber(r.item, it)
+				if !ok {
+					continue
+				}
+				v, err := parseCount(n)
+				if err != nil {
+					return fmt.Errorf("[%s] 无法解析字段 %s：%q", d.Date.Format("2006-01-02"), r.Field, m[0])
+				}
+				value += v
+			}
+		} else {
+			n, ok := submatchNumber(r.re, m)
+			if !ok {
+				continue
+			}
+			var err error
+			if value, err = parseCount(n); err != nil {
+				return fmt.Errorf("[%s] 无法解析字段 %s：%q", d.Date.Format("2006-01-02"), r.Field, m[0])
+			}
+		}
+		f.SetInt(int64(value))
+		d.Parsed(r.Field, p.extractor(r), m[0])
+	}
+	return nil
+}
+
+// 字段来源中的提取器名，如 “definition:北京市.LocalConfirmed”
+func (p DailyParserGeneric) extractor(r fieldRule) string {
+	return fmt.Sprintf("definition:%s.%s", p.def.City, r.Field)
+}
+
+// 第一个非空的 number 分组，没有命名分组时取第一个非空分组
+func submatchNumber(re *regexp.Regexp, m []string) (string, bool) {
+	names := re.SubexpNames()
+	for i := 1; i < len(m); i++ {
+		if len(m[i]) > 0 && (len(names[i]) == 0 || strings.HasPrefix(names[i], "number")) {
+			return m[i], true
+		}
+	}
+	return "", false
+}
+
+func (p DailyParserGeneric) parseDistricts(d *model.Daily, r fieldRule, text string) map[string]int {
+	mm := r.re.FindAllString(text, -1)
+	if mm == nil {
+		return nil
+	}
+	dict := make(map[string]int)
+	i_district := r.item.SubexpIndex("district")
+	i_number := r.item.SubexpIndex("number")
+	i_from := r.item.SubexpIndex("from")
+	i_to := r.item.SubexpIndex("to")
+	for _, list := range mm {
+		for _, it := range r.item.FindAllStringSubmatch(list, -1) {
+			n := 1
+			var err error
+			if i_number > 0 && len(it[i_number]) > 0 {
+				n, err = parseCount(it[i_number])
+			} else if i_from > 0 && i_to > 0 && len(it[i_to]) > 0 {
+				var from, to int
+				if from, err = strconv.Atoi(it[i_from]); err == nil {
+					to, err = strconv.Atoi(it[i_to])
+					n = to - from + 1
+				}
+			}
+			if err != nil {
+				log.Warnf("[%s] 无法解析区域病例列表：%q", d.Date.Format("2006-01-02"), it[0])
+				continue
+			}
+			districts := []string{it[i_district]}
+			if len(r.Split) > 0 {
+				districts = strings.Split(it[i_district], r.Split)
+			}
+			for _, district := range districts {
+				district = strings.TrimSpace(district)
+				if len(district) > 0 {
+					dict[district] += n
+				}
+			}
+		}
+	}
+	return dict
+}
+
+func (p DailyParserGeneric) ParseResidents(rs *model.Residents, date time.Time, content string) error {
+	if rs == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+	if p.reResidents == nil {
+		return nil
+	}
+	for _, r := range p.def.Replace {
+		content = strings.ReplaceAll(content, r.From, r.To)
+	}
+
+	group := func(m []string, name string) string {
+		if i := p.reResidents.SubexpIndex(name); i > 0 {
+			return strings.TrimSpace(m[i])
+		}
+		return ""
+	}
+	for _, m := range p.reResidents.FindAllStringSubmatch(content, -1) {
+		r := model.Resident{
+			Date:     date,
+			Name:     group(m, "name"),
+			Type:     group(m, "type"),
+			Gender:   group(m, "gender"),
+			City:     p.def.Residents.City,
+			District: group(m, "district"),
+			Street:   group(m, "street"),
+			Address:  group(m, "address"),
+		}
+		if len(r.Street) == 0 {
+			r.Street = parseStreet(r.Address)
+		}
+		if len(r.Name) == 0 {
+			r.Name = r.Type + group(m, "number")
+		}
+		if age := group(m, "age"); len(age) > 0 {
+			if strings.HasSuffix(age, "月") {
+				n, err := strconv.Atoi(strings.TrimSuffix(age, "月"))
+				if err != nil {
+					log.Warnf("[%s] 无法解析居住地信息中的婴儿年龄：%q", date.Format("2006-01-02"), m[0])
+				}
+				r.Age = float64(n) / 12
+			} else {
+				n, err := strconv.Atoi(age)
+				if err != nil {
+					log.Warnf("[%s] 无法解析居住地信息中的年龄：%q", date.Format("2006-01-02"), m[0])
+				}
+				r.Age = float64(n)
+			}
+		}
+		*rs = append(*rs, r)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package crawler
+
+import (
+	"crawler/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyParserGeneric_Beijing(t *testing.T) {
+	def, err := LoadParserDefinition("../definitions/beijing.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	p, err := NewDailyParserGeneric(def)
+	if !assert.NoError(t, err) {
+		return
+	}
+	native := DailyParserBeijing{}
+
+	//	与 DailyParserBeijing 的结果应一致
+	for _, s := range []string{"index", "item", "title", "content"} {
+		assert.Equal(t, native.GetSelector(s), p.GetSelector(s))
+	}
+	assert.EqualValues(t, native.GetIndexLinks(), p.GetIndexLinks())
+	assert.EqualValues(t, native.GetDistricts(), p.GetDistricts())
+
+	titles := []string{
+		"北京5月1日新增36例本土确诊病例、 5例本土无症状感染者 治愈出院10例",
+		"北京4月17日新增3例本土确诊病例和4例境外输入确诊病例、1例境外输入无症状感染者 治愈出院7例",
+		"北京4月30日新增53例本土确诊病例、6例本土无症状感染者和1例境外输入无症状感染者 治愈出院11例 ",
+		"4月16日0时至24时，无新增本土确诊病例、疑似病例和无症状感染者；无新增境外输入确诊病例、疑似病例，新增3例境外输入无症状感染者。治愈出院4例。",
+	}
+	for _, title := range titles {
+		var expected, actual model.Daily
+		assert.NoError(t, native.ParseDailyTitle(&expected, title))
+		assert.NoError(t, p.ParseDailyTitle(&actual, title))
+		assert.EqualValues(t, expected, actual, "解析标题结果不一致：%q", title)
+		assert.Equal(t, native.IsValidTitle(title), p.IsValidTitle(title))
+		assert.Equal(t, native.IsDaily(expected.Date, title), p.IsDaily(actual.Date, title))
+		assert.Equal(t, native.IsResidents(expected.Date, title), p.IsResidents(actual.Date, title))
+	}
+
+	contents := []string{
+		"5月7日0时至15时，新增本土新冠肺炎病毒感染者45例。自5月6日发布会后（5月6日15时至7日15时），新增本土新冠肺炎病毒感染者78例（感染者749至826），其中，朝阳区37例，房山区24例，丰台区7例，通州区和顺义区各3例，海淀区2例，门头沟区和东城区各1例；普通型3例、轻型51例、无症状感染者24例；管控人员70例，社区筛查8例。均已转至定点医院隔离治疗，相关风险点位及人员均已管控落位。\n4月22日至5月7日15时，本市累计报告688例新冠肺炎病毒感染者，涉及15个区，其中，朝阳区288例，房山区179例。",
+		"5月9日0时至24时，新增61例本土确诊病例(含1例5月7日、2例5月8日诊断的无症状感染者转确诊病例)和13例无症状感染者，无新增疑似病例；新增1例境外输入确诊病例，无新增疑似病例和无症状感染者。治愈出院26例。",
+	}
+	for _, content := range contents {
+		var expected, actual model.Daily
+		assert.NoError(t, native.ParseDailyContent(&expected, content))
+		assert.NoError(t, p.ParseDailyContent(&actual, content))
+		assert.EqualValues(t, expected, actual, "解析内容结果不一致：%q", content)
+	}
+}
+
+func TestNewDailyParserGeneric_Invalid(t *testing.T) {
+	_, err := NewDailyParserGeneric(ParserDefinition{City: "x", Content: []FieldDefinition{{Field: "NoSuchField", Regexp: `(\d+)`}}})
+	assert.Error(t, err)
+	_, err = NewDailyParserGeneric(ParserDefinition{City: "x", Content: []FieldDefinition{{Field: "LocalConfirmed", Regexp: `(\d+`}}})
+	assert.Error(t, err)
+	_, err = NewDailyParserGeneric(ParserDefinition{City: "x", Content: []FieldDefinition{{Field: "DistrictPositive", Regexp: `(\d+)`}}})
+	assert.Error(t, err)
+}
+
+func TestDailyParserGeneric_ItemSum(t *testing.T) {
+	p, err := NewDailyParserGeneric(ParserDefinition{City: "x", Content: []FieldDefinition{
+		{Field: "LocalConfirmed", Regexp: `新增(?P<number>\d+)例本土确诊病例`},
+		{Field: "LocalConfirmedFromAsymptomatic", Regexp: `含(?:\d+例\d+月\d+日[、和]?)+诊断的无症状感染者转确诊病例`, Item: `(?P<number>\d+)例`},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	var d model.Daily
+	assert.NoError(t, p.ParseDailyContent(&d, "5月9日0时至24时，新增61例本土确诊病例(含1例5月7日、2例5月8日诊断的无症状感染者转确诊病例)和13例无症状感染者。"))
+	assert.Equal(t, 61, d.LocalConfirmed)
+	assert.Equal(t, 3, d.LocalConfirmedFromAsymptomatic)
+
+	_, err = NewDailyParserGeneric(ParserDefinition{City: "x", Content: []FieldDefinition{{Field: "LocalConfirmed", Regexp: `(\d+)`, Item: `(\d+`}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,406 @@
+package crawler
+
+import (
+	"crawler/model"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type DailyParserGuangzhou struct{}
+
+func init() {
+	RegisterCity(City{Key: "guangzhou", Name: "广州市", Parser: DailyParserGuangzhou{}})
+}
+
+func (p DailyParserGuangzhou) GetSelector(t string) string {
+	selectors := map[string]string{
+		"index":   ".news_list a, .list_content a",
+		"item":    ".content_main, .article",
+		"title":   ".content_title, .article_title",
+		"content": ".content_article p, .article_content p",
+	}
+
+	if val, ok := selectors[t]; ok {
+		return val
+	} else {
+		return ""
+	}
+}
+
+func (p DailyParserGuangzhou) GetItemLinks() []string {
+	return []string{}
+}
+
+func (p DailyParserGuangzhou) GetIndexLinks() []string {
+	const (
+		LINK_DAILY_1 string = "https://wjw.gz.gov.cn/ztzl/xxfyyqfk/yqtb/index{page}.html"
+		MAX_PAGES    int    = 10
+	)
+
+	links := []string{}
+
+	for i := 1; i <= MAX_PAGES; i++ {
+		//	首页>>专题专栏>>新型冠状病毒感染的肺炎疫情防控>>疫情通报
+		page := ""
+		if i > 1 {
+			page = fmt.Sprintf("_%d", i)
+		}
+		link := strings.ReplaceAll(LINK_DAILY_1, "{page}", page)
+
+		//	访问
+		links = append(links, link)
+	}
+
+	return links
+}
+
+func (p DailyParserGuangzhou) GetDistricts() []string {
+	return []string{
+		"越秀区", "海珠区", "荔湾区", "天河区", "白云区", "黄埔区",
+		"番禺区", "花都区", "南沙区", "从化区", "增城区",
+	}
+}
+
+//	广州的疫情通报中同时包含每日统计和感染者居住地信息
+
+// 提取数量的正则，见 CoverageParser
+func (p DailyParserGuangzhou) GetExtractors() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		reDailyImportedAsymptomatic,
+		reDailyImportedConfirmed,
+		reDailyLocalAllFromBubbleGuangzhou,
+		reDailyLocalAsymptomatic,
+		reDailyLocalAsymptomaticFromBubbleGuangzhou,
+		reDailyLocalConfirmed,
+		reDailyLocalConfirmedFromAsymptomaticGuangzhou,
+		reDailyLocalConfirmedFromBubbleGuangzhou,
+		reDailyLocalDischargedFromHospitalGuangzhou,
+		reDailyLocalDischargedFromMedicalObservationGuangzhou,
+		reDailyRegionListGuangzhou,
+		reDailyTotalLocalConfirmedGuangzhou,
+		reResidentGuangzhou,
+	}
+}
+
+func (p DailyParserGuangzhou) IsDaily(date time.Time, title string) bool {
+	return p.IsValidTitle(title)
+}
+
+func (p DailyParserGuangzhou) IsResidents(date time.Time, title string) bool {
+	return p.IsValidTitle(title)
+}
+
+func (p DailyParserGuangzhou) IsValidTitle(title string) bool {
+	return strings.Contains(title, "新冠肺炎疫情情况") || strings.Contains(title, "新冠肺炎疫情通报")
+}
+
+var (
+	reDailyDateGuangzhou        = regexp.MustCompile(`(?P<date>(?:\d+年)?\d+月\d+日)`)
+	reDailyContentDateGuangzhou = regexp.MustCompile(`(?P<date>(?:\d+年)?\d+月\d+日)\s*0\s*[—\-－至时]+\s*24\s*时`)
+)
+
+func (p DailyParserGuangzhou) parseDate(s string) (time.Time, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	if !strings.Contains(s, "年") {
+		s = fmt.Sprintf("2022年%s", s)
+	}
+	return time.Parse("2006年1月2日", s)
+}
+
+//	解析 Daily
+
+// 解析 Daily 标题
+//
+// 标题中只有发布日期，通报的是前一天的数据，因此统计日期为发布日期减一天；
+// 索引页按该日期筛选 --since/--until，内容中有统计日期时以内容为准
+func (p DailyParserGuangzhou) ParseDailyTitle(d *model.Daily, title string) error {
+	if d == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+
+	// 标题日期
+	m := reDailyDateGuangzhou.FindStringSubmatch(title)
+	if m == nil {
+		return fmt.Errorf("[%s] 无法解析文章标题中日期：%q", d.Date.Format("2006-01-02"), title)
+	}
+	date, err := p.parseDate(m[1])
+	if err != nil {
+		return fmt.Errorf("[%s] 无法解析文章标题中日期：%q", d.Date.Format("2006-01-02"), m[1])
+	}
+	d.Date = date.AddDate(0, 0, -1)
+
+	return nil
+}
+
+var (
+	reDailyLocalConfirmedFromAsymptomaticGuangzhou        = regexp.MustCompile(`(?:含|其中|有)(?P<number>\d+)例(?:为|由)?(?:既往)?无症状感染者转(?:为)?确诊`)
+	reDailyLocalConfirmedFromBubbleGuangzhou              = regexp.MustCompile(`(?P<number>\d+)例确诊病例(?:和\d+例无症状感染者)?(?:均)?在隔离管控中发现`)
+	reDailyLocalAllFromBubbleGuangzhou                    = regexp.MustCompile(`新增本土确诊病例\d+例[^。\n]*新增本土无症状感染者\d+例[，,]均在隔离管控中发现`)
+	reDailyLocalAsymptomaticFromBubbleGuangzhou           = regexp.MustCompile(`(?:确诊病例)?(?:和)(?P<number>\d+)例无症状感染者(?:均)?在隔离管控中发现`)
+	reDailyLocalDischargedFromHospitalGuangzhou           = regexp.MustCompile(`本土确诊病例[^。\n]*治愈出院(?P<number>\d+)例`)
+	reDailyLocalDischargedFromMedicalObservationGuangzhou = regexp.MustCompile(`本土无症状感染者[^。\n]*解除医学观察(?P<number>\d+)例`)
+	reDailyTotalLocalConfirmedGuangzhou                   = regexp.MustCompile(`累计报告本土确诊病例(?P<number>\d+)例`)
+)
+
+// 解析 Daily 内容
+func (p DailyParserGuangzhou) ParseDailyContent(d *model.Daily, content string) error {
+	if d == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+
+	var m []string
+	var err error
+
+	// 日期：标题中为发布日期，内容中“0—24时”前的日期才是统计日期
+	m = reDailyContentDateGuangzhou.FindStringSubmatch(content)
+	if m == nil {
+		// log.Warnf("[%s] 无法解析文章内容中日期：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		d.Date, err = p.parseDate(m[1])
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中日期：%q", d.Date.Format("2006-01-02"), m[1])
+		}
+	}
+
+	// 本土新增
+	m = reDailyLocalConfirmed.FindStringSubmatch(content)
+	if m == nil {
+		// log.Warnf("[%s] 无法解析文章内容中本土新增：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		///	2种情况
+		n := m[1] + m[2]
+		d.Parsed("LocalConfirmed", "reDailyLocalConfirmed", m[0])
+		d.LocalConfirmed, err = parseCount(n)
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中本土新增：%q", d.Date.Format("2006-01-02"), m[0])
+		}
+	}
+
+	// 本土无症状
+	m = reDailyLocalAsymptomatic.FindStringSubmatch(content)
+	if m == nil || !strings.Contains(m[0], "无症状") {
+		// log.Warnf("[%s] 无法解析文章内容中本土无症状：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		// 有3种情况
+		n := m[1] + m[2] + m[3]
+		d.Parsed("LocalAsymptomatic", "reDailyLocalAsymptomatic", m[0])
+		d.LocalAsymptomatic, err = parseCount(n)
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中本土无症状：%q", d.Date.Format("2006-01-02"), m[0])
+		}
+	}
+
+	// 境外输入确诊
+	m = reDailyImportedConfirmed.FindStringSubmatch(content)
+	if m == nil {
+		// log.Warnf("[%s] 无法解析文章内容中境外输入确诊：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		//	数值有两个case
+		n := m[1] + m[2]
+		d.Parsed("ImportedConfirmed", "reDailyImportedConfirmed", m[0])
+		d.ImportedConfirmed, err = parseCount(n)
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中境外输入确诊：%q", d.Date.Format("2006-01-02"), m[0])
+		}
+	}
+
+	// 境外输入无症状
+	m = reDailyImportedAsymptomatic.FindStringSubmatch(content)
+	if m == nil || !strings.Contains(m[0], "无症状") {
+		// log.Warnf("[%s] 无法解析文章内容中境外输入无症状：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		n := m[1] + m[2] + m[3] + m[4]
+		d.Parsed("ImportedAsymptomatic", "reDailyImportedAsymptomatic", m[0])
+		d.ImportedAsymptomatic, err = parseCount(n)
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中境外输入无症状：%q", d.Date.Format("2006-01-02"), m[0])
+		}
+	}
+
+	// 本土无症状转为确诊病例
+	m = reDailyLocalConfirmedFromAsymptomaticGuangzhou.FindStringSubmatch(content)
+	if m == nil {
+		// log.Warnf("[%s] 无法解析文章内容中本土无症状转为确诊病例：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		d.Parsed("LocalConfirmedFromAsymptomatic", "reDailyLocalConfirmedFromAsymptomaticGuangzhou", m[0])
+		d.LocalConfirmedFromAsymptomatic, err = parseCount(m[1])
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中本土无症状转为确诊病例：%q", d.Date.Format("2006-01-02"), m[1])
+		}
+	}
+
+	// 本土隔离管控中发现的确诊病例
+	m = reDailyLocalConfirmedFromBubbleGuangzhou.FindStringSubmatch(content)
+	if m == nil {
+		// log.Warnf("[%s] 无法解析文章内容中本土隔离管控中发现的确诊病例：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		d.Parsed("LocalConfirmedFromBubble", "reDailyLocalConfirmedFromBubbleGuangzhou", m[0])
+		d.LocalConfirmedFromBubble, err = parseCount(m[1])
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中本土隔离管控中发现的确诊病例：%q", d.Date.Format("2006-01-02"), m[1])
+		}
+	}
+
+	// 本土隔离管控中发现的无症状病例
+	m = reDailyLocalAsymptomaticFromBubbleGuangzhou.FindStringSubmatch(content)
+	if m == nil {
+		// log.Warnf("[%s] 无法解析文章内容中本土隔离管控中发现的无症状病例：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		d.Parsed("LocalAsymptomaticFromBubble", "reDailyLocalAsymptomaticFromBubbleGuangzhou", m[0])
+		d.LocalAsymptomaticFromBubble, err = parseCount(m[1])
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中本土隔离管控中发现的无症状病例：%q", d.Date.Format("2006-01-02"), m[1])
+		}
+	}
+
+	// “新增本土确诊病例3例（其中1例为无症状感染者转确诊），新增本土无症状感染者6例，均在隔离管控中发现”
+	//	通报没有给出数量，除无症状感染者转确诊外，全部在隔离管控中发现
+	if d.LocalConfirmedFromBubble == 0 && d.LocalAsymptomaticFromBubble == 0 {
+		if reDailyLocalAllFromBubbleGuangzhou.MatchString(content) {
+			d.LocalConfirmedFromBubble = d.LocalConfirmed - d.LocalConfirmedFromAsymptomatic
+			d.LocalAsymptomaticFromBubble = d.LocalAsymptomatic
+			d.Derived("LocalConfirmedFromBubble", "reDailyLocalAllFromBubbleGuangzhou", "LocalConfirmed - LocalConfirmedFromAsymptomatic")
+			d.Derived("LocalAsymptomaticFromBubble", "reDailyLocalAllFromBubbleGuangzhou", "LocalAsymptomatic")
+		}
+	}
+
+	// 本土治愈出院
+	m = reDailyLocalDischargedFromHospitalGuangzhou.FindStringSubmatch(content)
+	if m == nil {
+		// log.Warnf("[%s] 无法解析文章内容中本土治愈出院：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		d.Parsed("LocalDischargedFromHospital", "reDailyLocalDischargedFromHospitalGuangzhou", m[0])
+		d.LocalDischargedFromHospital, err = parseCount(m[1])
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中本土治愈出院：%q", d.Date.Format("2006-01-02"), m[1])
+		}
+	}
+
+	// 本土解除医学观察
+	m = reDailyLocalDischargedFromMedicalObservationGuangzhou.FindStringSubmatch(content)
+	if m == nil {
+		// log.Warnf("[%s] 无法解析文章内容中本土解除医学观察：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		d.Parsed("LocalDischargedFromMedicalObservation", "reDailyLocalDischargedFromMedicalObservationGuangzhou", m[0])
+		d.LocalDischargedFromMedicalObservation, err = parseCount(m[1])
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中本土解除医学观察：%q", d.Date.Format("2006-01-02"), m[1])
+		}
+	}
+
+	// 累计本土确诊
+	m = reDailyTotalLocalConfirmedGuangzhou.FindStringSubmatch(content)
+	if m == nil {
+		// log.Warnf("[%s] 无法解析文章内容中累计本土确诊：%q", d.Date.Format("2006-01-02"), content)
+	} else {
+		d.Parsed("TotalLocalConfirmed", "reDailyTotalLocalConfirmedGuangzhou", m[0])
+		d.TotalLocalConfirmed, err = parseCount(m[1])
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中累计本土确诊：%q", d.Date.Format("2006-01-02"), m[1])
+		}
+	}
+
+	//	进一步解析城区信息
+	return p.parseDailyContentRegion(d, content)
+}
+
+// “其中，海珠区1925例，番禺区315例，越秀区和荔湾区各2例。”
+var (
+	patternCaseGuangzhou        = `(?P<district>[^，；。、\n]{2,3}区(?:[和、][^，；。、\n]{2,3}区)*)各?(?P<number>\d+)例`
+	reDailyRegionListGuangzhou  = regexp.MustCompile(`(?:新增[^\n。]*感染者[^\n。]*中|其中)[，：:]?(?:` + patternCaseGuangzhou + `[，、；。]?)+`)
+	reDailyRegionItemGuangzhou  = regexp.MustCompile(patternCaseGuangzhou)
+	reDailyRegionSplitGuangzhou = regexp.MustCompile(`[和、]`)
+)
+
+func (p DailyParserGuangzhou) parseDailyContentRegion(d *model.Daily, content string) error {
+	mm := reDailyRegionListGuangzhou.FindAllStringSubmatch(content, -1)
+	if mm == nil {
+		if d.LocalConfirmed+d.LocalAsymptomatic > 0 {
+			log.Warnf("[%s] 无法解析文章内容中城区阳性感染者", d.Date.Format("2006-01-02"))
+		}
+		return nil
+	}
+
+	dict := make(map[string]int)
+	for _, m := range mm {
+		for _, it := range reDailyRegionItemGuangzhou.FindAllStringSubmatch(m[0], -1) {
+			v, err := parseCount(it[2])
+			if err != nil {
+				log.Warnf("[%s] 无法解析区域病例列表：%q", d.Date.Format("2006-01-02"), it)
+				continue
+			}
+			for _, r := range reDailyRegionSplitGuangzhou.Split(it[1], -1) {
+				r = strings.TrimSpace(r)
+				if len(r) > 0 {
+					dict[r] += v
+				}
+			}
+		}
+	}
+	d.DistrictPositive = dict
+	d.Parsed("DistrictPositive", "reDailyRegionListGuangzhou", mm[0][0])
+	return nil
+}
+
+// “本土确诊病例1：男，35岁，居住在白云区太和镇大源村，……”
+// “本土无症状感染者3—5：为同一家庭成员，居住在……”这种合并的写法没有年龄和性别信息，只提取居住地
+var (
+	reResidentGuangzhou = regexp.MustCompile(`(?:本土)?(?P<type>确诊病例|无症状感染者)(?P<from>\d+)(?:[—\-至](?P<to>\d+))?[：:，](?:(?P<gender>男|女)，(?P<age>\d+)(?P<unit>岁|月龄|个月)，)?[^\n]*?(?:居住(?:在|于|地为)|现住)(?P<district>[^，。\n]{2,3}区)(?P<addr>[^，。\n]*)`)
+)
+
+func (p DailyParserGuangzhou) ParseResidents(rs *model.Residents, date time.Time, content string) error {
+	if rs == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+
+	for _, m := range reResidentGuangzhou.FindAllStringSubmatch(content, -1) {
+		from, err := strconv.Atoi(m[2])
+		if err != nil {
+			log.Warnf("[%s] 无法解析居住地信息中的病例号：%q", date.Format("2006-01-02"), m[0])
+			continue
+		}
+		to := from
+		if len(m[3]) > 0 {
+			to, err = strconv.Atoi(m[3])
+			if err != nil || to < from {
+				log.Warnf("[%s] 无法解析居住地信息中的病例号：%q", date.Format("2006-01-02"), m[0])
+				continue
+			}
+		}
+
+		var age float64
+		if len(m[5]) > 0 {
+			n, err := strconv.Atoi(m[5])
+			if err != nil {
+				log.Warnf("[%s] 无法解析居住地信息中的年龄：%q", date.Format("2006-01-02"), m[0])
+			}
+			age = float64(n)
+			if m[6] != "岁" {
+				//	婴儿
+				age = age / 12
+			}
+		}
+
+		for i := from; i <= to; i++ {
+			r := model.Resident{
+				Date:     date,
+				Name:     fmt.Sprintf("%s%d", m[1], i),
+				Type:     m[1],
+				Gender:   m[4],
+				Age:      age,
+				City:     "广州市",
+				District: strings.TrimSpace(m[7]),
+				Street:   parseStreet(m[8]),
+				Address:  strings.TrimSpace(m[8]),
+			}
+			*rs = append(*rs, r)
+		}
+	}
+
+	return nil
+}
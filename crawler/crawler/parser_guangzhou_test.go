@@ -0,0 +1,193 @@
+package crawler
+
+import (
+	"crawler/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDailyTitleGuangzhou(t *testing.T) {
+	testcases := []test_case{
+		{
+			"2022年4月11日广州市新冠肺炎疫情情况",
+			model.Daily{Date: s2date("2022-04-10")},
+		},
+		{
+			"广州市新冠肺炎疫情情况（11月11日）",
+			model.Daily{Date: s2date("2022-11-10")},
+		},
+	}
+
+	p := DailyParserGuangzhou{}
+	for i, c := range testcases {
+		var d model.Daily
+		assert.True(t, p.IsValidTitle(c.content))
+		err := p.ParseDailyTitle(&d, c.content)
+		assert.NoErrorf(t, err, "解析标题失败 (%d) %q => %s", i, c.content, err)
+		assert.EqualValues(t, c.daily, d, "匹配标题失败 (%d) '%s'", i, c.content)
+	}
+
+	assert.False(t, p.IsValidTitle("广州市卫生健康委员会关于开展2022年全民健康素养监测的通知"))
+}
+
+func TestParseDailyContentGuangzhou(t *testing.T) {
+	testcases := []test_case{
+		{
+			"2022年4月10日0—24时，广州市新增本土确诊病例3例（其中1例为无症状感染者转确诊），新增本土无症状感染者6例，均在隔离管控中发现；新增境外输入确诊病例2例，新增境外输入无症状感染者1例。\n" +
+				"新增本土确诊病例和无症状感染者中，白云区5例，番禺区2例，海珠区和花都区各1例。\n" +
+				"本土确诊病例1：男，35岁，居住在白云区太和镇大源村，作为密切接触者在隔离管控中发现。\n" +
+				"本土确诊病例2：女，8月龄，居住在白云区嘉禾街道望岗村，作为密切接触者在隔离管控中发现。\n" +
+				"本土确诊病例3：女，62岁，居住在番禺区大石街道，为既往无症状感染者转确诊。\n" +
+				"本土无症状感染者1—4：为同一家庭成员，居住在白云区太和镇大源村，作为密切接触者在隔离管控中发现。\n" +
+				"本土无症状感染者5：男，41岁，居住在海珠区凤阳街道，作为密切接触者在隔离管控中发现。\n" +
+				"本土无症状感染者6：男，27岁，居住在花都区新华街道，作为密切接触者在隔离管控中发现。\n" +
+				"当日本土确诊病例治愈出院4例，本土无症状感染者解除医学观察7例。截至4月10日24时，全市累计报告本土确诊病例1431例。",
+			model.Daily{
+				Date:                                  s2date("2022-04-10"),
+				LocalConfirmed:                        3,
+				LocalAsymptomatic:                     6,
+				LocalConfirmedFromAsymptomatic:        1,
+				LocalConfirmedFromBubble:              2,
+				LocalAsymptomaticFromBubble:           6,
+				ImportedConfirmed:                     2,
+				ImportedAsymptomatic:                  1,
+				LocalDischargedFromHospital:           4,
+				LocalDischargedFromMedicalObservation: 7,
+				TotalLocalConfirmed:                   1431,
+				DistrictPositive: map[string]int{
+					"白云区": 5,
+					"番禺区": 2,
+					"海珠区": 1,
+					"花都区": 1,
+				},
+			},
+		},
+		{
+			"11月10日0—24时，全市新增本土确诊病例163例和无症状感染者2661例。其中，海珠区1925例，番禺区315例，白云区308例，天河区113例，荔湾区71例，越秀区34例，花都区24例，黄埔区21例，南沙区9例，从化区5例，增城区1例。本土确诊病例中，有38例为无症状感染者转确诊。",
+			model.Daily{
+				Date:                           s2date("2022-11-10"),
+				LocalConfirmed:                 163,
+				LocalAsymptomatic:              2661,
+				LocalConfirmedFromAsymptomatic: 38,
+				DistrictPositive: map[string]int{
+					"海珠区": 1925,
+					"番禺区": 315,
+					"白云区": 308,
+					"天河区": 113,
+					"荔湾区": 71,
+					"越秀区": 34,
+					"花都区": 24,
+					"黄埔区": 21,
+					"南沙区": 9,
+					"从化区": 5,
+					"增城区": 1,
+				},
+			},
+		},
+	}
+
+	p := DailyParserGuangzhou{}
+	for i, c := range testcases {
+		//	标题为发布日期，内容中的统计日期优先
+		d := model.Daily{Date: c.daily.Date.AddDate(0, 0, 1)}
+		err := p.ParseDailyContent(&d, c.content)
+		assert.NoErrorf(t, err, "解析内容失败 (%d) %q => %s", i, c.content, err)
+		assert.EqualValuesf(t, c.daily, d, "匹配内容失败 (%d) '%s'", i, c.content)
+	}
+}
+
+func TestParseDailyContentGuangzhou_FromBubble(t *testing.T) {
+	//	“均在隔离管控中发现”没有给出数量，由总数计算得出
+	var d model.Daily
+	d.EnableProvenance()
+	content := "2022年4月10日0—24时，广州市新增本土确诊病例3例（其中1例为无症状感染者转确诊），新增本土无症状感染者6例，均在隔离管控中发现；新增境外输入确诊病例2例，新增境外输入无症状感染者1例。"
+	assert.NoError(t, DailyParserGuangzhou{}.ParseDailyContent(&d, content))
+	assert.Equal(t, 2, d.LocalConfirmedFromBubble)
+	assert.Equal(t, 6, d.LocalAsymptomaticFromBubble)
+	assert.Equal(t, model.Provenance{Kind: model.ProvenanceDerived, Extractor: "reDailyLocalAllFromBubbleGuangzhou", Snippet: "LocalConfirmed - LocalConfirmedFromAsymptomatic"}, d.Provenance["LocalConfirmedFromBubble"])
+	assert.Equal(t, model.Provenance{Kind: model.ProvenanceDerived, Extractor: "reDailyLocalAllFromBubbleGuangzhou", Snippet: "LocalAsymptomatic"}, d.Provenance["LocalAsymptomaticFromBubble"])
+
+	//	没有说明在哪里发现的，不推算
+	d = model.Daily{}
+	content = "11月10日0—24时，全市新增本土确诊病例163例和无症状感染者2661例。本土确诊病例中，有38例为无症状感染者转确诊。"
+	assert.NoError(t, DailyParserGuangzhou{}.ParseDailyContent(&d, content))
+	assert.Zero(t, d.LocalConfirmedFromBubble)
+	assert.Zero(t, d.LocalAsymptomaticFromBubble)
+}
+
+func TestDailyParserGuangzhou_ParseResidents(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		rs      model.Residents
+	}{
+		{
+			name:    "单一病例",
+			content: "本土确诊病例1：男，35岁，居住在白云区太和镇大源村，作为密切接触者在隔离管控中发现。",
+			rs: model.Residents{
+				{Date: s2date("2022-04-10"), Name: "确诊病例1", Type: "确诊病例", Gender: "男", Age: 35, City: "广州市", District: "白云区", Street: "太和镇", Address: "太和镇大源村"},
+			},
+		},
+		{
+			name:    "单一病例 - 婴儿",
+			content: "本土确诊病例2：女，6月龄，居住在海珠区凤阳街道康乐村，作为密切接触者在隔离管控中发现。",
+			rs: model.Residents{
+				{Date: s2date("2022-04-10"), Name: "确诊病例2", Type: "确诊病例", Gender: "女", Age: 0.5, City: "广州市", District: "海珠区", Street: "凤阳街道", Address: "凤阳街道康乐村"},
+			},
+		},
+		{
+			name:    "多个病例 - 同一居住地",
+			content: "本土无症状感染者1—3：为同一家庭成员，居住在番禺区大石街道，在社区核酸筛查中发现。",
+			rs: model.Residents{
+				{Date: s2date("2022-04-10"), Name: "无症状感染者1", Type: "无症状感染者", City: "广州市", District: "番禺区", Street: "大石街道", Address: "大石街道"},
+				{Date: s2date("2022-04-10"), Name: "无症状感染者2", Type: "无症状感染者", City: "广州市", District: "番禺区", Street: "大石街道", Address: "大石街道"},
+				{Date: s2date("2022-04-10"), Name: "无症状感染者3", Type: "无症状感染者", City: "广州市", District: "番禺区", Street: "大石街道", Address: "大石街道"},
+			},
+		},
+		{
+			name: "多行",
+			content: "2022年4月10日0—24时，广州市新增本土确诊病例1例，新增本土无症状感染者1例。\n" +
+				"本土确诊病例1：男，35岁，居住在白云区太和镇大源村，作为密切接触者在隔离管控中发现。\n" +
+				"本土无症状感染者1：女，41岁，现住花都区新华街道，在社区核酸筛查中发现。",
+			rs: model.Residents{
+				{Date: s2date("2022-04-10"), Name: "确诊病例1", Type: "确诊病例", Gender: "男", Age: 35, City: "广州市", District: "白云区", Street: "太和镇", Address: "太和镇大源村"},
+				{Date: s2date("2022-04-10"), Name: "无症状感染者1", Type: "无症状感染者", Gender: "女", Age: 41, City: "广州市", District: "花都区", Street: "新华街道", Address: "新华街道"},
+			},
+		},
+	}
+
+	p := DailyParserGuangzhou{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := model.Residents{}
+			err := p.ParseResidents(&rs, s2date("2022-04-10"), tt.content)
+			assert.NoError(t, err)
+			assert.EqualValues(t, tt.rs, rs)
+		})
+	}
+}
+
+func TestGuangzhou_DistrictMismatch(t *testing.T) {
+	//	保存的通报中，分区统计（白云区5例，番禺区2例）与病例列表（白云区6例，番禺区1例）不一致
+	page := "guangzhou/2022-04-10.html"
+	g := parseGoldenPage(t, "guangzhou", goldenLink("testdata/"+page), []byte(readSavedPage(t, page)))
+	if !assert.Len(t, g.Dailys, 1) {
+		return
+	}
+	d := g.Dailys[0]
+	assert.Equal(t, map[string]int{"白云区": 5, "番禺区": 2, "海珠区": 1, "花都区": 1}, d.DistrictPositive)
+
+	var vs model.Violations
+	for _, v := range d.Validate() {
+		if v.Rule == "positive-district-by-type" {
+			vs = append(vs, v)
+		}
+	}
+	if assert.Len(t, vs, 2) {
+		assert.Contains(t, vs[0].Message, "番禺区")
+		assert.Equal(t, [2]int{2, 1}, [2]int{vs[0].Actual, vs[0].Expected})
+		assert.Contains(t, vs[1].Message, "白云区")
+		assert.Equal(t, [2]int{5, 6}, [2]int{vs[1].Actual, vs[1].Expected})
+	}
+}
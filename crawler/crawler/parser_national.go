@@ -0,0 +1,311 @@
+package crawler
+
+import (
+	"crawler/model"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// 国家卫健委每日通报
+//
+//	分区数据（District*）按省份统计，省份名称与通报中一致，如：上海、北京、新疆生产建设兵团
+type DailyParserNational struct{}
+
+func init() {
+	RegisterCity(City{Key: "national", Name: "全国", Parser: DailyParserNational{}})
+}
+
+func (p DailyParserNational) GetSelector(t string) string {
+	selectors := map[string]string{
+		"index":   ".zxxx_list a",
+		"item":    ".list",
+		"title":   ".tit",
+		"content": "#xw_box p",
+	}
+
+	if val, ok := selectors[t]; ok {
+		return val
+	} else {
+		return ""
+	}
+}
+
+func (p DailyParserNational) GetItemLinks() []string {
+	return []string{}
+}
+
+func (p DailyParserNational) GetIndexLinks() []string {
+	const (
+		LINK_DAILY_1 string = "http://www.nhc.gov.cn/xcs/yqtb/list_gzbd{page}.shtml"
+		MAX_PAGES    int    = 10
+	)
+
+	links := []string{}
+
+	for i := 1; i <= MAX_PAGES; i++ {
+		//	首页>>卫生应急办公室>>疫情通报
+		page := ""
+		if i > 1 {
+			page = fmt.Sprintf("_%d", i)
+		}
+		link := strings.ReplaceAll(LINK_DAILY_1, "{page}", page)
+
+		//	访问
+		links = append(links, link)
+	}
+
+	return links
+}
+
+func (p DailyParserNational) GetDistricts() []string {
+	return []string{
+		"北京", "天津", "河北", "山西", "内蒙古", "辽宁", "吉林", "黑龙江",
+		"上海", "江苏", "浙江", "安徽", "福建", "江西", "山东", "河南",
+		"湖北", "湖南", "广东", "广西", "海南", "重庆", "四川", "贵州",
+		"云南", "西藏", "陕西", "甘肃", "青海", "宁夏", "新疆", "新疆生产建设兵团",
+	}
+}
+
+// 提取数量的正则，见 CoverageParser
+func (p DailyParserNational) GetExtractors() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		reDailyAsymptomaticNational,
+		reDailyConfirmedNational,
+		reDailyCurrentImportedInHospitalNational,
+		reDailyCurrentInHospitalNational,
+		reDailyCurrentSevereNational,
+		reDailyDeathNational,
+		reDailyDischargedFromHospitalNational,
+		reDailyDischargedFromMedicalObservationNational,
+		reDailyImportedAsymptomaticNational,
+		reDailyImportedConfirmedNational,
+		reDailyImportedDischargedFromHospitalNational,
+		reDailyImportedDischargedFromMedicalObservationNational,
+		reDailyImportedUnderMedicalObservationNational,
+		reDailyLocalAsymptomaticNational,
+		reDailyLocalConfirmedFromAsymptomaticNational,
+		reDailyLocalConfirmedNational,
+		reDailyLocalDischargedFromHospitalNational,
+		reDailyTotalConfirmedNational,
+		reDailyTotalDeathNational,
+		reDailyTotalDischargedFromHospitalNational,
+		reDailyTotalImportedConfirmedNational,
+		reDailyTotalImportedDischargedNational,
+		reDailyUnderMedicalObservationNational,
+	}
+}
+
+func (p DailyParserNational) IsDaily(date time.Time, title string) bool {
+	return p.IsValidTitle(title)
+}
+
+// 国家卫健委通报中没有感染者居住地信息
+func (p DailyParserNational) IsResidents(date time.Time, title string) bool {
+	return false
+}
+
+func (p DailyParserNational) IsValidTitle(title string) bool {
+	return strings.Contains(title, "疫情最新情况")
+}
+
+var (
+	reDailyDateNational = regexp.MustCompile(`截至(?P<date>(?:\d+年)?\d+月\d+日)\s*24时`)
+)
+
+// 解析 Daily 标题
+//
+//	“截至4月14日24时新型冠状病毒肺炎疫情最新情况”
+func (p DailyParserNational) ParseDailyTitle(d *model.Daily, title string) error {
+	if d == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+
+	m := reDailyDateNational.FindStringSubmatch(title)
+	if m == nil {
+		return fmt.Errorf("[%s] 无法解析文章标题中日期：%q", d.Date.Format("2006-01-02"), title)
+	}
+	s := strings.ReplaceAll(m[1], " ", "")
+	if !strings.Contains(s, "年") {
+		s = fmt.Sprintf("2022年%s", s)
+	}
+	var err error
+	d.Date, err = time.Parse("2006年1月2日", s)
+	if err != nil {
+		return fmt.Errorf("[%s] 无法解析文章标题中日期：%q", d.Date.Format("2006-01-02"), m[1])
+	}
+
+	return nil
+}
+
+// 括号中的省份列表，如：（上海2573例，吉林326例，北京5例）
+const patternProvinceListNational = `(?:（(?P<provinces>[^）]+)）)?`
+
+var (
+	reDailyConfirmedNational                                = regexp.MustCompile(`报告新增确诊病例(?P<number>\d+)例`)
+	reDailyImportedConfirmedNational                        = regexp.MustCompile(`新增确诊病例\d+例。其中境外输入病例(?P<number>\d+)例`)
+	reDailyLocalConfirmedNational                           = regexp.MustCompile(`；本土病例(?P<number>\d+)例` + patternProvinceListNational)
+	reDailyLocalConfirmedFromAsymptomaticNational           = regexp.MustCompile(`；本土病例\d+例(?:（[^）]+）)?，含(?P<number>\d+)例由无症状感染者转为确诊病例` + patternProvinceListNational)
+	reDailyDeathNational                                    = regexp.MustCompile(`新增死亡病例(?P<number>\d+)例`)
+	reDailyDischargedFromHospitalNational                   = regexp.MustCompile(`当日新增治愈出院病例(?P<number>\d+)例`)
+	reDailyImportedDischargedFromHospitalNational           = regexp.MustCompile(`当日新增治愈出院病例\d+例，其中境外输入病例(?P<number>\d+)例`)
+	reDailyLocalDischargedFromHospitalNational              = regexp.MustCompile(`当日新增治愈出院病例\d+例，[^。]*本土病例(?P<number>\d+)例`)
+	reDailyCurrentImportedInHospitalNational                = regexp.MustCompile(`境外输入现有确诊病例(?P<number>\d+)例`)
+	reDailyTotalImportedConfirmedNational                   = regexp.MustCompile(`境外输入现有确诊病例\d+例[^。]*。[^。]*累计确诊病例(?P<number>\d+)例`)
+	reDailyTotalImportedDischargedNational                  = regexp.MustCompile(`境外输入现有确诊病例\d+例[^。]*。[^。]*累计治愈出院病例(?P<number>\d+)例`)
+	reDailyCurrentInHospitalNational                        = regexp.MustCompile(`24时，据[^，]+报告，现有确诊病例(?P<number>\d+)例`)
+	reDailyCurrentSevereNational                            = regexp.MustCompile(`24时，据[^，]+报告，现有确诊病例\d+例（其中重症病例(?P<number>\d+)例）`)
+	reDailyTotalDischargedFromHospitalNational              = regexp.MustCompile(`24时，据[^，]+报告，现有确诊病例[^。]*累计治愈出院病例(?P<number>\d+)例`)
+	reDailyTotalDeathNational                               = regexp.MustCompile(`24时，据[^，]+报告，现有确诊病例[^。]*累计死亡病例(?P<number>\d+)例`)
+	reDailyTotalConfirmedNational                           = regexp.MustCompile(`24时，据[^，]+报告，现有确诊病例[^。]*累计报告确诊病例(?P<number>\d+)例`)
+	reDailyAsymptomaticNational                             = regexp.MustCompile(`报告新增无症状感染者(?P<number>\d+)例`)
+	reDailyImportedAsymptomaticNational                     = regexp.MustCompile(`新增无症状感染者\d+例，其中境外输入(?P<number>\d+)例`)
+	reDailyLocalAsymptomaticNational                        = regexp.MustCompile(`新增无症状感染者\d+例，[^。]*本土(?P<number>\d+)例` + patternProvinceListNational)
+	reDailyDischargedFromMedicalObservationNational         = regexp.MustCompile(`当日解除医学观察(?P<number>\d+)例`)
+	reDailyImportedDischargedFromMedicalObservationNational = regexp.MustCompile(`当日解除医学观察\d+例（境外输入(?P<number>\d+)例）`)
+	reDailyUnderMedicalObservationNational                  = regexp.MustCompile(`尚在医学观察的无症状感染者(?P<number>\d+)例`)
+	reDailyImportedUnderMedicalObservationNational          = regexp.MustCompile(`尚在医学观察的无症状感染者\d+例（境外输入(?P<number>\d+)例）`)
+
+	reDailyProvinceItemNational = regexp.MustCompile(`(?P<province>[^\d，、；（）]+?)(?P<number>\d+)例`)
+)
+
+// 解析 Daily 内容
+func (p DailyParserNational) ParseDailyContent(d *model.Daily, content string) error {
+	if d == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+
+	var current, total_confirmed, total_discharged int
+	fields := []struct {
+		name  string
+		field string // 为空表示只是中间结果
+		re    *regexp.Regexp
+		value *int
+	}{
+		{"确诊病例", "Confirmed", reDailyConfirmedNational, &d.Confirmed},
+		{"境外输入确诊病例", "ImportedConfirmed", reDailyImportedConfirmedNational, &d.ImportedConfirmed},
+		{"本土确诊病例", "LocalConfirmed", reDailyLocalConfirmedNational, &d.LocalConfirmed},
+		{"本土无症状感染者转为确诊病例", "LocalConfirmedFromAsymptomatic", reDailyLocalConfirmedFromAsymptomaticNational, &d.LocalConfirmedFromAsymptomatic},
+		//	通报中的死亡病例（包括累计死亡）都是本土病例
+		{"死亡病例", "LocalDeath", reDailyDeathNational, &d.LocalDeath},
+		{"治愈出院", "DischargedFromHospital", reDailyDischargedFromHospitalNational, &d.DischargedFromHospital},
+		{"境外输入治愈出院", "ImportedDischargedFromHospital", reDailyImportedDischargedFromHospitalNational, &d.ImportedDischargedFromHospital},
+		{"本土治愈出院", "LocalDischargedFromHospital", reDailyLocalDischargedFromHospitalNational, &d.LocalDischargedFromHospital},
+		{"境外输入在院治疗", "CurrentImportedInHospital", reDailyCurrentImportedInHospitalNational, &d.CurrentImportedInHospital},
+		{"累计境外输入确诊", "TotalImportedConfirmed", reDailyTotalImportedConfirmedNational, &d.TotalImportedConfirmed},
+		{"累计境外输入治愈出院", "TotalImportedDischargedFromHospital", reDailyTotalImportedDischargedNational, &d.TotalImportedDischargedFromHospital},
+		{"在院治疗", "", reDailyCurrentInHospitalNational, &current},
+		{"累计治愈出院", "", reDailyTotalDischargedFromHospitalNational, &total_discharged},
+		{"累计死亡", "TotalLocalDeath", reDailyTotalDeathNational, &d.TotalLocalDeath},
+		{"累计确诊", "", reDailyTotalConfirmedNational, &total_confirmed},
+		{"当前重症", "CurrentSevere", reDailyCurrentSevereNational, &d.CurrentSevere},
+		{"无症状感染者", "Asymptomatic", reDailyAsymptomaticNational, &d.Asymptomatic},
+		{"境外输入无症状感染者", "ImportedAsymptomatic", reDailyImportedAsymptomaticNational, &d.ImportedAsymptomatic},
+		{"本土无症状感染者", "LocalAsymptomatic", reDailyLocalAsymptomaticNational, &d.LocalAsymptomatic},
+		{"解除医学观察", "DischargedFromMedicalObservation", reDailyDischargedFromMedicalObservationNational, &d.DischargedFromMedicalObservation},
+		{"境外输入解除医学观察", "ImportedDischargedFromMedicalObservation", reDailyImportedDischargedFromMedicalObservationNational, &d.ImportedDischargedFromMedicalObservation},
+		{"尚在医学观察", "UnderMedicalObservation", reDailyUnderMedicalObservationNational, &d.UnderMedicalObservation},
+		{"境外输入尚在医学观察", "ImportedUnderMedicalObservation", reDailyImportedUnderMedicalObservationNational, &d.ImportedUnderMedicalObservation},
+	}
+	for _, f := range fields {
+		m := f.re.FindStringSubmatch(content)
+		if m == nil {
+			// log.Warnf("[%s] 无法解析文章内容中%s：%q", d.Date.Format("2006-01-02"), f.name, content)
+			continue
+		}
+		n, err := parseCount(m[1])
+		if err != nil {
+			return fmt.Errorf("[%s] 无法解析文章内容中%s：%q", d.Date.Format("2006-01-02"), f.name, m[0])
+		}
+		*f.value = n
+		if len(f.field) > 0 {
+			d.Parsed(f.field, "DailyParserNational."+f.name, m[0])
+		}
+	}
+
+	//	本土 = 总共 - 境外输入
+	if current > 0 {
+		d.CurrentLocalInHospital = current - d.CurrentImportedInHospital
+		d.Derived("CurrentLocalInHospital", "DailyParserNational", fmt.Sprintf("现有确诊病例 %d - CurrentImportedInHospital", current))
+	}
+	if total_confirmed > 0 {
+		d.TotalLocalConfirmed = total_confirmed - d.TotalImportedConfirmed
+		d.Derived("TotalLocalConfirmed", "DailyParserNational", fmt.Sprintf("累计报告确诊病例 %d - TotalImportedConfirmed", total_confirmed))
+	}
+	if total_discharged > 0 {
+		d.TotalLocalDischargedFromHospital = total_discharged - d.TotalImportedDischargedFromHospital
+		d.Derived("TotalLocalDischargedFromHospital", "DailyParserNational", fmt.Sprintf("累计治愈出院病例 %d - TotalImportedDischargedFromHospital", total_discharged))
+	}
+	if d.LocalDischargedFromMedicalObservation == 0 && d.DischargedFromMedicalObservation > 0 {
+		d.LocalDischargedFromMedicalObservation = d.DischargedFromMedicalObservation - d.ImportedDischargedFromMedicalObservation
+		d.Derived("LocalDischargedFromMedicalObservation", "DailyParserNational", "DischargedFromMedicalObservation - ImportedDischargedFromMedicalObservation")
+	}
+	if d.LocalUnderMedicalObservation == 0 && d.UnderMedicalObservation > 0 {
+		d.LocalUnderMedicalObservation = d.UnderMedicalObservation - d.ImportedUnderMedicalObservation
+		d.Derived("LocalUnderMedicalObservation", "DailyParserNational", "UnderMedicalObservation - ImportedUnderMedicalObservation")
+	}
+
+	//	进一步解析各省份信息
+	return p.parseDailyContentProvince(d, content)
+}
+
+func (p DailyParserNational) parseDailyContentProvince(d *model.Daily, content string) error {
+	provinces := []struct {
+		name  string
+		field string
+		re    *regexp.Regexp
+		dict  *map[string]int
+		total int
+	}{
+		{"本土确诊病例", "DistrictConfirmed", reDailyLocalConfirmedNational, &d.DistrictConfirmed, d.LocalConfirmed},
+		{"本土无症状感染者转为确诊病例", "DistrictConfirmedFromAsymptomatic", reDailyLocalConfirmedFromAsymptomaticNational, &d.DistrictConfirmedFromAsymptomatic, d.LocalConfirmedFromAsymptomatic},
+		{"本土无症状感染者", "DistrictAsymptomatic", reDailyLocalAsymptomaticNational, &d.DistrictAsymptomatic, d.LocalAsymptomatic},
+	}
+	for _, pr := range provinces {
+		m := pr.re.FindStringSubmatch(content)
+		if m == nil {
+			continue
+		}
+		text := m[pr.re.SubexpIndex("provinces")]
+		if len(text) == 0 {
+			if pr.total > 0 {
+				log.Warnf("[%s] 无法解析文章内容中各省份%s", d.Date.Format("2006-01-02"), pr.name)
+			}
+			continue
+		}
+		*pr.dict = p.parseProvinceItems(d, text, pr.total)
+		d.Parsed(pr.field, "DailyParserNational."+pr.name, m[0])
+	}
+	return nil
+}
+
+// “上海2573例，吉林326例，北京5例”
+//
+//	“均在上海”这类只有一个省份的写法，该省份的数量即为总数
+func (p DailyParserNational) parseProvinceItems(d *model.Daily, text string, total int) map[string]int {
+	dict := make(map[string]int)
+	if strings.HasPrefix(text, "均在") || strings.HasPrefix(text, "均为") {
+		dict[strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(text, "均在"), "均为"))] = total
+		return dict
+	}
+	for _, it := range reDailyProvinceItemNational.FindAllStringSubmatch(text, -1) {
+		v, err := parseCount(it[2])
+		if err != nil {
+			log.Warnf("[%s] 无法解析省份病例列表：%q", d.Date.Format("2006-01-02"), it)
+			continue
+		}
+		province := strings.TrimSpace(strings.TrimPrefix(it[1], "其中"))
+		dict[province] += v
+	}
+	return dict
+}
+
+func (p DailyParserNational) ParseResidents(rs *model.Residents, date time.Time, content string) error {
+	if rs == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+	return nil
+}
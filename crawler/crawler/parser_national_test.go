@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"crawler/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDailyTitleNational(t *testing.T) {
+	p := DailyParserNational{}
+	title := "截至4月14日24时新型冠状病毒肺炎疫情最新情况"
+
+	var d model.Daily
+	assert.True(t, p.IsValidTitle(title))
+	assert.True(t, p.IsDaily(d.Date, title))
+	assert.False(t, p.IsResidents(d.Date, title))
+	assert.NoError(t, p.ParseDailyTitle(&d, title))
+	assert.Equal(t, s2date("2022-04-14"), d.Date)
+
+	assert.False(t, p.IsValidTitle("国家卫生健康委办公厅关于印发新型冠状病毒肺炎诊疗方案（试行第九版）的通知"))
+}
+
+func TestParseDailyContentNational(t *testing.T) {
+	testcases := []test_case{
+		{
+			"4月14日0—24时，31个省（自治区、直辖市）和新疆生产建设兵团报告新增确诊病例3020例。其中境外输入病例20例（上海7例，广东4例，福建3例，云南2例，天津1例，浙江1例，广西1例，四川1例），含2例由无症状感染者转为确诊病例（上海1例，广东1例）；本土病例3000例（上海2573例，吉林326例，黑龙江22例，浙江17例，广东13例，北京5例，江苏44例），含1979例由无症状感染者转为确诊病例（上海1960例，吉林19例）。新增死亡病例0例。新增疑似病例0例。\n" +
+				"当日新增治愈出院病例1115例，其中境外输入病例47例，本土病例1068例（吉林688例，上海300例，广东80例），解除医学观察的密切接触者43120人，重症病例较前一日增加9例。\n" +
+				"境外输入现有确诊病例489例（无重症病例），现有疑似病例4例。累计确诊病例17210例，累计治愈出院病例16721例，无死亡病例。\n" +
+				"截至4月14日24时，据31个省（自治区、直辖市）和新疆生产建设兵团报告，现有确诊病例27012例（其中重症病例94例），累计治愈出院病例161806例，累计死亡病例4638例，累计报告确诊病例193456例，现有疑似病例10例。累计追踪到密切接触者3366340人，尚在医学观察的密切接触者518612人。\n" +
+				"31个省（自治区、直辖市）和新疆生产建设兵团报告新增无症状感染者25141例，其中境外输入46例，本土25095例（上海19872例，吉林2217例，广东118例，北京2例）。\n" +
+				"当日转为确诊病例1981例（境外输入2例），当日解除医学观察8980例（境外输入40例）；尚在医学观察的无症状感染者278164例（境外输入572例）。",
+			model.Daily{
+				Confirmed:                                3020,
+				ImportedConfirmed:                        20,
+				LocalConfirmed:                           3000,
+				LocalConfirmedFromAsymptomatic:           1979,
+				DischargedFromHospital:                   1115,
+				ImportedDischargedFromHospital:           47,
+				LocalDischargedFromHospital:              1068,
+				CurrentImportedInHospital:                489,
+				TotalImportedConfirmed:                   17210,
+				TotalImportedDischargedFromHospital:      16721,
+				CurrentLocalInHospital:                   26523,
+				CurrentSevere:                            94,
+				TotalLocalConfirmed:                      176246,
+				TotalLocalDischargedFromHospital:         145085,
+				TotalLocalDeath:                          4638,
+				Asymptomatic:                             25141,
+				ImportedAsymptomatic:                     46,
+				LocalAsymptomatic:                        25095,
+				DischargedFromMedicalObservation:         8980,
+				ImportedDischargedFromMedicalObservation: 40,
+				LocalDischargedFromMedicalObservation:    8940,
+				UnderMedicalObservation:                  278164,
+				ImportedUnderMedicalObservation:          572,
+				LocalUnderMedicalObservation:             277592,
+				DistrictConfirmed: map[string]int{
+					"上海":  2573,
+					"吉林":  326,
+					"黑龙江": 22,
+					"浙江":  17,
+					"广东":  13,
+					"北京":  5,
+					"江苏":  44,
+				},
+				DistrictConfirmedFromAsymptomatic: map[string]int{
+					"上海": 1960,
+					"吉林": 19,
+				},
+				DistrictAsymptomatic: map[string]int{
+					"上海": 19872,
+					"吉林": 2217,
+					"广东": 118,
+					"北京": 2,
+				},
+			},
+		},
+		{
+			"5月20日0—24时，31个省（自治区、直辖市）和新疆生产建设兵团报告新增确诊病例84例。其中境外输入病例16例（广东5例，四川3例，福建2例，云南2例，上海1例，浙江1例，山东1例，重庆1例），含2例由无症状感染者转为确诊病例（均在广东）；本土病例68例（北京36例，上海32例），含25例由无症状感染者转为确诊病例（均在上海）。新增死亡病例3例，均为本土病例，均在上海。",
+			model.Daily{
+				Confirmed:                      84,
+				ImportedConfirmed:              16,
+				LocalConfirmed:                 68,
+				LocalConfirmedFromAsymptomatic: 25,
+				LocalDeath:                     3,
+				DistrictConfirmed: map[string]int{
+					"北京": 36,
+					"上海": 32,
+				},
+				DistrictConfirmedFromAsymptomatic: map[string]int{
+					"上海": 25,
+				},
+			},
+		},
+	}
+
+	p := DailyParserNational{}
+	for i, c := range testcases {
+		var d model.Daily
+		err := p.ParseDailyContent(&d, c.content)
+		assert.NoErrorf(t, err, "解析内容失败 (%d) %q => %s", i, c.content, err)
+		assert.EqualValuesf(t, c.daily, d, "匹配内容失败 (%d) '%s'", i, c.content)
+	}
+}
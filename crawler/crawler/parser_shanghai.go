@@ -3,6 +3,7 @@ package crawler
 import (
 	"crawler/model"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -13,6 +14,10 @@ import (
 
 type DailyParserShanghai struct{}
 
+func init() {
+	RegisterCity(City{Key: "shanghai", Name: "上海市", Parser: DailyParserShanghai{}})
+}
+
 //	小标题
 //		section > span > strong:		margin-bottom: 0em;
 //		section [data-brushtype] > span > strong
@@ -108,6 +113,48 @@ func (p DailyParserShanghai) GetDistricts() []string {
 	}
 }
 
+// 解析时用于提取数量的正则（不包括日期和列表项），用于统计解析覆盖率
+func (p DailyParserShanghai) GetExtractors() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		reDailyCritical,
+		reDailyDeathCase,
+		reDailyDischargedFromHospital,
+		reDailyDischargedFromMedicalObservation,
+		reDailyDischargedFromMedicalObservation2,
+		reDailyImportedAsymptomatic,
+		reDailyImportedConfirmed,
+		reDailyImportedDeath,
+		reDailyImportedDischargedFromHospital,
+		reDailyImportedDischargedFromMedicalObservation,
+		reDailyImportedInHospital,
+		reDailyImportedUnderMedicalObservation,
+		reDailyLocalAsymptomatic,
+		reDailyLocalAsymptomaticFromBubble,
+		reDailyLocalConfirmed,
+		reDailyLocalConfirmedFromAsymptomatic,
+		reDailyLocalConfirmedFromBubble,
+		reDailyLocalDeath,
+		reDailyLocalDischargedFromHospital,
+		reDailyLocalDischargedFromMedicalObservation,
+		reDailyLocalInHospital,
+		reDailyLocalUnderMedicalObservation,
+		reDailyRegionAsymptomaticFromBubble,
+		reDailyRegionAsymptomaticFromRisk,
+		reDailyRegionConfirmedFromAsymptomatic,
+		reDailyRegionConfirmedFromBubble,
+		reDailyRegionConfirmedFromRisk,
+		reDailySevere,
+		reDailyTotalImportedConfirmed,
+		reDailyTotalImportedDischargedFromHospital,
+		reDailyTotalLocalConfirmed,
+		reDailyTotalLocalDeath,
+		reDailyTotalLocalDischargedFromHospital,
+		reDailyUnderMedicalObservation,
+		reResidentDistrictShanghai1,
+		reResidentDistrictShanghai2,
+	}
+}
+
 func (p DailyParserShanghai) IsDaily(date time.Time, title string) bool {
 	return !strings.Contains(title, "居住地信息")
 }
@@ -123,6 +170,49 @@ func (p DailyParserShanghai) IsValidTitle(title string) bool {
 	return strings.Contains(title, "本土新冠肺炎") || strings.Contains(title, "居住地信息")
 }
 
+// 风险地区调整通告，见 RiskAreaParser
+func (p DailyParserShanghai) IsRiskAreas(title string) bool {
+	return strings.Contains(title, "风险地区") || strings.Contains(title, "风险区")
+}
+
+func (p DailyParserShanghai) ParseRiskAreas(ns *model.RiskAreaNotices, date time.Time, content string) error {
+	return parseRiskAreas(ns, "上海市", p.GetDistricts(), date, content)
+}
+
+// 封控区、管控区、防范区名单，见 ZoneParser
+func (p DailyParserShanghai) IsZones(title string) bool {
+	return strings.Contains(title, "封控区") && strings.Contains(title, "名单")
+}
+
+func (p DailyParserShanghai) ParseZones(zs *model.Zones, title, content string) error {
+	return parseZones(zs, "上海市", p.GetDistricts(), title, content)
+}
+
+// 新闻发布会实录，见 BriefingParser
+func (p DailyParserShanghai) GetBriefingIndexLinks() []string {
+	const (
+		LINK_BRIEFING string = "https://ss.shanghai.gov.cn/search?q={q}&page={page}&view=xwzx&contentScope=1&dateOrder=2&tr=4&dr=&format=1&re=2&all=1&siteId=wsjkw.sh.gov.cn&siteArea=all"
+		MAX_PAGES     int    = 10
+	)
+
+	links := []string{}
+	for i := 1; i <= MAX_PAGES; i++ {
+		//	全文检索
+		link := strings.ReplaceAll(LINK_BRIEFING, "{q}", url.QueryEscape("疫情防控新闻发布会"))
+		link = strings.ReplaceAll(link, "{page}", strconv.Itoa(i))
+		links = append(links, link)
+	}
+	return links
+}
+
+func (p DailyParserShanghai) IsBriefing(title string) bool {
+	return strings.Contains(title, "新闻发布会")
+}
+
+func (p DailyParserShanghai) ParseBriefing(b *model.Briefing, title, content string) error {
+	return parseBriefing(b, "上海市", title, content)
+}
+
 //	解析 Daily
 
 //	解析 Daily 标题
@@ -162,7 +252,8 @@ func (p DailyParserShanghai) ParseDailyTitle(d *model.Daily, title string) error
 	} else {
 		///	2种情况
 		n := m[1] + m[2]
-		d.LocalConfirmed, err = strconv.Atoi(n)
+		d.Parsed("LocalConfirmed", "reDailyLocalConfirmed", m[0])
+		d.LocalConfirmed, err = parseCount(n)
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章标题中本土新增：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -175,7 +266,8 @@ func (p DailyParserShanghai) ParseDailyTitle(d *model.Daily, title string) error
 	} else {
 		// 有3种情况
 		n := m[1] + m[2] + m[3]
-		d.LocalAsymptomatic, err = strconv.Atoi(n)
+		d.Parsed("LocalAsymptomatic", "reDailyLocalAsymptomatic", m[0])
+		d.LocalAsymptomatic, err = parseCount(n)
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章标题中本土无症状感染者：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -188,7 +280,8 @@ func (p DailyParserShanghai) ParseDailyTitle(d *model.Daily, title string) error
 	} else {
 		//	数值有两个case
 		n := m[1] + m[2]
-		d.ImportedConfirmed, err = strconv.Atoi(n)
+		d.Parsed("ImportedConfirmed", "reDailyImportedConfirmed", m[0])
+		d.ImportedConfirmed, err = parseCount(n)
 		if err != nil {
 			log.Warnf("[%s] 无法解析文章标题中境外输入新增：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -201,7 +294,8 @@ func (p DailyParserShanghai) ParseDailyTitle(d *model.Daily, title string) error
 	} else {
 		/// 正则包含3个可能性，因此有3个数值的匹配，但是只可能有一个有值，因此字符串合并后就是那个有值的值
 		n := m[1] + m[2] + m[3] + m[4]
-		d.ImportedAsymptomatic, err = strconv.Atoi(n)
+		d.Parsed("ImportedAsymptomatic", "reDailyImportedAsymptomatic", m[0])
+		d.ImportedAsymptomatic, err = parseCount(n)
 		if err != nil {
 			log.Warnf("[%s] 无法解析文章标题中境外输入无症状感染者：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -212,7 +306,8 @@ func (p DailyParserShanghai) ParseDailyTitle(d *model.Daily, title string) error
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章标题中治愈出院：%q", d.Date.Format("2006-01-02"), title)
 	} else {
-		d.DischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("DischargedFromHospital", "reDailyDischargedFromHospital", m[0])
+		d.DischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			log.Warnf("[%s] 无法解析文章标题中治愈出院：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -223,7 +318,8 @@ func (p DailyParserShanghai) ParseDailyTitle(d *model.Daily, title string) error
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章标题中解除医学观察：%q", d.Date.Format("2006-01-02"), title)
 	} else {
-		d.DischargedFromMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("DischargedFromMedicalObservation", "reDailyDischargedFromMedicalObservation", m[0])
+		d.DischargedFromMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			log.Warnf("[%s] 无法解析文章标题中解除医学观察：%q", d.Date.Format("2006-01-02"), title)
 		}
@@ -232,6 +328,16 @@ func (p DailyParserShanghai) ParseDailyTitle(d *model.Daily, title string) error
 	return nil
 }
 
+// 修正一些格式上的问题：分区列表常被拆成多行
+func (p DailyParserShanghai) NormalizeContent(content string) string {
+	content = strings.ReplaceAll(content, "区，\n", "区，")
+	content = strings.ReplaceAll(content, "居住于\n", "居住于")
+	content = strings.ReplaceAll(content, "无症状\n", "无症状")
+	content = strings.ReplaceAll(content, "无症状感染\n", "无症状感染")
+	content = strings.ReplaceAll(content, "无症状感染者\n", "无症状感染者")
+	return content
+}
+
 // 解析 Daily 内容
 func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) error {
 	if d == nil {
@@ -240,12 +346,7 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 
 	var m []string
 	var err error
-	// 修正一些格式上的问题
-	content = strings.ReplaceAll(content, "区，\n", "区，")
-	content = strings.ReplaceAll(content, "居住于\n", "居住于")
-	content = strings.ReplaceAll(content, "无症状\n", "无症状")
-	content = strings.ReplaceAll(content, "无症状感染\n", "无症状感染")
-	content = strings.ReplaceAll(content, "无症状感染者\n", "无症状感染者")
+	content = p.NormalizeContent(content)
 
 	// 日期 (补充标题缺失)
 	if time.Time.IsZero(d.Date) {
@@ -270,7 +371,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 		} else {
 			///	2种情况
 			n := m[1] + m[2]
-			d.LocalConfirmed, err = strconv.Atoi(n)
+			d.Parsed("LocalConfirmed", "reDailyLocalConfirmed", m[0])
+			d.LocalConfirmed, err = parseCount(n)
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中本土新增：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -285,7 +387,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 		} else {
 			// 有3种情况
 			n := m[1] + m[2] + m[3]
-			d.LocalAsymptomatic, err = strconv.Atoi(n)
+			d.Parsed("LocalAsymptomatic", "reDailyLocalAsymptomatic", m[0])
+			d.LocalAsymptomatic, err = parseCount(n)
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中本土无症状：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -300,7 +403,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 		} else {
 			//	数值有两个case
 			n := m[1] + m[2]
-			d.ImportedConfirmed, err = strconv.Atoi(n)
+			d.Parsed("ImportedConfirmed", "reDailyImportedConfirmed", m[0])
+			d.ImportedConfirmed, err = parseCount(n)
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中境外输入确诊：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -313,7 +417,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 		if m == nil {
 			// log.Warnf("[%s] 无法解析文章内容中境外输入无症状：%q", d.Date.Format("2006-01-02"), content)
 		} else {
-			d.ImportedAsymptomatic, err = strconv.Atoi(m[1])
+			d.Parsed("ImportedAsymptomatic", "reDailyImportedAsymptomatic", m[0])
+			d.ImportedAsymptomatic, err = parseCount(m[1])
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中境外输入无症状：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -325,7 +430,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土无症状转为确诊病例：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalConfirmedFromAsymptomatic, err = strconv.Atoi(m[1])
+		d.Parsed("LocalConfirmedFromAsymptomatic", "reDailyLocalConfirmedFromAsymptomatic", m[0])
+		d.LocalConfirmedFromAsymptomatic, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土无症状转为确诊病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -336,7 +442,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土隔离管控中发现的确诊病例：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalConfirmedFromBubble, err = strconv.Atoi(m[1])
+		d.Parsed("LocalConfirmedFromBubble", "reDailyLocalConfirmedFromBubble", m[0])
+		d.LocalConfirmedFromBubble, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土隔离管控中发现的确诊病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -347,7 +454,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土隔离管控中发现的无症状病例：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalAsymptomaticFromBubble, err = strconv.Atoi(m[1])
+		d.Parsed("LocalAsymptomaticFromBubble", "reDailyLocalAsymptomaticFromBubble", m[0])
+		d.LocalAsymptomaticFromBubble, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土隔离管控中发现的无症状病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -359,7 +467,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 		if m == nil {
 			// log.Warnf("[%s] 无法解析文章标题中治愈出院：%q", d.Date.Format("2006-01-02"), content)
 		} else {
-			d.DischargedFromHospital, err = strconv.Atoi(m[1])
+			d.Parsed("DischargedFromHospital", "reDailyDischargedFromHospital", m[0])
+			d.DischargedFromHospital, err = parseCount(m[1])
 			if err != nil {
 				log.Warnf("[%s] 无法解析文章内容中治愈出院：%q", d.Date.Format("2006-01-02"), content)
 			}
@@ -371,7 +480,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土治愈出院：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalDischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("LocalDischargedFromHospital", "reDailyLocalDischargedFromHospital", m[0])
+		d.LocalDischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土治愈出院：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -382,7 +492,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入治愈出院：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.ImportedDischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("ImportedDischargedFromHospital", "reDailyImportedDischargedFromHospital", m[0])
+		d.ImportedDischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入治愈出院：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -394,7 +505,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 		if m == nil {
 			// log.Warnf("[%s] 无法解析文章内容中解除医学观察：%q", d.Date.Format("2006-01-02"), content)
 		} else {
-			d.DischargedFromMedicalObservation, err = strconv.Atoi(m[1])
+			d.Parsed("DischargedFromMedicalObservation", "reDailyDischargedFromMedicalObservation2", m[0])
+			d.DischargedFromMedicalObservation, err = parseCount(m[1])
 			if err != nil {
 				return fmt.Errorf("[%s] 无法解析文章内容中解除医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 			}
@@ -406,7 +518,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土解除医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalDischargedFromMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("LocalDischargedFromMedicalObservation", "reDailyLocalDischargedFromMedicalObservation", m[0])
+		d.LocalDischargedFromMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土解除医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -417,7 +530,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入解除医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.ImportedDischargedFromMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("ImportedDischargedFromMedicalObservation", "reDailyImportedDischargedFromMedicalObservation", m[0])
+		d.ImportedDischargedFromMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入解除医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -428,7 +542,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土死亡病例：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalDeath, err = strconv.Atoi(m[1])
+		d.Parsed("LocalDeath", "reDailyLocalDeath", m[0])
+		d.LocalDeath, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土死亡病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -439,18 +554,23 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入死亡病例：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.ImportedDeath, err = strconv.Atoi(m[1])
+		d.Parsed("ImportedDeath", "reDailyImportedDeath", m[0])
+		d.ImportedDeath, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入死亡病例：%q", d.Date.Format("2006-01-02"), m[1])
 		}
 	}
 
+	// 死亡病例详情
+	d.DeathCases = parseDeathCases(d, "上海市", content)
+
 	// 累计本土确诊
 	m = reDailyTotalLocalConfirmed.FindStringSubmatch(content)
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计本土确诊：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalLocalConfirmed, err = strconv.Atoi(m[1])
+		d.Parsed("TotalLocalConfirmed", "reDailyTotalLocalConfirmed", m[0])
+		d.TotalLocalConfirmed, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计本土确诊：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -461,7 +581,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计本土治愈出院：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalLocalDischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("TotalLocalDischargedFromHospital", "reDailyTotalLocalDischargedFromHospital", m[0])
+		d.TotalLocalDischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计本土治愈出院：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -472,7 +593,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土在院治疗：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.CurrentLocalInHospital, err = strconv.Atoi(m[1])
+		d.Parsed("CurrentLocalInHospital", "reDailyLocalInHospital", m[0])
+		d.CurrentLocalInHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土在院治疗：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -483,7 +605,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计境外输入确诊：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalImportedConfirmed, err = strconv.Atoi(m[1])
+		d.Parsed("TotalImportedConfirmed", "reDailyTotalImportedConfirmed", m[0])
+		d.TotalImportedConfirmed, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计境外输入确诊：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -494,7 +617,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计境外输入治愈出院：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalImportedDischargedFromHospital, err = strconv.Atoi(m[1])
+		d.Parsed("TotalImportedDischargedFromHospital", "reDailyTotalImportedDischargedFromHospital", m[0])
+		d.TotalImportedDischargedFromHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计境外输入治愈出院：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -505,7 +629,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入在院治疗：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.CurrentImportedInHospital, err = strconv.Atoi(m[1])
+		d.Parsed("CurrentImportedInHospital", "reDailyImportedInHospital", m[0])
+		d.CurrentImportedInHospital, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入在院治疗：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -516,7 +641,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中累计本土死亡：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.TotalLocalDeath, err = strconv.Atoi(m[1])
+		d.Parsed("TotalLocalDeath", "reDailyTotalLocalDeath", m[0])
+		d.TotalLocalDeath, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中累计本土死亡：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -527,7 +653,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中当前重型：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.CurrentSevere, err = strconv.Atoi(m[1])
+		d.Parsed("CurrentSevere", "reDailySevere", m[0])
+		d.CurrentSevere, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中当前重型：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -538,7 +665,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中当前危重型：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.CurrentCritical, err = strconv.Atoi(m[1])
+		d.Parsed("CurrentCritical", "reDailyCritical", m[0])
+		d.CurrentCritical, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中当前危重型：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -549,7 +677,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中尚在医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.UnderMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("UnderMedicalObservation", "reDailyUnderMedicalObservation", m[0])
+		d.UnderMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中尚在医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -560,7 +689,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中本土尚在医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.LocalUnderMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("LocalUnderMedicalObservation", "reDailyLocalUnderMedicalObservation", m[0])
+		d.LocalUnderMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中本土尚在医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -571,7 +701,8 @@ func (p DailyParserShanghai) ParseDailyContent(d *model.Daily, content string) e
 	if m == nil {
 		// log.Warnf("[%s] 无法解析文章内容中境外输入尚在医学观察：%q", d.Date.Format("2006-01-02"), content)
 	} else {
-		d.ImportedUnderMedicalObservation, err = strconv.Atoi(m[1])
+		d.Parsed("ImportedUnderMedicalObservation", "reDailyImportedUnderMedicalObservation", m[0])
+		d.ImportedUnderMedicalObservation, err = parseCount(m[1])
 		if err != nil {
 			return fmt.Errorf("[%s] 无法解析文章内容中境外输入尚在医学观察：%q", d.Date.Format("2006-01-02"), m[1])
 		}
@@ -593,6 +724,7 @@ func (p DailyParserShanghai) parseDailyContentRegion(d *model.Daily, content str
 		}
 	} else {
 		d.DistrictConfirmedFromBubble = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictConfirmedFromBubble", "reDailyRegionConfirmedFromBubble", mm[0][0])
 		// log.Warnf("[%s] 城区确诊病例(来自隔离管控): %#v", cs.Date.Format("2006-01-02"), cs.DistrictConfirmedFromBubble)
 	}
 	//	风险人群 => 确诊病例
@@ -603,6 +735,7 @@ func (p DailyParserShanghai) parseDailyContentRegion(d *model.Daily, content str
 		// }
 	} else {
 		d.DistrictConfirmedFromRisk = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictConfirmedFromRisk", "reDailyRegionConfirmedFromRisk", mm[0][0])
 		// log.Warnf("[%s] 城区确诊病例(来自风险人群): %#v", cs.Date.Format("2006-01-02"), cs.DistrictConfirmedFromRisk)
 	}
 	//	无症状 => 确诊病例
@@ -613,6 +746,7 @@ func (p DailyParserShanghai) parseDailyContentRegion(d *model.Daily, content str
 		// }
 	} else {
 		d.DistrictConfirmedFromAsymptomatic = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictConfirmedFromAsymptomatic", "reDailyRegionConfirmedFromAsymptomatic", mm[0][0])
 		// log.Warnf("[%s] 城区确诊病例(来自无症状感染者): %#v", cs.Date.Format("2006-01-02"), cs.DistrictConfirmedFromAsymptomatic)
 	}
 	//	隔离管控 => 无症状
@@ -623,6 +757,7 @@ func (p DailyParserShanghai) parseDailyContentRegion(d *model.Daily, content str
 		// }
 	} else {
 		d.DistrictAsymptomaticFromBubble = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictAsymptomaticFromBubble", "reDailyRegionAsymptomaticFromBubble", mm[0][0])
 		// log.Warnf("[%s] 城区无症状感染者(来自隔离管控): %#v", cs.Date.Format("2006-01-02"), cs.DistrictAsymptomaticFromBubble)
 	}
 	//	风险人群 => 无症状
@@ -633,6 +768,7 @@ func (p DailyParserShanghai) parseDailyContentRegion(d *model.Daily, content str
 		// }
 	} else {
 		d.DistrictAsymptomaticFromRisk = p.parseDailyContentRegionItems(d, mm)
+		d.Parsed("DistrictAsymptomaticFromRisk", "reDailyRegionAsymptomaticFromRisk", mm[0][0])
 		// log.Warnf("[%s] 城区无症状感染者(来自风险人群): %#v", cs.Date.Format("2006-01-02"), cs.DistrictAsymptomaticFromRisk)
 	}
 
@@ -719,6 +855,7 @@ func (p DailyParserShanghai) parseDailyContentRegionItem(d *model.Daily, text st
 var (
 	reResidentDistrictShanghai1 = regexp.MustCompile(`(?:\n)(?P<district>[^\d\n：]+区)(?:\n[^\n]+(?:(?:\n分别)?居住于[^\n]?|）))*(?P<addrs>(?:\n[^\n2已][^\n]+[，。、]?)+)?`)
 	reResidentDistrictShanghai2 = regexp.MustCompile(`(?P<type>病例|无症状感染者)(?P<number>\d+)，(?P<gender>男|女)，(?P<age>\d+月?)[岁龄]，(?:[^，]+，)?居住(?:于|地为)(?P<district>[^，。]+区)?(?P<addr>[^，。]+)`)
+	reResidentStreetShanghai    = regexp.MustCompile(`^\s*(?P<street>[^\d\s，、：:]{1,8}?(?:街道|地区|镇|乡))[：:]\s*(?P<addrs>.*)$`)
 )
 
 func (p DailyParserShanghai) ParseResidents(rs *model.Residents, date time.Time, content string) error {
@@ -777,6 +914,7 @@ func (p DailyParserShanghai) ParseResidents(rs *model.Residents, date time.Time,
 					Age:      age,
 					City:     "上海市",
 					District: strings.TrimSpace(m[5]),
+					Street:   parseStreet(m[6]),
 					Address:  strings.TrimSpace(m[6]),
 				}
 				// log.Tracef("[%s] %v", date.Format("2006-01-02"), r)
@@ -792,26 +930,39 @@ func (p DailyParserShanghai) ParseResidents(rs *model.Residents, date time.Time,
 
 			d := m[1]
 
-			s := m[2]
-			for _, c := range "\n，。、 " {
-				s = strings.ReplaceAll(s, string(c), ",")
-			}
-			s = strings.ReplaceAll(s, ",,", ",")
-			s = strings.ReplaceAll(s, ",,", ",")
-			s = strings.Trim(s, ", ")
-
-			addrs := strings.Split(s, ",")
-			// log.Tracef("[%s] > %q => (%d) %#v\n", date.Format("2006-01-02"), d, len(addrs), addrs)
-			for _, addr := range addrs {
-				if len(addr) > 0 {
-					r := model.Resident{
-						Date:     date,
-						Name:     fmt.Sprintf("%s%s", d, addr),
-						City:     "上海市",
-						District: d,
-						Address:  addr,
+			//	部分时期按街道、乡镇分组，如“高桥镇：”单独一行或位于行首，之后的地址都属于该街道
+			street := ""
+			for _, line := range strings.Split(m[2], "\n") {
+				if h := reResidentStreetShanghai.FindStringSubmatch(line); h != nil {
+					street = h[1]
+					line = h[2]
+				}
+
+				s := line
+				for _, c := range "，。、 " {
+					s = strings.ReplaceAll(s, string(c), ",")
+				}
+				s = strings.ReplaceAll(s, ",,", ",")
+				s = strings.ReplaceAll(s, ",,", ",")
+				s = strings.Trim(s, ", ")
+
+				addrs := strings.Split(s, ",")
+				// log.Tracef("[%s] > %q => (%d) %#v\n", date.Format("2006-01-02"), d, len(addrs), addrs)
+				for _, addr := range addrs {
+					if len(addr) > 0 {
+						r := model.Resident{
+							Date:     date,
+							Name:     fmt.Sprintf("%s%s", d, addr),
+							City:     "上海市",
+							District: d,
+							Street:   parseStreet(addr),
+							Address:  addr,
+						}
+						if len(r.Street) == 0 {
+							r.Street = street
+						}
+						*rs = append(*rs, r)
 					}
-					*rs = append(*rs, r)
 				}
 			}
 		}
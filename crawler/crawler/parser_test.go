@@ -597,3 +597,22 @@ func TestRegexpResidentDistrict2(t *testing.T) {
 		}
 	}
 }
+
+func TestParseResidentsShanghai_Street(t *testing.T) {
+	content := "\n浦东新区\n2022年4月21日，浦东新区新增10例本土确诊病例，新增20例本土无症状感染者，分别居住于：\n高桥镇：\n凌桥路100弄，\n花木街道：东绣路1号、锦绣路2号，\n祝桥镇邓一村，\n已对相关居住地落实消毒等措施。"
+	var rs model.Residents
+	assert.NoError(t, DailyParserShanghai{}.ParseResidents(&rs, s2date("2022-04-21"), content))
+
+	type item struct{ street, address string }
+	var got []item
+	for _, r := range rs {
+		assert.Equal(t, "浦东新区", r.District)
+		got = append(got, item{r.Street, r.Address})
+	}
+	assert.Equal(t, []item{
+		{"高桥镇", "凌桥路100弄"},
+		{"花木街道", "东绣路1号"},
+		{"花木街道", "锦绣路2号"},
+		{"祝桥镇", "祝桥镇邓一村"},
+	}, got)
+}
@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"crawler/model"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileDailys(t *testing.T) {
+	left := model.Dailys{
+		{Date: s2date("2022-04-24"), LocalConfirmed: 2472, LocalAsymptomatic: 16983, ImportedConfirmed: 1, Source: "http://left/0424",
+			DistrictPositive: map[string]int{"浦东新区": 6181, "黄浦区": 1755}},
+		{Date: s2date("2022-04-25"), LocalConfirmed: 1661, Source: "http://left/0425"},
+	}
+	right := model.Dailys{
+		{Date: s2date("2022-04-24"), LocalConfirmed: 2472, LocalAsymptomatic: 16980, Source: "http://right/0424",
+			DistrictPositive: map[string]int{"浦东新区": 6180, "黄浦区": 1755, "徐汇区": 1396}},
+		{Date: s2date("2022-04-26"), LocalConfirmed: 1606, Source: "http://right/0426"},
+	}
+
+	cs := model.ReconcileDailys(left, right, nil, false)
+	assert.Equal(t, model.Conflicts{
+		{Key: "2022-04-24", Field: "DistrictPositive[徐汇区]", Left: "0", Right: "1396", LeftSource: "http://left/0424", RightSource: "http://right/0424"},
+		{Key: "2022-04-24", Field: "DistrictPositive[浦东新区]", Left: "6181", Right: "6180", LeftSource: "http://left/0424", RightSource: "http://right/0424"},
+		{Key: "2022-04-24", Field: "ImportedConfirmed", Left: "1", Right: "0", LeftSource: "http://left/0424", RightSource: "http://right/0424"},
+		{Key: "2022-04-24", Field: "LocalAsymptomatic", Left: "16983", Right: "16980", LeftSource: "http://left/0424", RightSource: "http://right/0424"},
+		//	只有一方有数据的日期
+		{Key: "2022-04-25", Field: model.CONFLICT_FIELD_DATE, Left: model.CONFLICT_PRESENT, Right: model.CONFLICT_MISSING, LeftSource: "http://left/0425"},
+		{Key: "2022-04-26", Field: model.CONFLICT_FIELD_DATE, Left: model.CONFLICT_MISSING, Right: model.CONFLICT_PRESENT, RightSource: "http://right/0426"},
+	}, cs)
+
+	//	任一方为 0 时不视为冲突
+	cs = model.ReconcileDailys(left, right, nil, true)
+	var fields []string
+	for _, c := range cs {
+		fields = append(fields, c.Key+" "+c.Field)
+	}
+	assert.Equal(t, []string{
+		"2022-04-24 DistrictPositive[浦东新区]",
+		"2022-04-24 LocalAsymptomatic",
+		"2022-04-25 Date",
+		"2022-04-26 Date",
+	}, fields)
+
+	//	只对比指定字段
+	cs = model.ReconcileDailys(left, right, []string{"DistrictPositive"}, false)
+	fields = nil
+	for _, c := range cs {
+		fields = append(fields, c.Key+" "+c.Field)
+	}
+	assert.Equal(t, []string{
+		"2022-04-24 DistrictPositive[徐汇区]",
+		"2022-04-24 DistrictPositive[浦东新区]",
+		"2022-04-25 Date",
+		"2022-04-26 Date",
+	}, fields)
+
+	//	输出
+	dir := t.TempDir()
+	if assert.NoError(t, cs.SaveToCSV(path.Join(dir, "reconcile.csv"))) {
+		b, err := os.ReadFile(path.Join(dir, "reconcile.csv"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(b), "日期,字段,左侧,右侧,左侧来源,右侧来源")
+		assert.Contains(t, string(b), "2022-04-24,DistrictPositive[浦东新区],6181,6180,http://left/0424,http://right/0424")
+		assert.Contains(t, string(b), "2022-04-26,Date,缺失,有数据,,http://right/0426")
+	}
+	if assert.NoError(t, cs.SaveToMarkdown(path.Join(dir, "reconcile.md"))) {
+		b, err := os.ReadFile(path.Join(dir, "reconcile.md"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(b), "共 4 处不一致。")
+		assert.Contains(t, string(b), "## 2022-04-24\n\n- 左侧来源：http://left/0424\n- 右侧来源：http://right/0424")
+		assert.Contains(t, string(b), "| DistrictPositive[浦东新区] | 6181 | 6180 |")
+		assert.Contains(t, string(b), "| Date | 缺失 | 有数据 |")
+	}
+}
+
+func TestDailys_Province(t *testing.T) {
+	national := model.Dailys{
+		{Date: s2date("2022-04-14"), Source: "http://national/0414",
+			DistrictConfirmed:                 map[string]int{"上海": 2573, "吉林": 326},
+			DistrictAsymptomatic:              map[string]int{"上海": 19872},
+			DistrictConfirmedFromAsymptomatic: map[string]int{"上海": 1189}},
+	}
+	ps := national.Province("上海")
+	if assert.Len(t, ps, 1) {
+		assert.Equal(t, model.Daily{
+			Date:                           s2date("2022-04-14"),
+			LocalConfirmed:                 2573,
+			LocalAsymptomatic:              19872,
+			LocalConfirmedFromAsymptomatic: 1189,
+			LocalPositive:                  22445,
+			Source:                         "http://national/0414",
+		}, ps[0])
+	}
+
+	//	没有该省份时为 0
+	ps = national.Province("北京")
+	if assert.Len(t, ps, 1) {
+		assert.Zero(t, ps[0].LocalConfirmed)
+		assert.Zero(t, ps[0].LocalPositive)
+	}
+}
@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 中国标准时间，不依赖系统时区数据库
+var TIMEZONE_CHINA = time.FixedZone("CST", 8*60*60)
+
+// 城市及其每日通报解析器
+//
+//	新增城市只需在解析器所在文件的 init() 中调用 RegisterCity()
+type City struct {
+	Key       string         // 命令行中使用的城市名，如 shanghai
+	Name      string         // 显示名称，如 上海市
+	Districts []string       // 分区列表，决定 CSV 输出中的分区列
+	TimeZone  *time.Location // 通报所使用的时区，通报中的日期均为该时区的日期
+	Parser    DailyParser
+}
+
+var (
+	lockCities sync.RWMutex
+	cities     = make(map[string]City)
+)
+
+func RegisterCity(c City) {
+	registerCity(c, false)
+}
+
+func registerCity(c City, override bool) {
+	if len(c.Key) == 0 || c.Parser == nil {
+		panic(fmt.Sprintf("RegisterCity(): 城市名或解析器为空：%#v", c))
+	}
+	if c.TimeZone == nil {
+		c.TimeZone = TIMEZONE_CHINA
+	}
+	if c.Districts == nil {
+		c.Districts = c.Parser.GetDistricts()
+	}
+
+	lockCities.Lock()
+	defer lockCities.Unlock()
+	if _, ok := cities[c.Key]; ok && !override {
+		panic(fmt.Sprintf("RegisterCity(): 城市 %q 重复注册", c.Key))
+	}
+	cities[c.Key] = c
+}
+
+func GetCity(key string) (City, error) {
+	lockCities.RLock()
+	defer lockCities.RUnlock()
+	if c, ok := cities[key]; ok {
+		return c, nil
+	}
+	keys := make([]string, 0, len(cities))
+	for k := range cities {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return City{}, fmt.Errorf("未知城市 %q，可选城市：%s", key, strings.Join(keys, ", "))
+}
+
+// 所有已注册的城市，按城市名排序
+func Cities() []City {
+	lockCities.RLock()
+	defer lockCities.RUnlock()
+	cs := make([]City, 0, len(cities))
+	for _, c := range cities {
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		return cs[i].Key < cs[j].Key
+	})
+	return cs
+}
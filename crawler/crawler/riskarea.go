@@ -0,0 +1,111 @@
+package crawler
+
+import (
+	"crawler/model"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//	中高风险地区
+//
+//	风险地区调整通告的写法基本一致，如：
+//		自2022年5月5日15时起，将朝阳区潘家园街道松榆西里社区、劲松街道农光里列为中风险地区；
+//		将丰台区右安门街道翠林一里调整为低风险地区。
+//	每个分句为一组地区及其风险等级，地区之间以顿号分隔，省略区名时沿用前一个地区的区。
+
+// 可以解析风险地区调整通告的解析器
+type RiskAreaParser interface {
+	IsRiskAreas(title string) bool
+	ParseRiskAreas(ns *model.RiskAreaNotices, date time.Time, content string) error
+}
+
+var (
+	reRiskAreaDate   = regexp.MustCompile(`自(?P<date>(?:\d+年)?\d+月\d+日)(?:\d+时)?起`)
+	reRiskAreaClause = regexp.MustCompile(`将(?P<areas>[^；。]+?)(?:由[高中]风险(?:地区|区))?(?:列为|划定为|划为|调整为|升级为|降为|降级为)(?P<level>高|中|低)风险(?:地区|区)`)
+)
+
+// 解析风险地区调整通告，date 为通告日期，内容中有“自某月某日起”时以其为准，
+// 有多个时每个分句取其前最近的一个
+func parseRiskAreas(ns *model.RiskAreaNotices, city string, districts []string, date time.Time, content string) error {
+	if ns == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+
+	//	生效日期，一篇通告中可能有多个“自某月某日起”，各自作用于其后的分句
+	type effective struct {
+		pos  int
+		date time.Time
+	}
+	var dates []effective
+	for _, m := range reRiskAreaDate.FindAllStringSubmatchIndex(content, -1) {
+		d, err := parseRiskAreaDate(content[m[2]:m[3]], date)
+		if err != nil {
+			return fmt.Errorf("无法解析风险地区的生效日期：%q", content[m[0]:m[1]])
+		}
+		dates = append(dates, effective{m[0], d})
+	}
+	if len(dates) > 0 {
+		date = dates[0].date
+	}
+	if date.IsZero() {
+		return fmt.Errorf("风险地区通告中没有生效日期")
+	}
+
+	mm := reRiskAreaClause.FindAllStringSubmatchIndex(content, -1)
+	if len(mm) == 0 {
+		return fmt.Errorf("[%s] 无法解析风险地区通告内容", date.Format("2006-01-02"))
+	}
+	for _, m := range mm {
+		//	分句之前最近的生效日期，分句之前没有时取第一个生效日期
+		clauseDate := date
+		for _, e := range dates {
+			if e.pos < m[0] {
+				clauseDate = e.date
+			}
+		}
+		level := model.RiskLevel(content[m[4]:m[5]] + "风险")
+		district := ""
+		for _, area := range strings.FieldsFunc(content[m[2]:m[3]], func(r rune) bool { return r == '、' || r == '，' || r == '\n' }) {
+			area = strings.TrimSpace(area)
+			if d := matchDistrict(districts, area); len(d) > 0 {
+				district = d
+				area = strings.TrimPrefix(area, d)
+			}
+			if len(area) == 0 || len(district) == 0 {
+				continue
+			}
+			*ns = append(*ns, model.RiskAreaNotice{
+				Date:     clauseDate,
+				City:     city,
+				District: district,
+				Name:     area,
+				Level:    level,
+			})
+		}
+	}
+	return nil
+}
+
+// 解析“某年某月某日”，省略年份时取通告日期的年份
+func parseRiskAreaDate(s string, date time.Time) (time.Time, error) {
+	if !strings.Contains(s, "年") {
+		year := 2022
+		if !date.IsZero() {
+			year = date.Year()
+		}
+		s = fmt.Sprintf("%d年%s", year, s)
+	}
+	return time.Parse("2006年1月2日", s)
+}
+
+// 文本开头的区名，没有时返回空字符串
+func matchDistrict(districts []string, text string) string {
+	for _, d := range districts {
+		if strings.HasPrefix(text, d) {
+			return d
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package crawler
+
+import (
+	"crawler/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRiskAreas(t *testing.T) {
+	content := "根据国务院联防联控机制有关规定，经研究决定，自2022年5月5日15时起，将朝阳区潘家园街道松榆西里社区、劲松街道农光里、东城区东花市街道列为中风险地区；" +
+		"将丰台区右安门街道翠林一里由中风险地区调整为低风险地区。其他地区风险等级不变。"
+
+	var ns model.RiskAreaNotices
+	assert.NoError(t, DailyParserBeijing{}.ParseRiskAreas(&ns, s2date("2022-05-05"), content))
+
+	type item struct {
+		district, name string
+		level          model.RiskLevel
+	}
+	var got []item
+	for _, n := range ns {
+		assert.Equal(t, s2date("2022-05-05"), n.Date)
+		assert.Equal(t, "北京市", n.City)
+		got = append(got, item{n.District, n.Name, n.Level})
+	}
+	assert.Equal(t, []item{
+		{"朝阳区", "潘家园街道松榆西里社区", model.RiskMedium},
+		{"朝阳区", "劲松街道农光里", model.RiskMedium},
+		{"东城区", "东花市街道", model.RiskMedium},
+		{"丰台区", "右安门街道翠林一里", model.RiskLow},
+	}, got)
+}
+
+func TestParseRiskAreas_DateWithoutYear(t *testing.T) {
+	var ns model.RiskAreaNotices
+	err := DailyParserShanghai{}.ParseRiskAreas(&ns, s2date("2022-03-10"), "自3月11日起，将闵行区虹桥镇虹桥新城列为中风险地区。")
+	assert.NoError(t, err)
+	if assert.Len(t, ns, 1) {
+		assert.Equal(t, s2date("2022-03-11"), ns[0].Date)
+		assert.Equal(t, "闵行区", ns[0].District)
+		assert.Equal(t, "虹桥镇虹桥新城", ns[0].Name)
+	}
+
+	assert.Error(t, DailyParserShanghai{}.ParseRiskAreas(&ns, s2date("2022-03-10"), "本市各区风险等级不变。"))
+}
+
+func TestRiskAreaNotices_Periods(t *testing.T) {
+	n := func(date, name string, level model.RiskLevel) model.RiskAreaNotice {
+		return model.RiskAreaNotice{Date: s2date(date), City: "北京市", District: "朝阳区", Name: name, Level: level}
+	}
+	ns := model.RiskAreaNotices{
+		n("2022-05-01", "甲社区", model.RiskMedium),
+		n("2022-05-03", "甲社区", model.RiskHigh),
+		n("2022-05-03", "甲社区", model.RiskHigh),
+		n("2022-05-10", "甲社区", model.RiskLow),
+		n("2022-05-02", "乙社区", model.RiskMedium),
+	}
+	as := ns.Periods()
+	assert.Equal(t, []string{
+		"[2022-05-03 ~ 2022-05-10] 北京市朝阳区甲社区: 高风险",
+		"[2022-05-02 ~ 至今] 北京市朝阳区乙社区: 中风险",
+		"[2022-05-01 ~ 2022-05-03] 北京市朝阳区甲社区: 中风险",
+	}, riskAreaStrings(as))
+
+	//	已有的时期与新的通告合并
+	merged := as.Merge(model.RiskAreaNotices{n("2022-05-12", "乙社区", model.RiskLow)})
+	assert.Equal(t, []string{
+		"[2022-05-03 ~ 2022-05-10] 北京市朝阳区甲社区: 高风险",
+		"[2022-05-02 ~ 2022-05-12] 北京市朝阳区乙社区: 中风险",
+		"[2022-05-01 ~ 2022-05-03] 北京市朝阳区甲社区: 中风险",
+	}, riskAreaStrings(merged))
+	assert.True(t, merged[1].ActiveOn(s2date("2022-05-11")))
+	assert.False(t, merged[1].ActiveOn(s2date("2022-05-12")))
+}
+
+func riskAreaStrings(as model.RiskAreas) []string {
+	var ss []string
+	for _, a := range as {
+		ss = append(ss, a.String())
+	}
+	return ss
+}
+
+func TestParseRiskAreas_MultipleDates(t *testing.T) {
+	//	同一篇通告分段公布不同时间生效的调整
+	content := "自2022年5月5日15时起，将朝阳区潘家园街道松榆西里社区列为中风险地区。\n" +
+		"自2022年5月6日0时起，将丰台区右安门街道翠林一里由中风险地区调整为低风险地区；将朝阳区劲松街道农光里列为高风险地区。"
+
+	var ns model.RiskAreaNotices
+	assert.NoError(t, DailyParserBeijing{}.ParseRiskAreas(&ns, s2date("2022-05-05"), content))
+
+	type item struct {
+		date, name string
+		level      model.RiskLevel
+	}
+	var got []item
+	for _, n := range ns {
+		got = append(got, item{n.Date.Format("2006-01-02"), n.Name, n.Level})
+	}
+	assert.Equal(t, []item{
+		{"2022-05-05", "潘家园街道松榆西里社区", model.RiskMedium},
+		{"2022-05-06", "右安门街道翠林一里", model.RiskLow},
+		{"2022-05-06", "劲松街道农光里", model.RiskHigh},
+	}, got)
+}
@@ -0,0 +1,41 @@
+package crawler
+
+import (
+	"crawler/model"
+	"regexp"
+	"strings"
+)
+
+//	街道/乡镇
+//
+//	北京、广州的病例居住地一般以街道或乡镇开头，如“永顺镇馨通家园”、“嘉禾街道望岗村”；
+//	上海的居住地信息在部分时期按街道、乡镇分组列出。
+
+var (
+	reStreet = regexp.MustCompile(`^(?P<street>[^\d\s，、：:；;。（）()]{1,8}?(?:街道|地区|镇|乡))`)
+	//	街道、乡镇后面紧跟这些词时，是小区名称的一部分，如“金色家乡小区”、“古镇花园”
+	reStreetFalse = regexp.MustCompile(`^(?:小区|花园|家园|公寓|新村|苑|里|路|街|大厦|广场|村委)`)
+)
+
+// 居住地开头的街道/乡镇，没有时返回空字符串
+func parseStreet(address string) string {
+	address = strings.TrimSpace(address)
+	m := reStreet.FindStringSubmatch(address)
+	if m == nil {
+		return ""
+	}
+	street := m[reStreet.SubexpIndex("street")]
+	if reStreetFalse.MatchString(address[len(street):]) {
+		return ""
+	}
+	return street
+}
+
+// 为没有街道的居住地信息从居住地开头识别街道，用于升级此前保存的数据
+func FillStreets(rs model.Residents) {
+	for i := range rs {
+		if len(rs[i].Street) == 0 {
+			rs[i].Street = parseStreet(rs[i].Address)
+		}
+	}
+}
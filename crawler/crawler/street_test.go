@@ -0,0 +1,31 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStreet(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"永顺镇馨通家园", "永顺镇"},
+		{"于家务乡于家务西里小区", "于家务乡"},
+		{"北苑街道新仓路小区", "北苑街道"},
+		{"嘉禾街道望岗村", "嘉禾街道"},
+		{"川沙新镇", "川沙新镇"},
+		{"南口地区", "南口地区"},
+		{"松榆东里", ""},
+		{"镇宁路200弄", ""},
+		{"金色家乡小区", ""},
+		{"建德路1号", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseStreet(tt.address))
+		})
+	}
+}
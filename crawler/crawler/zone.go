@@ -0,0 +1,114 @@
+package crawler
+
+import (
+	"crawler/model"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//	封控区、管控区、防范区名单
+//
+//	2022年4月起上海各区分别公布“三区”名单，一般按分级、街道分组列出小区：
+//		浦东新区封控区、管控区、防范区名单（4月11日）
+//		一、封控区
+//		（一）川沙新镇
+//		1.川沙新镇城南小区
+//		2.川沙新镇华夏社区
+//		高桥镇：凌桥一村、凌桥二村
+//		二、管控区
+//		...
+//	区名可能在标题中，也可能单独一行；街道可能单独一行，也可能位于行首并以冒号与小区分隔。
+
+// 可以解析封控区、管控区、防范区名单的解析器
+type ZoneParser interface {
+	IsZones(title string) bool
+	ParseZones(zs *model.Zones, title, content string) error
+}
+
+var (
+	reZoneDate   = regexp.MustCompile(`(?P<date>(?:\d+年)?\d+月\d+日)`)
+	reZoneTier   = regexp.MustCompile(`^(?:[一二三四五六七八九十]+[、.．]|[（(][一二三四五六七八九十]+[）)])?\s*(?P<tier>封控区|管控区|防范区)(?:名单)?(?:[（(]\d+个[）)])?[：:]?$`)
+	reZoneStreet = regexp.MustCompile(`^(?:[一二三四五六七八九十]+[、.．]|[（(][一二三四五六七八九十]+[）)])?\s*(?P<street>[^\d\s，、：:；。（）()]{1,8}?(?:街道|地区|镇|乡))(?:[（(]\d+个[）)])?(?:[：:]\s*(?P<names>.*))?$`)
+	reZoneNumber = regexp.MustCompile(`^\s*(?:\d+[.．、]|[（(]\d+[）)])\s*`)
+)
+
+// 解析名单，日期和区名优先取自标题
+func parseZones(zs *model.Zones, city string, districts []string, title, content string) error {
+	if zs == nil {
+		return fmt.Errorf("输入对象为空")
+	}
+
+	//	日期
+	m := reZoneDate.FindStringSubmatch(title)
+	if m == nil {
+		m = reZoneDate.FindStringSubmatch(content)
+	}
+	if m == nil {
+		return fmt.Errorf("无法解析名单日期：%q", title)
+	}
+	s := m[1]
+	if !strings.Contains(s, "年") {
+		s = fmt.Sprintf("2022年%s", s)
+	}
+	date, err := time.Parse("2006年1月2日", s)
+	if err != nil {
+		return fmt.Errorf("无法解析名单日期：%q", m[1])
+	}
+
+	district := ""
+	for _, d := range districts {
+		if strings.Contains(title, d) {
+			district = d
+			break
+		}
+	}
+
+	var tier model.ZoneTier
+	street := ""
+	n := 0
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		//	单独一行的区名
+		if d := matchDistrict(districts, line); len(d) > 0 && len(strings.Trim(line[len(d):], "：: ")) == 0 {
+			district, tier, street = d, "", ""
+			continue
+		}
+		if h := reZoneTier.FindStringSubmatch(line); h != nil {
+			tier, street = model.ZoneTier(h[1]), ""
+			continue
+		}
+		if len(tier) == 0 || strings.Contains(line, "。") {
+			//	名单前后的说明文字
+			continue
+		}
+		names := line
+		if h := reZoneStreet.FindStringSubmatch(line); h != nil {
+			street, names = h[1], h[2]
+		}
+		names = reZoneNumber.ReplaceAllString(names, "")
+		for _, name := range strings.FieldsFunc(names, func(r rune) bool { return r == '、' || r == '，' || r == '；' }) {
+			name = strings.TrimSpace(name)
+			if len(name) == 0 || len(district) == 0 {
+				continue
+			}
+			*zs = append(*zs, model.Zone{
+				Date:     date,
+				City:     city,
+				District: district,
+				Street:   street,
+				Name:     name,
+				Tier:     tier,
+			})
+			n++
+		}
+	}
+	if n == 0 {
+		return fmt.Errorf("[%s] 名单中没有解析到小区：%q", date.Format("2006-01-02"), title)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"crawler/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseZones(t *testing.T) {
+	title := "浦东新区封控区、管控区、防范区名单（4月11日）"
+	content := "根据疫情防控需要，现将本区封控区、管控区、防范区名单公布如下。\n" +
+		"一、封控区\n" +
+		"（一）川沙新镇\n" +
+		"1.城南小区\n" +
+		"2.华夏社区\n" +
+		"高桥镇：凌桥一村、凌桥二村\n" +
+		"二、管控区\n" +
+		"花木街道\n" +
+		"1.东绣路1号\n" +
+		"三、防范区\n" +
+		"除封控区、管控区以外的区域。\n"
+
+	var zs model.Zones
+	assert.NoError(t, DailyParserShanghai{}.ParseZones(&zs, title, content))
+
+	type item struct {
+		street, name string
+		tier         model.ZoneTier
+	}
+	var got []item
+	for _, z := range zs {
+		assert.Equal(t, s2date("2022-04-11"), z.Date)
+		assert.Equal(t, "浦东新区", z.District)
+		got = append(got, item{z.Street, z.Name, z.Tier})
+	}
+	assert.Equal(t, []item{
+		{"川沙新镇", "城南小区", model.ZoneSealed},
+		{"川沙新镇", "华夏社区", model.ZoneSealed},
+		{"高桥镇", "凌桥一村", model.ZoneSealed},
+		{"高桥镇", "凌桥二村", model.ZoneSealed},
+		{"花木街道", "东绣路1号", model.ZoneControlled},
+	}, got)
+
+	assert.True(t, DailyParserShanghai{}.IsZones(title))
+	assert.Error(t, DailyParserShanghai{}.ParseZones(&zs, title, "暂无名单"))
+}
+
+func TestResidents_JoinZones(t *testing.T) {
+	z := func(date, name string, tier model.ZoneTier) model.Zone {
+		return model.Zone{Date: s2date(date), City: "上海市", District: "黄浦区", Name: name, Tier: tier}
+	}
+	zs := model.Zones{
+		z("2022-04-11", "永年路24弄", model.ZoneSealed),
+		z("2022-04-11", "建德路1号（部分楼栋）", model.ZoneControlled),
+		z("2022-04-15", "永年路24弄", model.ZonePrecaution),
+	}
+	r := func(date, address string) model.Resident {
+		return model.Resident{Date: s2date(date), City: "上海市", District: "黄浦区", Address: address}
+	}
+	rs := model.Residents{
+		r("2022-04-10", "永年路24弄"), // 名单公布之前
+		r("2022-04-12", "永年路24弄"),
+		r("2022-04-12", "建德路1号"), // 包含匹配
+		r("2022-04-12", "顺昌路612弄"),
+		r("2022-04-16", "永年路24弄"), // 新名单
+		r("2022-04-30", "永年路24弄"), // 名单已过期
+	}
+	rs.JoinZones(zs)
+
+	var got []model.ZoneTier
+	for _, r := range rs {
+		got = append(got, r.Zone)
+	}
+	assert.Equal(t, []model.ZoneTier{"", model.ZoneSealed, model.ZoneControlled, "", model.ZonePrecaution, ""}, got)
+}
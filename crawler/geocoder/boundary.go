@@ -0,0 +1,264 @@
+package geocoder
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/suifengtec/gocoord"
+)
+
+// 区界
+//
+//	内置的区界位于 boundaries/<city>.geojson，city 为城市键，如 shanghai。
+//	每个区为一个 Feature，properties.name 为区名，geometry 为 Polygon 或 MultiPolygon，坐标应为 WGS84。
+//	DataV 等来源的区界为 GCJ-02 坐标，需要先用 ImportBoundaries 转换。没有区界的区不做检查。
+
+//go:embed boundaries/*.geojson
+var boundaryFiles embed.FS
+
+// 多边形，第一个环为外边界，其余为洞
+type polygon struct {
+	rings          [][][2]float64
+	minLon, minLat float64
+	maxLon, maxLat float64
+}
+
+func newPolygon(rings [][][2]float64) polygon {
+	p := polygon{rings: rings, minLon: 180, minLat: 90, maxLon: -180, maxLat: -90}
+	if len(rings) > 0 {
+		for _, pt := range rings[0] {
+			if pt[0] < p.minLon {
+				p.minLon = pt[0]
+			}
+			if pt[0] > p.maxLon {
+				p.maxLon = pt[0]
+			}
+			if pt[1] < p.minLat {
+				p.minLat = pt[1]
+			}
+			if pt[1] > p.maxLat {
+				p.maxLat = pt[1]
+			}
+		}
+	}
+	return p
+}
+
+// 射线法判断点是否在环内
+func ringContains(ring [][2]float64, lon, lat float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (p polygon) contains(lon, lat float64) bool {
+	if len(p.rings) == 0 || lon < p.minLon || lon > p.maxLon || lat < p.minLat || lat > p.maxLat {
+		return false
+	}
+	if !ringContains(p.rings[0], lon, lat) {
+		return false
+	}
+	for _, hole := range p.rings[1:] {
+		if ringContains(hole, lon, lat) {
+			return false
+		}
+	}
+	return true
+}
+
+// 一个城市各区的区界
+type Boundaries struct {
+	districts []string
+	polygons  map[string][]polygon // 区名 => 多边形
+}
+
+// 读取内置的区界，city 为城市键，如 shanghai；没有该城市的区界时返回空的区界
+func LoadBoundaries(city string) (*Boundaries, error) {
+	f, err := boundaryFiles.Open("boundaries/" + city + ".geojson")
+	if err != nil {
+		return &Boundaries{polygons: make(map[string][]polygon)}, nil
+	}
+	defer f.Close()
+	return loadBoundaries(f)
+}
+
+// 从 GeoJSON 文件读取区界
+func LoadBoundariesFromFile(filename string) (*Boundaries, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := loadBoundaries(f)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取区界 %q：%s", filename, err)
+	}
+	return b, nil
+}
+
+func loadBoundaries(r io.Reader) (*Boundaries, error) {
+	var fc struct {
+		Features []struct {
+			Properties struct {
+				Name string
+			}
+			Geometry struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
+		}
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, err
+	}
+	b := &Boundaries{polygons: make(map[string][]polygon)}
+	for i, f := range fc.Features {
+		var polygons [][][][2]float64
+		switch f.Geometry.Type {
+		case "Polygon":
+			var rings [][][2]float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &rings); err != nil {
+				return nil, fmt.Errorf("第 %d 个 Feature (%s)：%s", i+1, f.Properties.Name, err)
+			}
+			polygons = append(polygons, rings)
+		case "MultiPolygon":
+			if err := json.Unmarshal(f.Geometry.Coordinates, &polygons); err != nil {
+				return nil, fmt.Errorf("第 %d 个 Feature (%s)：%s", i+1, f.Properties.Name, err)
+			}
+		default:
+			return nil, fmt.Errorf("第 %d 个 Feature (%s) 不是 Polygon 或 MultiPolygon", i+1, f.Properties.Name)
+		}
+		name := f.Properties.Name
+		if _, ok := b.polygons[name]; !ok {
+			b.districts = append(b.districts, name)
+		}
+		for _, rings := range polygons {
+			b.polygons[name] = append(b.polygons[name], newPolygon(rings))
+		}
+	}
+	return b, nil
+}
+
+// 有区界的区的数量
+func (b *Boundaries) Len() int {
+	return len(b.districts)
+}
+
+// 是否有该区的区界
+func (b *Boundaries) Has(district string) bool {
+	_, ok := b.polygons[district]
+	return ok
+}
+
+// 坐标是否在该区内，没有该区的区界时视为在区内
+func (b *Boundaries) Contains(district string, lon, lat float64) bool {
+	ps, ok := b.polygons[district]
+	if !ok {
+		return true
+	}
+	for _, p := range ps {
+		if p.contains(lon, lat) {
+			return true
+		}
+	}
+	return false
+}
+
+// 坐标所在的区，不在任何区内时返回空字符串
+func (b *Boundaries) Locate(lon, lat float64) string {
+	for _, d := range b.districts {
+		for _, p := range b.polygons[d] {
+			if p.contains(lon, lat) {
+				return d
+			}
+		}
+	}
+	return ""
+}
+
+// 导入 GCJ-02 坐标的区界（如 DataV 的 310000_full.json），转换为 WGS84 后写出内置区界所用的 GeoJSON
+//
+//	只保留 properties.name，坐标保留 6 位小数（约 0.1 米）；返回导入的区的数量
+func ImportBoundaries(r io.Reader, w io.Writer) (int, error) {
+	var fc struct {
+		Features []struct {
+			Properties struct {
+				Name string
+			}
+			Geometry struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
+		}
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return 0, err
+	}
+
+	type feature struct {
+		Type       string            `json:"type"`
+		Properties map[string]string `json:"properties"`
+		Geometry   struct {
+			Type        string      `json:"type"`
+			Coordinates interface{} `json:"coordinates"`
+		} `json:"geometry"`
+	}
+	out := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Features: []feature{}}
+
+	round := func(v float64) float64 { return math.Round(v*1e6) / 1e6 }
+	convert := func(rings [][][2]float64) {
+		for _, ring := range rings {
+			for i, pt := range ring {
+				p := gocoord.GCJ02ToWGS84(gocoord.Position{Lon: pt[0], Lat: pt[1]})
+				ring[i] = [2]float64{round(p.Lon), round(p.Lat)}
+			}
+		}
+	}
+
+	for i, f := range fc.Features {
+		if len(f.Properties.Name) == 0 {
+			return 0, fmt.Errorf("第 %d 个 Feature 没有名称", i+1)
+		}
+		o := feature{Type: "Feature", Properties: map[string]string{"name": f.Properties.Name}}
+		o.Geometry.Type = f.Geometry.Type
+		switch f.Geometry.Type {
+		case "Polygon":
+			var rings [][][2]float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &rings); err != nil {
+				return 0, fmt.Errorf("第 %d 个 Feature (%s)：%s", i+1, f.Properties.Name, err)
+			}
+			convert(rings)
+			o.Geometry.Coordinates = rings
+		case "MultiPolygon":
+			var polygons [][][][2]float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &polygons); err != nil {
+				return 0, fmt.Errorf("第 %d 个 Feature (%s)：%s", i+1, f.Properties.Name, err)
+			}
+			for _, rings := range polygons {
+				convert(rings)
+			}
+			o.Geometry.Coordinates = polygons
+		default:
+			return 0, fmt.Errorf("第 %d 个 Feature (%s) 不是 Polygon 或 MultiPolygon", i+1, f.Properties.Name)
+		}
+		out.Features = append(out.Features, o)
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		return 0, err
+	}
+	return len(out.Features), nil
+}
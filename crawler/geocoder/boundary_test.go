@@ -0,0 +1,146 @@
+package geocoder
+
+import (
+	"bytes"
+	"crawler/crawler"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/suifengtec/gocoord"
+)
+
+func TestBoundaries(t *testing.T) {
+	b, err := LoadBoundariesFromFile("testdata/boundaries.geojson")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, b.Len())
+	assert.True(t, b.Has("甲区"))
+	assert.False(t, b.Has("丙区"))
+
+	assert.True(t, b.Contains("甲区", 2, 2))
+	assert.False(t, b.Contains("甲区", 5, 5), "洞内的点不在区内")
+	assert.False(t, b.Contains("甲区", 15, 5))
+	assert.True(t, b.Contains("乙区", 15, 5))
+	assert.True(t, b.Contains("乙区", 35, 5))
+	assert.False(t, b.Contains("乙区", 25, 5))
+	//	没有区界的区不做检查
+	assert.True(t, b.Contains("丙区", 100, 100))
+
+	assert.Equal(t, "甲区", b.Locate(1, 9))
+	assert.Equal(t, "乙区", b.Locate(39, 1))
+	assert.Equal(t, "", b.Locate(5, 5))
+	assert.Equal(t, "", b.Locate(-1, 5))
+
+	//	内置的区界
+	for _, city := range []string{"shanghai", "beijing", "nosuchcity"} {
+		_, err := LoadBoundaries(city)
+		assert.NoError(t, err, city)
+	}
+}
+
+func TestImportBoundaries(t *testing.T) {
+	//	GCJ-02 坐标，人民广场附近的一个方块
+	gcj02 := `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"adcode": 310101, "name": "黄浦区"},
+		 "geometry": {"type": "MultiPolygon", "coordinates": [[[[121.46, 31.22], [121.49, 31.22], [121.49, 31.24], [121.46, 31.24], [121.46, 31.22]]]]}}
+	]}`
+	var w bytes.Buffer
+	n, err := ImportBoundaries(strings.NewReader(gcj02), &w)
+	if !assert.NoError(t, err) || !assert.Equal(t, 1, n) {
+		return
+	}
+	b, err := loadBoundaries(&w)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, b.Has("黄浦区"))
+
+	//	方块西南角内侧的点换算为 WGS84 后，仍在转换后的区界内
+	p := gocoord.GCJ02ToWGS84(gocoord.Position{Lon: 121.4601, Lat: 31.2201})
+	assert.True(t, b.Contains("黄浦区", p.Lon, p.Lat))
+	//	区界已向西偏移约 450 米，靠近东边界的 GCJ-02 坐标不再在区界内
+	assert.False(t, b.Contains("黄浦区", 121.4890, 31.23))
+
+	_, err = ImportBoundaries(strings.NewReader(`{"features": [{"properties": {"name": "甲区"}, "geometry": {"type": "Point", "coordinates": [1, 2]}}]}`), &w)
+	assert.Error(t, err)
+}
+
+// 内置区界为 WGS84，应包含全部 16 个区
+func TestLoadBoundaries_Builtin(t *testing.T) {
+	testcases := []struct {
+		city     string
+		lon, lat float64 // WGS84
+		district string
+	}{
+		{"shanghai", 121.4700, 31.2326, "黄浦区"},  // 人民广场
+		{"shanghai", 121.4953, 31.2416, "浦东新区"}, // 陆家嘴
+		{"beijing", 116.3912, 39.9061, "东城区"},   // 天安门
+		{"beijing", 116.3105, 39.9929, "海淀区"},   // 中关村
+	}
+	for _, c := range testcases {
+		b, err := LoadBoundaries(c.city)
+		if !assert.NoError(t, err, c.city) {
+			continue
+		}
+		if b.Len() == 0 {
+			t.Skipf("内置区界 boundaries/%s.geojson 尚未导入，见 ImportBoundaries", c.city)
+		}
+		assert.Equal(t, 16, b.Len(), c.city)
+		assert.Equal(t, c.district, b.Locate(c.lon, c.lat), "%s (%.4f, %.4f)", c.city, c.lon, c.lat)
+	}
+}
+
+// 内置区界应覆盖解析器中的每一个区，否则这些区的坐标不做检查
+func TestLoadBoundaries_BuiltinDistricts(t *testing.T) {
+	for _, city := range []string{"shanghai", "beijing"} {
+		info, err := crawler.GetCity(city)
+		if !assert.NoError(t, err, city) {
+			continue
+		}
+		b, err := LoadBoundaries(city)
+		if !assert.NoError(t, err, city) {
+			continue
+		}
+		for _, d := range info.Districts {
+			assert.True(t, b.Has(d), "内置区界 boundaries/%s.geojson 缺少 %s", city, d)
+		}
+	}
+}
+
+func TestGeocoder_GeocodeWithin(t *testing.T) {
+	first := &geocoderAPIStub{name: "first", known: map[string]Address{
+		"a": {Longitude: 15, Latitude: 5},
+	}}
+	second := &geocoderAPIStub{name: "second", known: map[string]Address{
+		"a": {Longitude: 2, Latitude: 2},
+	}}
+	g := NewGeocoderChain(path.Join(t.TempDir(), "cache"), Geocoder{api: first}, Geocoder{api: second})
+	defer g.Close()
+	b, err := LoadBoundariesFromFile("testdata/boundaries.geojson")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	a, err := g.Geocode("a")
+	if assert.NoError(t, err) {
+		assert.False(t, b.Contains("甲区", a.Longitude, a.Latitude))
+	}
+
+	//	第一个服务的坐标不在甲区内，改用第二个，并更新缓存
+	inside := func(a Address) bool { return b.Contains("甲区", a.Longitude, a.Latitude) }
+	a, err = g.GeocodeWithin("a", inside)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "second", a.Provider)
+	}
+	a, err = g.Geocode("a")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2.0, a.Longitude)
+	}
+
+	//	都不满足
+	_, err = g.GeocodeWithin("a", func(Address) bool { return false })
+	assert.Error(t, err)
+}
@@ -9,10 +9,20 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 )
 
+// 地理编码缓存，leveldb.DB 可以在多个 goroutine 中同时使用，因此缓存也可以
 type GeocodeCache struct {
 	db *leveldb.DB
 }
 
+// 缓存中保存的解析结果
+type geocodeCacheRecord struct {
+	Longitude  float64
+	Latitude   float64
+	Precision  Precision
+	Confidence float64
+	Provider   string // 给出结果的服务
+}
+
 func NewGeocodeCache(path string) (*GeocodeCache, error) {
 	db, err := leveldb.OpenFile(path, nil)
 	if err != nil {
@@ -25,9 +35,16 @@ func NewGeocodeCache(path string) (*GeocodeCache, error) {
 	return &c, nil
 }
 
-func (c GeocodeCache) Put(addr string, longitude, latitude float64) error {
+func (c GeocodeCache) Put(addr string, a Address) error {
 	buf := new(bytes.Buffer)
-	if err := gob.NewEncoder(buf).Encode([]float64{longitude, latitude}); err != nil {
+	rec := geocodeCacheRecord{
+		Longitude:  a.Longitude,
+		Latitude:   a.Latitude,
+		Precision:  a.Precision,
+		Confidence: a.Confidence,
+		Provider:   a.Provider,
+	}
+	if err := gob.NewEncoder(buf).Encode(rec); err != nil {
 		return err
 	}
 	if err := c.db.Put([]byte(strings.TrimSpace(addr)), buf.Bytes(), nil); err != nil {
@@ -36,15 +53,26 @@ func (c GeocodeCache) Put(addr string, longitude, latitude float64) error {
 	return nil
 }
 
-func (c GeocodeCache) Get(addr string) (longitude, latitude float64, err error) {
-	var data []byte
-	if data, err = c.db.Get([]byte(strings.TrimSpace(addr)), nil); err != nil {
-		return 0, 0, err
+func (c GeocodeCache) Get(addr string) (*Address, error) {
+	data, err := c.db.Get([]byte(strings.TrimSpace(addr)), nil)
+	if err != nil {
+		return nil, err
 	}
-	loc := []float64{}
-	if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(&loc); err != nil {
-		return 0, 0, err
+	var rec geocodeCacheRecord
+	if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(&rec); err != nil {
+		//	旧版本的缓存只有经纬度
+		loc := []float64{}
+		if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(&loc); err != nil {
+			return nil, err
+		}
+		rec.Longitude, rec.Latitude = loc[0], loc[1]
 	}
-	longitude, latitude = loc[0], loc[1]
-	return
+	return &Address{
+		Address:    addr,
+		Longitude:  rec.Longitude,
+		Latitude:   rec.Latitude,
+		Precision:  rec.Precision,
+		Confidence: rec.Confidence,
+		Provider:   rec.Provider,
+	}, nil
 }
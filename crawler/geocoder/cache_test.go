@@ -1,6 +1,8 @@
 package geocoder
 
 import (
+	"bytes"
+	"encoding/gob"
 	"math"
 	"os"
 	"path"
@@ -13,22 +15,38 @@ const TEST_CACHE_THRESHOLD = 0.001
 
 func TestCache(t *testing.T) {
 	testcases := []Address{
-		{"上海市静安区芷江西路453弄", 121.45779, 31.25999},
+		{Address: "上海市静安区芷江西路453弄", Longitude: 121.45779, Latitude: 31.25999, Precision: PrecisionAddress, Confidence: 0.8, Provider: "百度地图API"},
 	}
 
 	cache, err := NewGeocodeCache(path.Join(os.TempDir(), "geocoder", "cache"))
 	assert.NoErrorf(t, err, "建立缓存失败: %s", err)
 	if err == nil {
+		defer cache.db.Close()
 		for _, c := range testcases {
-			err := cache.Put(c.Address, c.Longitude, c.Latitude)
+			err := cache.Put(c.Address, c)
 			assert.NoError(t, err, "缓存添加地址失败")
 		}
 
 		for _, c := range testcases {
-			longitude, latitude, err := cache.Get(c.Address)
-			assert.NoErrorf(t, err, "获取缓存地址失败")
-			assert.Lessf(t, math.Abs(longitude-c.Longitude), TEST_CACHE_THRESHOLD, "缓存返回经度超出误差：%f => %f", c.Longitude, longitude)
-			assert.Lessf(t, math.Abs(latitude-c.Latitude), TEST_CACHE_THRESHOLD, "缓存返回纬度超出误差：%f => %f", c.Latitude, latitude)
+			a, err := cache.Get(c.Address)
+			if assert.NoErrorf(t, err, "获取缓存地址失败") {
+				assert.Lessf(t, math.Abs(a.Longitude-c.Longitude), TEST_CACHE_THRESHOLD, "缓存返回经度超出误差：%f => %f", c.Longitude, a.Longitude)
+				assert.Lessf(t, math.Abs(a.Latitude-c.Latitude), TEST_CACHE_THRESHOLD, "缓存返回纬度超出误差：%f => %f", c.Latitude, a.Latitude)
+				assert.Equal(t, c.Precision, a.Precision)
+				assert.Equal(t, c.Confidence, a.Confidence)
+				assert.Equal(t, c.Provider, a.Provider)
+			}
+		}
+
+		//	旧版本的缓存只有经纬度
+		buf := new(bytes.Buffer)
+		assert.NoError(t, gob.NewEncoder(buf).Encode([]float64{121.5, 31.2}))
+		assert.NoError(t, cache.db.Put([]byte("旧地址"), buf.Bytes(), nil))
+		a, err := cache.Get("旧地址")
+		if assert.NoError(t, err) {
+			assert.Equal(t, 121.5, a.Longitude)
+			assert.Equal(t, 31.2, a.Latitude)
+			assert.Empty(t, a.Provider)
 		}
 	}
 }
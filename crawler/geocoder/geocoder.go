@@ -1,15 +1,19 @@
 package geocoder
 
 import (
+	"fmt"
 	"math"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type Address struct {
-	Address   string
-	Longitude float64
-	Latitude  float64
+	Address    string
+	Longitude  float64
+	Latitude   float64
+	Precision  Precision // 坐标精度
+	Confidence float64   // 可信度（0~1），0 表示未知；本地地名库为匹配得分
+	Provider   string    // 给出结果的服务
 }
 
 type Geocoder struct {
@@ -24,11 +28,11 @@ func (g Geocoder) Name() string {
 func (g Geocoder) Geocode(addr string) (*Address, error) {
 	//	先检查缓存是否已存在该地址的解析
 	if g.cache != nil {
-		lon, lat, err := g.cache.Get(addr)
+		a, err := g.cache.Get(addr)
 		if err != nil {
 			// log.Warnf("Geocode(%s): 读取失败：%s", addr, err)
 		} else {
-			return &Address{Address: addr, Longitude: lon, Latitude: lat}, nil
+			return a, nil
 		}
 	}
 	//	缓存没有，发起请求
@@ -36,9 +40,12 @@ func (g Geocoder) Geocode(addr string) (*Address, error) {
 	if err != nil {
 		return a, err
 	}
-	//	将结果非 0 的坐标信息保存于缓存
+	if len(a.Provider) == 0 {
+		a.Provider = g.api.Name()
+	}
+	//	将结果非 0 的坐标信息保存于缓存，以查询的地址为键
 	if g.cache != nil && a.Longitude != 0 && a.Latitude != 0 {
-		err := g.cache.Put(a.Address, a.Longitude, a.Latitude)
+		err := g.cache.Put(addr, *a)
 		if err != nil {
 			log.Errorf("Geocode(%q): 写入失败：%s", addr, err)
 		}
@@ -46,6 +53,9 @@ func (g Geocoder) Geocode(addr string) (*Address, error) {
 	return a, nil
 }
 
+// 批量解析，结果与 addrs 一一对应
+//
+//	有分批请求失败时，仍返回全部结果（失败的地址坐标为 0），同时返回错误，调用方可以逐个重试
 func (g Geocoder) GeocodeInBatch(addrs []string) ([]Address, error) {
 	results := make([]Address, len(addrs))
 	//	先检查缓存是否已存在该地址的解析,分拆已经解析和未被解析的地址列表
@@ -53,11 +63,9 @@ func (g Geocoder) GeocodeInBatch(addrs []string) ([]Address, error) {
 	id_to_query := make([]int, 0, len(addrs))
 	for i, addr := range addrs {
 		if g.cache != nil {
-			if lon, lat, err := g.cache.Get(addr); err == nil {
+			if a, err := g.cache.Get(addr); err == nil {
 				//  缓存查询成功，将结果存入结果
-				results[i].Address = addr
-				results[i].Longitude = lon
-				results[i].Latitude = lat
+				results[i] = *a
 				//	跳过后面添加查询列表
 				continue
 			}
@@ -71,25 +79,38 @@ func (g Geocoder) GeocodeInBatch(addrs []string) ([]Address, error) {
 
 	//	针对未被解析的地址列表进行批量解析
 	var results_from_query []Address
+	var failed int
+	var last_err error
 	if len(addrs_to_query) > 0 {
 		//	根据限制切片，分子批发送请求
-		addrs_to_query_slices := batch_split(addrs)
+		addrs_to_query_slices := batch_split(addrs_to_query)
 		for _, addrs_slice := range addrs_to_query_slices {
 			results_slice, err := g.api.RequestBatch(addrs_slice)
-			if err == nil {
+			if err == nil && len(results_slice) == len(addrs_slice) {
 				//	请求成功，追加结果
 				results_from_query = append(results_from_query, results_slice...)
 			} else {
-				//	请求失败，追加空坐标到地址列表
+				//	请求失败，追加空坐标到地址列表，并记录错误
+				if err == nil {
+					err = fmt.Errorf("返回 %d 个结果，应为 %d 个", len(results_slice), len(addrs_slice))
+				}
+				failed += len(addrs_slice)
+				last_err = err
 				results_from_query = append(results_from_query, (make([]Address, len(addrs_slice)))...)
 			}
 		}
 
-		//	将结果非 0 的坐标信息保存于缓存
+		for i := range results_from_query {
+			if a := &results_from_query[i]; a.Longitude != 0 && a.Latitude != 0 && len(a.Provider) == 0 {
+				a.Provider = g.api.Name()
+			}
+		}
+
+		//	将结果非 0 的坐标信息保存于缓存，以查询的地址为键
 		if g.cache != nil {
-			for _, a := range results_from_query {
+			for i, a := range results_from_query {
 				if a.Longitude != 0 && a.Latitude != 0 {
-					if err := g.cache.Put(a.Address, a.Longitude, a.Latitude); err != nil {
+					if err := g.cache.Put(addrs_to_query[i], a); err != nil {
 						log.Errorf("Geocode(%q): 缓存写入失败：%s", a.Address, err)
 					}
 				}
@@ -102,10 +123,42 @@ func (g Geocoder) GeocodeInBatch(addrs []string) ([]Address, error) {
 		id := id_to_query[i]
 		results[id] = result
 	}
-	//	返回结果
+	//	返回结果，有请求失败时同时返回错误，失败的地址坐标为 0
+	if last_err != nil {
+		return results, fmt.Errorf("%s: 批量解析 %d 个地址中有 %d 个请求失败：%s", g.api.Name(), len(addrs), failed, last_err)
+	}
 	return results, nil
 }
 
+// 不使用缓存，依次向每个服务重新查询，返回第一个满足 accept 的结果，并更新缓存
+//
+//	用于缓存或首选服务给出的坐标明显有误（如不在所属的区内）时重新查询
+func (g Geocoder) GeocodeWithin(addr string, accept func(Address) bool) (*Address, error) {
+	apis := []GeocoderAPI{g.api}
+	if chain, ok := g.api.(GeocoderAPIChain); ok {
+		apis = chain.apis
+	}
+	for _, api := range apis {
+		a, err := api.Request(addr)
+		if err != nil || a == nil || a.Longitude == 0 || a.Latitude == 0 {
+			continue
+		}
+		if len(a.Provider) == 0 {
+			a.Provider = api.Name()
+		}
+		if !accept(*a) {
+			continue
+		}
+		if g.cache != nil {
+			if err := g.cache.Put(addr, *a); err != nil {
+				log.Errorf("Geocode(%q): 写入失败：%s", addr, err)
+			}
+		}
+		return a, nil
+	}
+	return nil, fmt.Errorf("没有服务给出满足条件的坐标：%q", addr)
+}
+
 func (g Geocoder) Close() {
 	if g.cache != nil {
 		g.cache.db.Close()
@@ -118,6 +171,21 @@ type GeocoderAPI interface {
 	RequestBatch(addrs []string) ([]Address, error)
 }
 
+// 一次请求就能解析多个地址的服务，其余服务的 RequestBatch 只是逐个请求
+type batchGeocoderAPI interface {
+	supportsBatch() bool
+}
+
+func supportsBatch(api GeocoderAPI) bool {
+	b, ok := api.(batchGeocoderAPI)
+	return ok && b.supportsBatch()
+}
+
+// 是否有批量接口，有时应尽量使用 GeocodeInBatch
+func (g Geocoder) SupportsBatch() bool {
+	return supportsBatch(g.api)
+}
+
 const BATCH_SIZE_LIMIT int = 20
 
 func batch_split(addrs []string) [][]string {
@@ -125,7 +193,11 @@ func batch_split(addrs []string) [][]string {
 	addrs_slices := make([][]string, 0)
 	var i int
 	for i = 0; i < num_of_slice; i++ {
-		addrs_slices = append(addrs_slices, addrs[i*BATCH_SIZE_LIMIT:(i+1)*BATCH_SIZE_LIMIT])
+		end := (i + 1) * BATCH_SIZE_LIMIT
+		if end > len(addrs) {
+			end = len(addrs)
+		}
+		addrs_slices = append(addrs_slices, addrs[i*BATCH_SIZE_LIMIT:end])
 	}
 	return addrs_slices
 }
@@ -97,6 +97,10 @@ func (a GeocoderAPIAmap) Name() string {
 	return "高德地图API"
 }
 
+func (a GeocoderAPIAmap) supportsBatch() bool {
+	return true
+}
+
 func (a GeocoderAPIAmap) Request(addr string) (*Address, error) {
 	var err error
 
@@ -147,7 +151,7 @@ func (a GeocoderAPIAmap) Request(addr string) (*Address, error) {
 	// )
 
 	//	返回
-	return &Address{Address: r0.Formatted_Address, Longitude: l2.Lon, Latitude: l2.Lat}, nil
+	return &Address{Address: r0.Formatted_Address, Longitude: l2.Lon, Latitude: l2.Lat, Precision: ParsePrecision(r0.Level)}, nil
 }
 
 // https://lbs.amap.com/api/webservice/guide/api/batchrequest
@@ -217,6 +221,7 @@ func (g GeocoderAPIAmap) RequestBatch(addrs []string) ([]Address, error) {
 					Address:   r0.Formatted_Address,
 					Longitude: l2.Lon,
 					Latitude:  l2.Lat,
+					Precision: ParsePrecision(r0.Level),
 				})
 			} else {
 				//	添加坐标为0的地址
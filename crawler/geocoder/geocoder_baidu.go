@@ -47,6 +47,22 @@ type GeocoderAPIBaiduResponse struct {
 	}
 }
 
+// 坐标精度和可信度
+//
+//	可信度取 confidence（打点精度）与 comprehension（地址理解程度）中较小者；
+//	precise 为 0 表示模糊打点，可信度减半
+func (r GeocoderAPIBaiduResponse) quality() (Precision, float64) {
+	confidence := r.Result.Confidence
+	if r.Result.Comprehension < confidence {
+		confidence = r.Result.Comprehension
+	}
+	c := float64(confidence) / 100
+	if r.Result.Precise == 0 {
+		c /= 2
+	}
+	return ParsePrecision(r.Result.Level), c
+}
+
 type GeocoderAPIBaiduBatchRequest struct {
 	Reqs []GeocoderAPIBaiduBatchRequestItem `json:"reqs"`
 }
@@ -77,6 +93,10 @@ func (a GeocoderAPIBaidu) Name() string {
 	return "百度地图API"
 }
 
+func (a GeocoderAPIBaidu) supportsBatch() bool {
+	return true
+}
+
 func (a GeocoderAPIBaidu) Request(addr string) (*Address, error) {
 	var err error
 
@@ -119,7 +139,8 @@ func (a GeocoderAPIBaidu) Request(addr string) (*Address, error) {
 	// 	l2.Lat, l2.Lon,
 	// )
 	//	返回
-	return &Address{Address: addr, Longitude: l2.Lon, Latitude: l2.Lat}, nil
+	precision, confidence := r.quality()
+	return &Address{Address: addr, Longitude: l2.Lon, Latitude: l2.Lat, Precision: precision, Confidence: confidence}, nil
 }
 
 // https://lbsyun.baidu.com/index.php?title=webapi/guide/batch
@@ -182,10 +203,13 @@ func (a GeocoderAPIBaidu) RequestBatch(addrs []string) ([]Address, error) {
 				// 	l2.Lat, l2.Lon,
 				// )
 				//	添加解析结果
+				precision, confidence := r.quality()
 				result = append(result, Address{
-					Address:   addrs[i],
-					Longitude: l2.Lon,
-					Latitude:  l2.Lat,
+					Address:    addrs[i],
+					Longitude:  l2.Lon,
+					Latitude:   l2.Lat,
+					Precision:  precision,
+					Confidence: confidence,
 				})
 			} else {
 				//	无法解析，添加坐标为0的地址
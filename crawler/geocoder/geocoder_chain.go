@@ -0,0 +1,118 @@
+package geocoder
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// 依次尝试多个服务
+//
+//	前一个服务返回错误或坐标为 0 时（如当日配额用完），改用下一个服务。
+//	结果中的 Address.Provider 记录实际给出结果的服务，并随结果一起缓存。
+type GeocoderAPIChain struct {
+	apis []GeocoderAPI
+}
+
+// 按顺序组合多个 Geocoder，只使用组合后的缓存，各 Geocoder 自身的缓存不再使用
+func NewGeocoderChain(cachedir string, gs ...Geocoder) Geocoder {
+	var cache *GeocodeCache
+	if len(cachedir) > 0 {
+		var err error
+		cache, err = NewGeocodeCache(cachedir)
+		if err != nil {
+			log.Errorf("NewGeocoderChain(): 无法建立缓存[%s]：%s", cachedir, err)
+		}
+	}
+	chain := GeocoderAPIChain{}
+	for _, g := range gs {
+		chain.apis = append(chain.apis, g.api)
+	}
+	return Geocoder{api: chain, cache: cache}
+}
+
+func (c GeocoderAPIChain) Name() string {
+	var names []string
+	for _, api := range c.apis {
+		names = append(names, api.Name())
+	}
+	return strings.Join(names, " > ")
+}
+
+// 首选服务有批量接口时，按批量处理
+func (c GeocoderAPIChain) supportsBatch() bool {
+	return len(c.apis) > 0 && supportsBatch(c.apis[0])
+}
+
+func (c GeocoderAPIChain) Request(addr string) (*Address, error) {
+	err := fmt.Errorf("没有可用的地理编码服务")
+	for _, api := range c.apis {
+		var a *Address
+		a, err = api.Request(addr)
+		if err != nil {
+			log.Debugf("%s: 解析地址 %q 失败，尝试下一个服务：%s", api.Name(), addr, err)
+			continue
+		}
+		if a == nil || a.Longitude == 0 || a.Latitude == 0 {
+			err = fmt.Errorf("%s: 地址 %q 的坐标为 0", api.Name(), addr)
+			continue
+		}
+		if len(a.Provider) == 0 {
+			a.Provider = api.Name()
+		}
+		return a, nil
+	}
+	return nil, err
+}
+
+func (c GeocoderAPIChain) RequestBatch(addrs []string) ([]Address, error) {
+	results := make([]Address, len(addrs))
+	for i, addr := range addrs {
+		results[i].Address = addr
+	}
+	//	尚未解析的地址序号
+	pending := make([]int, len(addrs))
+	for i := range pending {
+		pending[i] = i
+	}
+	//	所有服务的批量请求都失败时返回错误
+	var err error
+	succeeded := false
+	for _, api := range c.apis {
+		if len(pending) == 0 {
+			break
+		}
+		query := make([]string, len(pending))
+		for i, id := range pending {
+			query[i] = addrs[id]
+		}
+		var as []Address
+		as, err = api.RequestBatch(query)
+		if err != nil || len(as) != len(query) {
+			log.Debugf("%s: 批量解析 %d 个地址失败，尝试下一个服务：%v", api.Name(), len(query), err)
+			if err == nil {
+				err = fmt.Errorf("%s: 返回 %d 个结果，应为 %d 个", api.Name(), len(as), len(query))
+			}
+			continue
+		}
+		succeeded = true
+		var rest []int
+		for i, a := range as {
+			id := pending[i]
+			if a.Longitude == 0 || a.Latitude == 0 {
+				rest = append(rest, id)
+				continue
+			}
+			if len(a.Provider) == 0 {
+				a.Provider = api.Name()
+			}
+			results[id] = a
+		}
+		pending = rest
+	}
+	if !succeeded && err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,105 @@
+package geocoder
+
+import (
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 只认识部分地址的服务，用于测试
+type geocoderAPIStub struct {
+	name   string
+	known  map[string]Address
+	failed bool // 模拟配额用完
+	calls  int
+}
+
+func (s *geocoderAPIStub) Name() string {
+	return s.name
+}
+
+func (s *geocoderAPIStub) Request(addr string) (*Address, error) {
+	s.calls++
+	if s.failed {
+		return nil, fmt.Errorf("配额已用完")
+	}
+	a := s.known[addr]
+	a.Address = addr
+	return &a, nil
+}
+
+func (s *geocoderAPIStub) RequestBatch(addrs []string) ([]Address, error) {
+	var result []Address
+	for _, addr := range addrs {
+		a, err := s.Request(addr)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *a)
+	}
+	return result, nil
+}
+
+func TestGeocoderChain(t *testing.T) {
+	first := &geocoderAPIStub{name: "first", known: map[string]Address{
+		"a": {Longitude: 121.1, Latitude: 31.1},
+	}}
+	second := &geocoderAPIStub{name: "second", known: map[string]Address{
+		"a": {Longitude: 121.9, Latitude: 31.9},
+		"b": {Longitude: 121.2, Latitude: 31.2},
+	}}
+	g := NewGeocoderChain(path.Join(t.TempDir(), "cache"), Geocoder{api: first}, Geocoder{api: second})
+	defer g.Close()
+	assert.Equal(t, "first > second", g.Name())
+
+	a, err := g.Geocode("a")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 121.1, a.Longitude)
+		assert.Equal(t, "first", a.Provider)
+	}
+
+	//	第一个服务坐标为 0，改用第二个
+	a, err = g.Geocode("b")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 121.2, a.Longitude)
+		assert.Equal(t, "second", a.Provider)
+	}
+
+	//	都不认识
+	_, err = g.Geocode("c")
+	assert.Error(t, err)
+
+	//	缓存中记录了给出结果的服务，不再请求
+	first.failed = true
+	calls := first.calls + second.calls
+	a, err = g.Geocode("b")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "second", a.Provider)
+	}
+	assert.Equal(t, calls, first.calls+second.calls)
+
+	//	第一个服务配额用完，批量请求全部改用第二个
+	as, err := g.GeocodeInBatch([]string{"c", "a", "d"})
+	if assert.NoError(t, err) && assert.Len(t, as, 3) {
+		assert.Zero(t, as[0].Longitude)
+		assert.Equal(t, "first", as[1].Provider)
+		assert.Zero(t, as[2].Longitude)
+	}
+	second.known["d"] = Address{Longitude: 121.4, Latitude: 31.4}
+	as, err = g.GeocodeInBatch([]string{"d"})
+	if assert.NoError(t, err) && assert.Len(t, as, 1) {
+		assert.Equal(t, 121.4, as[0].Longitude)
+		assert.Equal(t, "second", as[0].Provider)
+	}
+
+	//	所有服务都失败时返回错误，缓存中已有的地址仍有结果
+	second.failed = true
+	as, err = g.GeocodeInBatch([]string{"a", "e"})
+	assert.Error(t, err)
+	if assert.Len(t, as, 2) {
+		assert.Equal(t, "first", as[0].Provider)
+		assert.Zero(t, as[1].Longitude)
+	}
+}
@@ -0,0 +1,311 @@
+package geocoder
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 本地地名库
+//
+//	不需要网络和密钥，从本地文件中查找小区、道路的坐标，坐标应为 WGS84。支持两种格式：
+//	[CSV] 第一行为表头，列依次为：
+//		市,区,名称,类型,经度,纬度
+//		上海市,静安区,芷江西路453弄,小区,121.45280,31.25884
+//	[GeoJSON] 由 Point 组成的 FeatureCollection，properties 中包含 city、district、name、type：
+//		{"type": "FeatureCollection", "features": [
+//		  {"type": "Feature", "geometry": {"type": "Point", "coordinates": [121.45280, 31.25884]},
+//		   "properties": {"city": "上海市", "district": "静安区", "name": "芷江西路453弄", "type": "小区"}}
+//		]}
+//	先按规范化后的名称精确匹配，再按包含关系和字符相似度模糊匹配，匹配得分记录在 Address.Confidence 中。
+type GeocoderAPIGazetteer struct {
+	entries   []GazetteerEntry
+	cities    []string
+	districts map[string][]string // 市 => 区
+	index     map[string][]int    // 市，或市 + 区 => 地名序号
+	exact     map[string]int      // 市 + 区 + 规范化名称 => 地名序号
+}
+
+// 地名库中的一个小区或道路
+type GazetteerEntry struct {
+	City      string
+	District  string
+	Name      string
+	Type      string // 小区、道路
+	Longitude float64
+	Latitude  float64
+
+	norm string // 规范化后的名称
+}
+
+// 模糊匹配的最低得分，低于该得分视为没有匹配
+const GAZETTEER_MIN_SCORE float64 = 0.6
+
+func NewGeocoderGazetteer(filename string) (Geocoder, error) {
+	api, err := NewGeocoderAPIGazetteer(filename)
+	if err != nil {
+		return Geocoder{}, err
+	}
+	//	本地查询足够快，不需要缓存
+	return Geocoder{api: api}, nil
+}
+
+func NewGeocoderAPIGazetteer(filename string) (*GeocoderAPIGazetteer, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []GazetteerEntry
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		entries, err = loadGazetteerCSV(f)
+	case ".json", ".geojson":
+		entries, err = loadGazetteerGeoJSON(f)
+	default:
+		err = fmt.Errorf("不支持的地名库格式，请使用 .csv 或 .geojson")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取地名库 %q：%s", filename, err)
+	}
+
+	g := &GeocoderAPIGazetteer{
+		districts: make(map[string][]string),
+		index:     make(map[string][]int),
+		exact:     make(map[string]int),
+	}
+	for _, e := range entries {
+		e.norm = normalizeGazetteerName(e.Name)
+		if len(e.norm) == 0 {
+			continue
+		}
+		i := len(g.entries)
+		g.entries = append(g.entries, e)
+		if _, ok := g.index[e.City]; !ok {
+			g.cities = append(g.cities, e.City)
+		}
+		if _, ok := g.index[e.City+e.District]; !ok && len(e.District) > 0 {
+			g.districts[e.City] = append(g.districts[e.City], e.District)
+		}
+		g.index[e.City] = append(g.index[e.City], i)
+		g.index[e.City+e.District] = append(g.index[e.City+e.District], i)
+		if _, ok := g.exact[e.City+e.District+e.norm]; !ok {
+			g.exact[e.City+e.District+e.norm] = i
+		}
+	}
+	return g, nil
+}
+
+func loadGazetteerCSV(r io.Reader) ([]GazetteerEntry, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var entries []GazetteerEntry
+	for i, rec := range records {
+		if i == 0 {
+			//	表头
+			continue
+		}
+		if len(rec) < 6 {
+			return nil, fmt.Errorf("第 %d 行只有 %d 列", i+1, len(rec))
+		}
+		e := GazetteerEntry{City: rec[0], District: rec[1], Name: rec[2], Type: rec[3]}
+		if e.Longitude, err = strconv.ParseFloat(strings.TrimSpace(rec[4]), 64); err != nil {
+			return nil, fmt.Errorf("第 %d 行无法解析经度：%s", i+1, err)
+		}
+		if e.Latitude, err = strconv.ParseFloat(strings.TrimSpace(rec[5]), 64); err != nil {
+			return nil, fmt.Errorf("第 %d 行无法解析纬度：%s", i+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func loadGazetteerGeoJSON(r io.Reader) ([]GazetteerEntry, error) {
+	var fc struct {
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates []float64
+			}
+			Properties struct {
+				City     string
+				District string
+				Name     string
+				Type     string
+			}
+		}
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, err
+	}
+	var entries []GazetteerEntry
+	for i, f := range fc.Features {
+		if f.Geometry.Type != "Point" || len(f.Geometry.Coordinates) < 2 {
+			return nil, fmt.Errorf("第 %d 个 Feature 不是 Point", i+1)
+		}
+		entries = append(entries, GazetteerEntry{
+			City:      f.Properties.City,
+			District:  f.Properties.District,
+			Name:      f.Properties.Name,
+			Type:      f.Properties.Type,
+			Longitude: f.Geometry.Coordinates[0],
+			Latitude:  f.Geometry.Coordinates[1],
+		})
+	}
+	return entries, nil
+}
+
+// 去掉空白和标点，全角数字、字母转为半角，去掉“（部分）”这类说明
+func normalizeGazetteerName(s string) string {
+	for _, suffix := range []string{"（部分）", "(部分)"} {
+		s = strings.ReplaceAll(s, suffix, "")
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= '０' && r <= '９' || r >= 'Ａ' && r <= 'Ｚ' || r >= 'ａ' && r <= 'ｚ' {
+			return r - 0xFEE0
+		}
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// 两个字符串的字符二元组 Dice 系数
+func bigramSimilarity(a, b string) float64 {
+	bigrams := func(s string) map[string]int {
+		m := make(map[string]int)
+		rs := []rune(s)
+		for i := 0; i+1 < len(rs); i++ {
+			m[string(rs[i:i+2])]++
+		}
+		return m
+	}
+	ma, mb := bigrams(a), bigrams(b)
+	na, nb := 0, 0
+	for _, n := range ma {
+		na += n
+	}
+	for _, n := range mb {
+		nb += n
+	}
+	if na+nb == 0 {
+		return 0
+	}
+	common := 0
+	for k, n := range ma {
+		if m := mb[k]; m < n {
+			common += m
+		} else {
+			common += n
+		}
+	}
+	return 2 * float64(common) / float64(na+nb)
+}
+
+func (g *GeocoderAPIGazetteer) Name() string {
+	return "本地地名库"
+}
+
+// 在地名库中查找地址，返回最佳匹配及其得分
+func (g *GeocoderAPIGazetteer) Match(addr string) (*GazetteerEntry, float64) {
+	rest := normalizeGazetteerName(addr)
+
+	//	拆出市、区，缩小查找范围
+	city := ""
+	for _, c := range g.cities {
+		if strings.HasPrefix(rest, c) {
+			city, rest = c, strings.TrimPrefix(rest, c)
+			break
+		}
+	}
+	district := ""
+	for _, d := range g.districts[city] {
+		if strings.HasPrefix(rest, d) {
+			district, rest = d, strings.TrimPrefix(rest, d)
+			break
+		}
+	}
+	if len(rest) == 0 {
+		return nil, 0
+	}
+
+	//	精确匹配
+	if len(city) > 0 && len(district) > 0 {
+		if i, ok := g.exact[city+district+rest]; ok {
+			return &g.entries[i], 1
+		}
+	}
+
+	//	模糊匹配
+	var candidates []int
+	switch {
+	case len(district) > 0:
+		candidates = g.index[city+district]
+	case len(city) > 0:
+		candidates = g.index[city]
+	default:
+		candidates = make([]int, len(g.entries))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+	best, score := -1, 0.0
+	n := utf8.RuneCountInString(rest)
+	for _, i := range candidates {
+		e := g.entries[i]
+		var s float64
+		if e.norm == rest {
+			s = 1
+		} else if strings.Contains(rest, e.norm) {
+			//	地址比地名更详细，如“芷江西路453弄3号”
+			s = 0.6 + 0.4*float64(utf8.RuneCountInString(e.norm))/float64(n)
+		} else {
+			s = bigramSimilarity(rest, e.norm)
+		}
+		if s > score {
+			best, score = i, s
+		}
+	}
+	if best < 0 || score < GAZETTEER_MIN_SCORE {
+		return nil, score
+	}
+	return &g.entries[best], score
+}
+
+func (g *GeocoderAPIGazetteer) Request(addr string) (*Address, error) {
+	e, score := g.Match(addr)
+	if e == nil {
+		return nil, fmt.Errorf("本地地名库中没有匹配的地址：%q (%.2f)", addr, score)
+	}
+	return &Address{
+		Address:    e.City + e.District + e.Name,
+		Longitude:  e.Longitude,
+		Latitude:   e.Latitude,
+		Precision:  ParsePrecision(e.Type),
+		Confidence: score,
+	}, nil
+}
+
+func (g *GeocoderAPIGazetteer) RequestBatch(addrs []string) ([]Address, error) {
+	result := make([]Address, 0, len(addrs))
+	for _, addr := range addrs {
+		if a, err := g.Request(addr); err == nil {
+			result = append(result, *a)
+		} else {
+			//	添加坐标为0的地址
+			result = append(result, Address{Address: addr})
+		}
+	}
+	return result, nil
+}
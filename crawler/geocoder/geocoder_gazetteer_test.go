@@ -0,0 +1,80 @@
+package geocoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGazetteer(t *testing.T) {
+	g, err := NewGeocoderGazetteer("testdata/gazetteer.csv")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testcases := []struct {
+		addr      string
+		name      string
+		exact     bool
+		longitude float64
+	}{
+		{"上海市静安区芷江西路453弄", "芷江西路453弄", true, 121.45280},
+		{"上海市 静安区 芷江西路４５３弄", "芷江西路453弄", true, 121.45280},
+		{"上海市黄浦区顺昌路612弄（部分）", "顺昌路612弄", true, 121.47390},
+		//	地址比地名详细
+		{"上海市静安区芷江西路453弄3号", "芷江西路453弄", false, 121.45280},
+		{"上海市浦东新区微山路120号", "微山路", false, 121.50859},
+		//	写法略有不同
+		{"上海市浦东新区微山新村小区", "微山新村", false, 121.50921},
+	}
+	for _, c := range testcases {
+		t.Run(c.addr, func(t *testing.T) {
+			a, err := g.Geocode(c.addr)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Contains(t, a.Address, c.name)
+			assert.Equal(t, c.longitude, a.Longitude)
+			if c.name == "微山路" {
+				assert.Equal(t, PrecisionRoad, a.Precision)
+			} else {
+				assert.Equal(t, PrecisionPOI, a.Precision)
+			}
+			if c.exact {
+				assert.Equal(t, 1.0, a.Confidence)
+			} else {
+				assert.Less(t, a.Confidence, 1.0)
+				assert.GreaterOrEqual(t, a.Confidence, GAZETTEER_MIN_SCORE)
+			}
+		})
+	}
+
+	//	没有匹配
+	_, err = g.Geocode("上海市静安区愚园路1000号")
+	assert.Error(t, err)
+	_, err = g.Geocode("北京市朝阳区芷江西路453弄")
+	assert.Error(t, err)
+
+	//	批量
+	as, err := g.GeocodeInBatch([]string{"上海市静安区芷江西路453弄", "上海市静安区愚园路1000号"})
+	assert.NoError(t, err)
+	if assert.Len(t, as, 2) {
+		assert.Equal(t, 121.45280, as[0].Longitude)
+		assert.Zero(t, as[1].Longitude)
+	}
+}
+
+func TestGazetteer_GeoJSON(t *testing.T) {
+	g, err := NewGeocoderGazetteer("testdata/gazetteer.geojson")
+	if !assert.NoError(t, err) {
+		return
+	}
+	a, err := g.Geocode("北京市通州区永顺镇馨通家园")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 116.65623, a.Longitude)
+		assert.Equal(t, 39.90988, a.Latitude)
+	}
+
+	_, err = NewGeocoderGazetteer("testdata/nosuchfile.csv")
+	assert.Error(t, err)
+}
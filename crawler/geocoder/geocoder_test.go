@@ -22,9 +22,9 @@ const THRESHOLD = 0.01
 
 func TestGeocoder(t *testing.T) {
 	testcases := []Address{
-		{"上海市静安区芷江西路453弄", 121.45280, 31.25884}, // 31.25884,121.45280
-		{"上海市浦东新区微山路", 121.50859, 31.21077},
-		{"山东省青岛市胶州市皓月路", 120.02190, 36.27371},
+		{Address: "上海市静安区芷江西路453弄", Longitude: 121.45280, Latitude: 31.25884}, // 31.25884,121.45280
+		{Address: "上海市浦东新区微山路", Longitude: 121.50859, Latitude: 31.21077},
+		{Address: "山东省青岛市胶州市皓月路", Longitude: 120.02190, Latitude: 36.27371},
 	}
 	addrs := []string{}
 	for _, c := range testcases {
@@ -103,7 +103,13 @@ func (a GeocoderAPITianditu) Request(addr string) (*Address, error) {
 	//	天地图的坐标系接近 WGS84，所以不进行转换
 
 	//	返回
-	return &Address{Address: r.Location.Keyword, Longitude: r.Location.Lon, Latitude: r.Location.Lat}, nil
+	return &Address{
+		Address:    r.Location.Keyword,
+		Longitude:  r.Location.Lon,
+		Latitude:   r.Location.Lat,
+		Precision:  ParsePrecision(r.Location.Level),
+		Confidence: float64(r.Location.Score) / 100,
+	}, nil
 }
 
 // 天地图没有批处理API，因此对单次请求进行封装
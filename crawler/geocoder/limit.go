@@ -0,0 +1,80 @@
+package geocoder
+
+import (
+	"sync"
+	"time"
+)
+
+// 简单的限速器，多个 goroutine 共用时按先后顺序排队
+type rateLimiter struct {
+	lock     sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(qps float64) *rateLimiter {
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / qps)}
+}
+
+// 等待到可以发出下一个请求
+func (l *rateLimiter) wait() {
+	l.lock.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.lock.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// 限制每秒请求数的服务
+//
+//	有批量接口的服务，一次批量请求只计一次；没有的，逐个地址限速
+type GeocoderAPILimited struct {
+	api     GeocoderAPI
+	limiter *rateLimiter
+}
+
+// 限制每秒请求数，qps 不大于 0 时不限速
+func (g Geocoder) WithRateLimit(qps float64) Geocoder {
+	if qps <= 0 {
+		return g
+	}
+	g.api = GeocoderAPILimited{api: g.api, limiter: newRateLimiter(qps)}
+	return g
+}
+
+func (a GeocoderAPILimited) Name() string {
+	return a.api.Name()
+}
+
+func (a GeocoderAPILimited) Request(addr string) (*Address, error) {
+	a.limiter.wait()
+	return a.api.Request(addr)
+}
+
+func (a GeocoderAPILimited) RequestBatch(addrs []string) ([]Address, error) {
+	if supportsBatch(a.api) {
+		a.limiter.wait()
+		return a.api.RequestBatch(addrs)
+	}
+	result := make([]Address, 0, len(addrs))
+	for _, addr := range addrs {
+		if r, err := a.Request(addr); err == nil {
+			result = append(result, *r)
+		} else {
+			//	无法解析，添加坐标为0的地址
+			result = append(result, Address{Address: addr})
+		}
+	}
+	return result, nil
+}
+
+func (a GeocoderAPILimited) supportsBatch() bool {
+	return supportsBatch(a.api)
+}
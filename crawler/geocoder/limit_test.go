@@ -0,0 +1,67 @@
+package geocoder
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeocoder_WithRateLimit(t *testing.T) {
+	stub := &geocoderAPIStub{name: "stub", known: map[string]Address{"a": {Longitude: 1, Latitude: 1}}}
+	g := Geocoder{api: stub}.WithRateLimit(50)
+	assert.Equal(t, "stub", g.Name())
+	assert.False(t, g.SupportsBatch())
+
+	//	多个 goroutine 共用同一个限速器：11 个请求至少需要 10 个间隔
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	begin := time.Now()
+	for i := 0; i < 11; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock.Lock()
+			defer lock.Unlock()
+			_, err := g.api.Request("a")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.GreaterOrEqual(t, time.Since(begin), 10*20*time.Millisecond-time.Millisecond)
+	assert.Equal(t, 11, stub.calls)
+
+	//	不限速
+	assert.Equal(t, Geocoder{api: stub}, Geocoder{api: stub}.WithRateLimit(0))
+
+	//	有批量接口的服务保持批量
+	assert.True(t, Geocoder{api: GeocoderAPIBaidu{}}.WithRateLimit(10).SupportsBatch())
+	assert.True(t, NewGeocoderChain("", Geocoder{api: GeocoderAPIAmap{}}.WithRateLimit(10), Geocoder{api: stub}).SupportsBatch())
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	cache, err := NewGeocodeCache(path.Join(t.TempDir(), "cache"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer cache.db.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				addr := fmt.Sprintf("地址%d", j)
+				assert.NoError(t, cache.Put(addr, Address{Longitude: float64(j), Latitude: float64(j), Provider: "stub"}))
+				if a, err := cache.Get(addr); assert.NoError(t, err) {
+					assert.Equal(t, float64(j), a.Longitude)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
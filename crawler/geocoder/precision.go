@@ -0,0 +1,39 @@
+package geocoder
+
+import "strings"
+
+// 坐标精度，由各服务返回的解析级别归一化而来
+type Precision string
+
+const (
+	PrecisionUnknown  Precision = ""
+	PrecisionDistrict Precision = "区县" // 区县及以上，坐标只是行政区的中心点
+	PrecisionTown     Precision = "乡镇" // 乡镇、街道、村庄
+	PrecisionRoad     Precision = "道路"
+	PrecisionPOI      Precision = "兴趣点" // 小区、商圈、站点等
+	PrecisionAddress  Precision = "门址"
+)
+
+// 将高德地图的 level、百度地图的 level、天地图的 level 归一化为坐标精度
+//
+//	高德：国家、省、市、区县、开发区、乡镇、村庄、热点商圈、兴趣点、门牌号、单元号、道路、道路交叉路口、公交站台、地铁站、未知
+//	百度：门址、道路、道路交叉口?、区县、乡镇、城市、省份、村庄、商圈、地产小区、购物、酒店……、UNKNOWN
+//	天地图：行政区划、地名地址、道路、POI 等
+func ParsePrecision(level string) Precision {
+	level = strings.TrimSpace(level)
+	switch level {
+	case "", "未知", "UNKNOWN":
+		return PrecisionUnknown
+	case "国家", "省", "省份", "市", "城市", "区县", "行政区划":
+		return PrecisionDistrict
+	case "开发区", "乡镇", "村庄":
+		return PrecisionTown
+	case "门牌号", "单元号", "门址", "地名地址":
+		return PrecisionAddress
+	}
+	if strings.HasPrefix(level, "道路") {
+		return PrecisionRoad
+	}
+	//	其余都是兴趣点的各种类别
+	return PrecisionPOI
+}
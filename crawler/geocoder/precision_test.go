@@ -0,0 +1,49 @@
+package geocoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePrecision(t *testing.T) {
+	tests := []struct {
+		level string
+		want  Precision
+	}{
+		{"门牌号", PrecisionAddress},
+		{"门址", PrecisionAddress},
+		{"地名地址", PrecisionAddress},
+		{"道路", PrecisionRoad},
+		{"道路交叉路口", PrecisionRoad},
+		{"兴趣点", PrecisionPOI},
+		{"地产小区", PrecisionPOI},
+		{"乡镇", PrecisionTown},
+		{"区县", PrecisionDistrict},
+		{"城市", PrecisionDistrict},
+		{"UNKNOWN", PrecisionUnknown},
+		{"", PrecisionUnknown},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ParsePrecision(tt.level), tt.level)
+	}
+}
+
+func TestGeocoderAPIBaiduResponse_Quality(t *testing.T) {
+	var r GeocoderAPIBaiduResponse
+	r.Result.Precise = 1
+	r.Result.Confidence = 80
+	r.Result.Comprehension = 100
+	r.Result.Level = "门址"
+	p, c := r.quality()
+	assert.Equal(t, PrecisionAddress, p)
+	assert.Equal(t, 0.8, c)
+
+	//	模糊打点
+	r.Result.Precise = 0
+	r.Result.Comprehension = 60
+	r.Result.Level = "区县"
+	p, c = r.quality()
+	assert.Equal(t, PrecisionDistrict, p)
+	assert.Equal(t, 0.3, c)
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 某日疫情防控新闻发布会中通报的数据
+//
+//	这些数据通常不出现在每日疫情通报中，没有提到的字段为 0 或空
+type Briefing struct {
+	Date   time.Time // 发布会日期
+	City   string    // 城市
+	Title  string    // 标题
+	Source string    // 链接
+
+	Deaths                int      // 新增死亡
+	DeathAgeAverage       float64  // 死亡病例平均年龄
+	DeathAgeMin           int      // 死亡病例最小年龄
+	DeathAgeMax           int      // 死亡病例最大年龄
+	DeathsWithComorbidity int      // 合并基础疾病的死亡病例
+	Comorbidities         []string // 提到的基础疾病，如 冠心病、高血压
+
+	ShelterHospitals int // 方舱医院（个）
+	ShelterBeds      int // 方舱医院床位（张）
+
+	NucleicAcidTests int // 核酸检测（人次或管）
+	AntigenTests     int // 抗原检测（人次或份）
+}
+
+func (b Briefing) Key() string {
+	return b.Date.Format("2006-01-02")
+}
+
+func (b Briefing) String() string {
+	return fmt.Sprintf("[%s] 死亡: %d, 方舱: %d 个 %d 床, 核酸: %d, 抗原: %d",
+		b.Date.Format("2006-01-02"),
+		b.Deaths,
+		b.ShelterHospitals,
+		b.ShelterBeds,
+		b.NucleicAcidTests,
+		b.AntigenTests,
+	)
+}
+
+type Briefings []Briefing
+
+func (bs Briefings) Sort() {
+	sort.SliceStable(bs, func(i, j int) bool {
+		return bs[i].Date.After(bs[j].Date)
+	})
+}
+
+func (bs Briefings) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"日期", "市", "新增死亡", "平均年龄", "最小年龄", "最大年龄", "合并基础疾病", "基础疾病", "方舱医院", "方舱床位", "核酸检测", "抗原检测", "标题", "来源"},
+	}
+	for _, b := range bs {
+		records = append(records, []string{
+			b.Date.Format("2006-01-02"),
+			b.City,
+			strconv.Itoa(b.Deaths),
+			strconv.FormatFloat(b.DeathAgeAverage, 'f', -1, 64),
+			strconv.Itoa(b.DeathAgeMin),
+			strconv.Itoa(b.DeathAgeMax),
+			strconv.Itoa(b.DeathsWithComorbidity),
+			strings.Join(b.Comorbidities, "、"),
+			strconv.Itoa(b.ShelterHospitals),
+			strconv.Itoa(b.ShelterBeds),
+			strconv.Itoa(b.NucleicAcidTests),
+			strconv.Itoa(b.AntigenTests),
+			b.Title,
+			b.Source,
+		})
+	}
+	return SaveToCSV(filename, records)
+}
+
+func (bs Briefings) SaveToJSON(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	e := json.NewEncoder(f)
+	e.SetIndent("", "  ")
+	return e.Encode(bs)
+}
+
+func (bs *Briefings) LoadFromJSON(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d := json.NewDecoder(f)
+	return d.Decode(&bs)
+}
@@ -0,0 +1,207 @@
+package model
+
+import (
+	"encoding/xml"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 传播链中节点的类型
+type ChainNodeType string
+
+const (
+	ChainCase    ChainNodeType = "病例"
+	ChainCluster ChainNodeType = "聚集性疫情"
+	ChainVenue   ChainNodeType = "场所"
+)
+
+// 传播链中的一条边：病例 To 由 From 传染，或与聚集性疫情、场所 From 有关联
+type ChainEdge struct {
+	Date     time.Time     // 病例 To 的日期
+	City     string        // 城市
+	From     string        // 传染源病例的 Key，或聚集性疫情、场所的名称
+	FromType ChainNodeType // From 的类型
+	To       string        // 病例的 Key
+}
+
+// 边的起点在图中的 ID，不同类型的同名节点不会混在一起
+func (e ChainEdge) FromID() string {
+	if e.FromType == ChainCase {
+		return e.From
+	}
+	return string(e.FromType) + ":" + e.From
+}
+
+type ChainEdges []ChainEdge
+
+// 由居住地信息中的传染源、聚集性疫情和暴露场所生成传播链
+//
+//	传染源没有注明日期时，优先关联同一天通报的同号病例
+func (rs Residents) ChainEdges() ChainEdges {
+	keys := make(map[string]bool, len(rs))
+	for _, r := range rs {
+		keys[r.Key()] = true
+	}
+	var es ChainEdges
+	for _, r := range rs {
+		e := ChainEdge{Date: r.Date, City: r.City, To: r.Key()}
+		if len(r.Infector) > 0 {
+			e.From, e.FromType = r.Infector, ChainCase
+			if k := r.Date.Format("2006-01-02") + "." + r.Infector; !strings.Contains(r.Infector, ".") && keys[k] {
+				e.From = k
+			}
+			es = append(es, e)
+		}
+		if len(r.Cluster) > 0 {
+			e.From, e.FromType = r.Cluster, ChainCluster
+			es = append(es, e)
+		}
+		if len(r.Venue) > 0 {
+			e.From, e.FromType = r.Venue, ChainVenue
+			es = append(es, e)
+		}
+	}
+	es.Sort()
+	return es
+}
+
+func (es ChainEdges) Sort() {
+	sort.SliceStable(es, func(i, j int) bool {
+		l, r := es[i], es[j]
+		if !l.Date.Equal(r.Date) {
+			return l.Date.After(r.Date)
+		}
+		if l.To != r.To {
+			return strings.Compare(l.To, r.To) < 0
+		}
+		return strings.Compare(l.FromID(), r.FromID()) < 0
+	})
+}
+
+// 保存为边列表
+func (es ChainEdges) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"日期", "市", "来源", "来源类型", "病例"},
+	}
+	for _, e := range es {
+		records = append(records, []string{
+			e.Date.Format("2006-01-02"),
+			e.City,
+			e.From,
+			string(e.FromType),
+			e.To,
+		})
+	}
+	return SaveToCSV(filename, records)
+}
+
+//	GraphML
+//
+//	节点属性 type 为节点类型，label 为显示名称；边属性 date 为病例日期。
+//	可以直接用 Gephi、Cytoscape 等工具打开。
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// 保存为 GraphML 有向图
+func (es ChainEdges) SaveToGraphML(filename string) error {
+	g := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "type", For: "node", Name: "type", Type: "string"},
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "date", For: "edge", Name: "date", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: "chains", EdgeDefault: "directed"},
+	}
+
+	nodes := make(map[string]bool)
+	addNode := func(id, label string, t ChainNodeType) {
+		if nodes[id] {
+			return
+		}
+		nodes[id] = true
+		g.Graph.Nodes = append(g.Graph.Nodes, graphMLNode{
+			ID: id,
+			Data: []graphMLData{
+				{Key: "type", Value: string(t)},
+				{Key: "label", Value: label},
+			},
+		})
+	}
+	for _, e := range es {
+		addNode(e.FromID(), e.From, e.FromType)
+		addNode(e.To, e.To, ChainCase)
+		g.Graph.Edges = append(g.Graph.Edges, graphMLEdge{
+			Source: e.FromID(),
+			Target: e.To,
+			Data:   []graphMLData{{Key: "date", Value: e.Date.Format("2006-01-02")}},
+		})
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(f)
+	e.Indent("", "  ")
+	return e.Encode(g)
+}
+
+// 旧数据中没有传播关系的居住地信息，用新抓取的同一病例补上
+func (rs Residents) MergeLinkage(fresh Residents) {
+	index := make(map[string]Resident, len(fresh))
+	for _, r := range fresh {
+		index[r.Key()] = r
+	}
+	for i, r := range rs {
+		if len(r.Infector) > 0 || len(r.Cluster) > 0 || len(r.Venue) > 0 {
+			continue
+		}
+		if f, ok := index[r.Key()]; ok {
+			rs[i].Infector = f.Infector
+			rs[i].Cluster = f.Cluster
+			rs[i].Venue = f.Venue
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//	聚集性疫情
+//
+//	通报中点名的聚集性疫情（居住地信息的 Cluster，没有时取暴露场所 Venue）按名称归并；
+//	其余病例按区和居住地归并，同一居住地相邻两例相隔不超过 CLUSTER_ADDRESS_WINDOW 时视为同一起。
+//	上海的居住地信息没有病例号，每条计为一例。
+
+// 同一居住地的病例，相邻两例相隔不超过这个时间时归为同一起聚集性疫情
+const CLUSTER_ADDRESS_WINDOW = 7 * 24 * time.Hour
+
+// 按居住地归并的聚集性疫情至少需要的病例数
+const CLUSTER_MIN_SIZE = 2
+
+// 最后一例之后超过这个时间没有新增，视为聚集性疫情已结束
+const CLUSTER_ACTIVE_WINDOW = 14 * 24 * time.Hour
+
+// 聚集性疫情某日的新增病例
+type ClusterDay struct {
+	Date  time.Time // 日期
+	Count int       // 当日新增
+	Total int       // 截至当日累计
+}
+
+// 一起聚集性疫情
+type Cluster struct {
+	City      string       // 城市
+	Name      string       // 名称，按居住地归并时为 区+居住地
+	Named     bool         // 名称是否来自通报
+	First     time.Time    // 首例日期
+	Last      time.Time    // 末例日期
+	Size      int          // 病例数
+	Districts []string     // 涉及的区
+	Timeline  []ClusterDay // 每日新增
+	Cases     []string     // 病例的 Key
+}
+
+func (c Cluster) Key() string {
+	return fmt.Sprintf("%s.%s.%s", c.First.Format("2006-01-02"), c.City, c.Name)
+}
+
+func (c Cluster) String() string {
+	return fmt.Sprintf("[%s ~ %s] %s%s: %d 例，涉及 %s",
+		c.First.Format("2006-01-02"),
+		c.Last.Format("2006-01-02"),
+		c.City,
+		c.Name,
+		c.Size,
+		strings.Join(c.Districts, "、"),
+	)
+}
+
+// 截至 date 是否仍在活跃：已出现首例，且末例距 date 不超过 CLUSTER_ACTIVE_WINDOW
+func (c Cluster) ActiveOn(date time.Time) bool {
+	return !date.Before(c.First) && date.Sub(c.Last) <= CLUSTER_ACTIVE_WINDOW
+}
+
+// 截至 date 的病例数
+func (c Cluster) SizeOn(date time.Time) int {
+	n := 0
+	for _, d := range c.Timeline {
+		if d.Date.After(date) {
+			break
+		}
+		n = d.Total
+	}
+	return n
+}
+
+func newCluster(city, name string, named bool, rs Residents) Cluster {
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].Date.Before(rs[j].Date) })
+	c := Cluster{City: city, Name: name, Named: named, First: rs[0].Date, Last: rs[len(rs)-1].Date, Size: len(rs)}
+	districts := make(map[string]bool)
+	for _, r := range rs {
+		if len(r.District) > 0 && !districts[r.District] {
+			districts[r.District] = true
+			c.Districts = append(c.Districts, r.District)
+		}
+		if n := len(c.Timeline); n > 0 && c.Timeline[n-1].Date.Equal(r.Date) {
+			c.Timeline[n-1].Count++
+			c.Timeline[n-1].Total++
+		} else {
+			c.Timeline = append(c.Timeline, ClusterDay{Date: r.Date, Count: 1, Total: len(c.Cases) + 1})
+		}
+		c.Cases = append(c.Cases, r.Key())
+	}
+	sort.Strings(c.Districts)
+	return c
+}
+
+type Clusters []Cluster
+
+// 由居住地信息归并出聚集性疫情
+func (rs Residents) Clusters() Clusters {
+	named := make(map[string]Residents)
+	addresses := make(map[string]Residents)
+	var named_keys, address_keys []string
+	for _, r := range rs {
+		name := r.Cluster
+		if len(name) == 0 {
+			name = r.Venue
+		}
+		if len(name) > 0 {
+			k := r.City + "." + name
+			if _, ok := named[k]; !ok {
+				named_keys = append(named_keys, k)
+			}
+			named[k] = append(named[k], r)
+			continue
+		}
+		if len(r.District) == 0 || len(r.Address) == 0 {
+			continue
+		}
+		k := r.City + "." + r.District + "." + r.Address
+		if _, ok := addresses[k]; !ok {
+			address_keys = append(address_keys, k)
+		}
+		addresses[k] = append(addresses[k], r)
+	}
+
+	var cs Clusters
+	for _, k := range named_keys {
+		list := named[k]
+		name := list[0].Cluster
+		if len(name) == 0 {
+			name = list[0].Venue
+		}
+		cs = append(cs, newCluster(list[0].City, name, true, list))
+	}
+	for _, k := range address_keys {
+		list := addresses[k]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Date.Before(list[j].Date) })
+		//	相隔超过时间窗口的病例，分为不同的聚集性疫情
+		begin := 0
+		for i := 1; i <= len(list); i++ {
+			if i < len(list) && list[i].Date.Sub(list[i-1].Date) <= CLUSTER_ADDRESS_WINDOW {
+				continue
+			}
+			if i-begin >= CLUSTER_MIN_SIZE {
+				r := list[begin]
+				cs = append(cs, newCluster(r.City, r.District+r.Address, false, list[begin:i]))
+			}
+			begin = i
+		}
+	}
+	cs.Sort()
+	return cs
+}
+
+// 截至 date 仍在活跃的聚集性疫情
+func (cs Clusters) Active(date time.Time) Clusters {
+	var result Clusters
+	for _, c := range cs {
+		if c.ActiveOn(date) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func (cs Clusters) Sort() {
+	sort.SliceStable(cs, func(i, j int) bool {
+		l, r := cs[i], cs[j]
+		if !l.Last.Equal(r.Last) {
+			return l.Last.After(r.Last)
+		}
+		if l.Size != r.Size {
+			return l.Size > r.Size
+		}
+		return strings.Compare(l.Name, r.Name) < 0
+	})
+}
+
+func (cs Clusters) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"市", "聚集性疫情", "通报命名", "首例日期", "末例日期", "病例数", "涉及区", "每日新增"},
+	}
+	for _, c := range cs {
+		var days []string
+		for _, d := range c.Timeline {
+			days = append(days, fmt.Sprintf("%s:%d", d.Date.Format("2006-01-02"), d.Count))
+		}
+		records = append(records, []string{
+			c.City,
+			c.Name,
+			strconv.FormatBool(c.Named),
+			c.First.Format("2006-01-02"),
+			c.Last.Format("2006-01-02"),
+			strconv.Itoa(c.Size),
+			strings.Join(c.Districts, "、"),
+			strings.Join(days, ";"),
+		})
+	}
+	return SaveToCSV(filename, records)
+}
+
+func (cs Clusters) SaveToJSON(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	e := json.NewEncoder(f)
+	e.SetIndent("", "  ")
+	return e.Encode(cs)
+}
+
+func (cs *Clusters) LoadFromJSON(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d := json.NewDecoder(f)
+	return d.Decode(&cs)
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 通报中描述的一个死亡病例
+type DeathCase struct {
+	Date       time.Time // 通报日期
+	City       string    // 城市
+	Number     int       // 通报中的编号，如 死亡病例3
+	Gender     string    // 性别
+	Age        int       // 年龄
+	Conditions []string  // 基础疾病
+	Source     string    // 来源
+}
+
+func (c DeathCase) Key() string {
+	return fmt.Sprintf("%s.%d", c.Date.Format("2006-01-02"), c.Number)
+}
+
+func (c DeathCase) String() string {
+	return fmt.Sprintf("[%s] 死亡病例%d: %s, %d, %s", c.Date.Format("2006-01-02"), c.Number, c.Gender, c.Age, strings.Join(c.Conditions, "、"))
+}
+
+type DeathCases []DeathCase
+
+// 所有日期的死亡病例，按日期、编号排序
+func (cs Dailys) DeathCases() DeathCases {
+	var dcs DeathCases
+	for _, d := range cs {
+		dcs = append(dcs, d.DeathCases...)
+	}
+	sort.SliceStable(dcs, func(i, j int) bool {
+		if !dcs[i].Date.Equal(dcs[j].Date) {
+			return dcs[i].Date.After(dcs[j].Date)
+		}
+		return dcs[i].Number < dcs[j].Number
+	})
+	return dcs
+}
+
+// 将新抓取数据中的死亡病例补充到旧数据中没有死亡病例的记录里
+func (cs Dailys) MergeDeathCases(fresh Dailys) {
+	index := make(map[string]Daily, len(fresh))
+	for _, d := range fresh {
+		index[d.Key()] = d
+	}
+	for i, od := range cs {
+		if fd, ok := index[od.Key()]; ok && len(od.DeathCases) == 0 && len(fd.DeathCases) > 0 {
+			cs[i].DeathCases = fd.DeathCases
+		}
+	}
+}
+
+func (dcs DeathCases) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"日期", "市", "编号", "性别", "年龄", "基础疾病", "来源"},
+	}
+	for _, c := range dcs {
+		records = append(records, []string{
+			c.Date.Format("2006-01-02"),
+			c.City,
+			strconv.Itoa(c.Number),
+			c.Gender,
+			strconv.Itoa(c.Age),
+			strings.Join(c.Conditions, "、"),
+			c.Source,
+		})
+	}
+	return SaveToCSV(filename, records)
+}
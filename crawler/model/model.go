@@ -71,7 +71,7 @@ type Daily struct {
 	TotalImportedConfirmed              int // 累计境外输入确诊病例
 	TotalImportedDischargedFromHospital int // 累计境外输入治愈出院
 
-	//	分区
+	//	分区（全国数据按省份）
 	DistrictPositive                  map[string]int // 城区阳性感染者
 	DistrictPositiveFromBubble        map[string]int // 城区从闭环隔离中发现阳性感染者
 	DistrictPositiveFromRisk          map[string]int // 城区从风险人群中发现阳性感染者
@@ -83,8 +83,12 @@ type Daily struct {
 	DistrictAsymptomaticFromBubble    map[string]int // 城区从闭环隔离中发现无症状感染者
 	DistrictAsymptomaticFromRisk      map[string]int // 城区从风险人群中发现无症状感染者
 
+	//	死亡病例详情
+	DeathCases DeathCases `json:",omitempty"` // 通报中描述的每个死亡病例
+
 	// meta
-	Source string // 来源
+	Source     string      // 来源
+	Provenance Provenances `json:",omitempty"` // 各字段值的来源
 }
 
 func (d Daily) Key() string {
@@ -321,6 +325,17 @@ func (cs Dailys) Find(d time.Time) *Daily {
 	return nil
 }
 
+// 最新一天的日期，如无数据则返回零值
+func (cs Dailys) Latest() time.Time {
+	var latest time.Time
+	for _, c := range cs {
+		if c.Date.After(latest) {
+			latest = c.Date
+		}
+	}
+	return latest
+}
+
 func (cs *Dailys) Add(c Daily) {
 	//	TODO: 修改为 buffered channel方式
 	lockDailys.Lock()
@@ -335,16 +350,24 @@ func (cs *Dailys) Sort() {
 }
 
 type Resident struct {
-	Date      time.Time // 日期
-	Name      string    // 病例号
-	Type      string    // 分型 （无症状感染者、轻型、普通型、重型、危重型）
-	Gender    string    // 性别
-	Age       float64   // 年龄
-	City      string    // 城市
-	District  string    // 区
-	Address   string    // 居住地
-	Longitude float64   // 经度
-	Latitude  float64   // 纬度
+	Date        time.Time // 日期
+	Name        string    // 病例号
+	Type        string    // 分型 （无症状感染者、轻型、普通型、重型、危重型）
+	Gender      string    // 性别
+	Age         float64   // 年龄
+	City        string    // 城市
+	District    string    // 区
+	Street      string    // 街道/乡镇，通报中没有时为空
+	Address     string    // 居住地
+	Zone        ZoneTier  // 所在小区当日的封控分级，不在名单中时为空
+	Infector    string    // 传染源病例，通报中注明日期时为 2022-04-23.确诊病例5，否则只有病例号
+	Cluster     string    // 关联的聚集性疫情
+	Venue       string    // 暴露场所
+	Longitude   float64   // 经度
+	Latitude    float64   // 纬度
+	Precision   string    // 坐标精度（区县、乡镇、道路、兴趣点、门址），未知时为空
+	Confidence  float64   // 坐标可信度（0~1），未知时为 0
+	GeoDistrict string    // 坐标实际所在的区，与 District 不一致时才有值，不在任何区内时为“市外”
 }
 
 func (r Resident) Key() string {
@@ -379,9 +402,17 @@ func (rs Residents) SaveToCSV(filename string) error {
 		"年龄",
 		"市",
 		"区",
+		"街道",
 		"居住地",
+		"封控分级",
+		"传染源",
+		"聚集性疫情",
+		"暴露场所",
 		"经度",
 		"纬度",
+		"坐标精度",
+		"坐标可信度",
+		"坐标所在区",
 	}
 
 	records = append(records, header)
@@ -395,9 +426,17 @@ func (rs Residents) SaveToCSV(filename string) error {
 			strconv.FormatFloat(r.Age, 'f', 0, 32),
 			r.City,
 			r.District,
+			r.Street,
 			r.Address,
+			string(r.Zone),
+			r.Infector,
+			r.Cluster,
+			r.Venue,
 			strconv.FormatFloat(r.Longitude, 'f', -1, 64),
 			strconv.FormatFloat(r.Latitude, 'f', -1, 64),
+			r.Precision,
+			strconv.FormatFloat(r.Confidence, 'f', -1, 64),
+			r.GeoDistrict,
 		}
 		records = append(records, rec)
 	}
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// 字段值的来源类型
+type ProvenanceKind string
+
+const (
+	ProvenanceParsed    ProvenanceKind = "parsed"    // 从通报标题或内容中解析
+	ProvenanceDerived   ProvenanceKind = "derived"   // 由其它字段计算得到
+	ProvenanceResidents ProvenanceKind = "residents" // 由居住地信息推算
+)
+
+// 保存的匹配文本最大长度
+const PROVENANCE_SNIPPET_MAX = 200
+
+// 字段值的来源
+type Provenance struct {
+	Kind      ProvenanceKind // 来源类型
+	Extractor string         // 提取器，如正则表达式变量名 reDailyLocalConfirmed，或 FixDaily
+	Snippet   string         // 匹配到的文本，或计算公式
+}
+
+func (p Provenance) String() string {
+	return fmt.Sprintf("%s (%s): %q", p.Kind, p.Extractor, p.Snippet)
+}
+
+// 字段名 => 来源
+type Provenances map[string]Provenance
+
+// 开始记录字段来源
+//
+//	Provenance 为 nil 时不记录，因此只有需要的调用方（如爬虫）才会产生来源数据
+func (d *Daily) EnableProvenance() {
+	if d.Provenance == nil {
+		d.Provenance = make(Provenances)
+	}
+}
+
+func (d *Daily) trace(field string, kind ProvenanceKind, extractor, snippet string) {
+	if d.Provenance == nil {
+		return
+	}
+	snippet = strings.TrimSpace(snippet)
+	if rs := []rune(snippet); len(rs) > PROVENANCE_SNIPPET_MAX {
+		snippet = string(rs[:PROVENANCE_SNIPPET_MAX]) + "…"
+	}
+	d.Provenance[field] = Provenance{Kind: kind, Extractor: extractor, Snippet: snippet}
+}
+
+// 记录从通报中解析出的字段
+func (d *Daily) Parsed(field, extractor, snippet string) {
+	d.trace(field, ProvenanceParsed, extractor, snippet)
+}
+
+// 记录由其它字段计算得到的字段
+func (d *Daily) Derived(field, extractor, formula string) {
+	d.trace(field, ProvenanceDerived, extractor, formula)
+}
+
+// 记录由居住地信息推算的字段
+func (d *Daily) Inferred(field, extractor, snippet string) {
+	d.trace(field, ProvenanceResidents, extractor, snippet)
+}
+
+// 字段的值，字段不存在时返回 false
+func (d Daily) Field(name string) (interface{}, bool) {
+	f := reflect.ValueOf(d).FieldByName(name)
+	if !f.IsValid() || name == "Provenance" {
+		return nil, false
+	}
+	return f.Interface(), true
+}
+
+// 有值的字段名（数值不为 0、分区不为空），按结构体中的顺序
+func (d Daily) PopulatedFields() []string {
+	var fields []string
+	v := reflect.ValueOf(d)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Int:
+			if f.Int() != 0 {
+				fields = append(fields, t.Field(i).Name)
+			}
+		case reflect.Map:
+			if f.Len() > 0 && t.Field(i).Name != "Provenance" {
+				fields = append(fields, t.Field(i).Name)
+			}
+		}
+	}
+	return fields
+}
+
+// 将新抓取数据中的字段来源合并到旧数据中
+//
+//	只有除来源外完全一致的记录才会合并，这样重新抓取后旧数据也能有来源信息
+func (cs Dailys) MergeProvenance(fresh Dailys) {
+	index := make(map[string]Daily, len(fresh))
+	for _, d := range fresh {
+		index[d.Key()] = d
+	}
+	for i, od := range cs {
+		fd, ok := index[od.Key()]
+		if !ok || len(fd.Provenance) == 0 {
+			continue
+		}
+		o, f := od, fd
+		o.Provenance, f.Provenance = nil, nil
+		if reflect.DeepEqual(o, f) {
+			cs[i].Provenance = fd.Provenance
+		}
+	}
+}
+
+// 按字段名排序的来源列表
+func (ps Provenances) Fields() []string {
+	fields := make([]string, 0, len(ps))
+	for k := range ps {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 同一天两个来源的数据中，某个字段的不一致
+type Conflict struct {
+	Key         string // 日期
+	Field       string // 字段名，分区字段为 DistrictPositive[浦东新区] 的形式
+	Left        string // 左侧来源的值
+	Right       string // 右侧来源的值
+	LeftSource  string // 左侧来源链接
+	RightSource string // 右侧来源链接
+}
+
+type Conflicts []Conflict
+
+// 某一天只有一个来源有数据时，Field 为 CONFLICT_FIELD_DATE，两侧的值分别为有数据或缺失
+const (
+	CONFLICT_FIELD_DATE = "Date"
+	CONFLICT_PRESENT    = "有数据"
+	CONFLICT_MISSING    = "缺失"
+)
+
+// 对比两个来源的 Daily 数据，按 Key() 对齐后逐字段列出不一致之处
+//
+//	fields 为空时对比全部字段；skip_zero 为 true 时，任一方为 0 的字段不视为冲突，
+//	适用于两个来源覆盖的字段不同的情况（如城市通报与全国通报）。
+//	只有一个来源有数据的日期也列为冲突，以便发现缺失的通报
+func ReconcileDailys(left, right Dailys, fields []string, skip_zero bool) Conflicts {
+	left_index := make(map[string]Daily, len(left))
+	for _, d := range left {
+		left_index[d.Key()] = d
+	}
+	right_index := make(map[string]Daily, len(right))
+	for _, d := range right {
+		right_index[d.Key()] = d
+	}
+	only := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		only[f] = true
+	}
+
+	var cs Conflicts
+	for _, l := range left {
+		r, ok := right_index[l.Key()]
+		if !ok {
+			cs = append(cs, Conflict{Key: l.Key(), Field: CONFLICT_FIELD_DATE, Left: CONFLICT_PRESENT, Right: CONFLICT_MISSING, LeftSource: l.Source})
+			continue
+		}
+		cs = append(cs, reconcileDaily(l, r, only, skip_zero)...)
+	}
+	for _, r := range right {
+		if _, ok := left_index[r.Key()]; !ok {
+			cs = append(cs, Conflict{Key: r.Key(), Field: CONFLICT_FIELD_DATE, Left: CONFLICT_MISSING, Right: CONFLICT_PRESENT, RightSource: r.Source})
+		}
+	}
+
+	sort.SliceStable(cs, func(i, j int) bool {
+		if cs[i].Key != cs[j].Key {
+			return cs[i].Key < cs[j].Key
+		}
+		return cs[i].Field < cs[j].Field
+	})
+	return cs
+}
+
+func reconcileDaily(l, r Daily, only map[string]bool, skip_zero bool) Conflicts {
+	var cs Conflicts
+	add := func(field string, lv, rv int) {
+		if lv == rv || (skip_zero && (lv == 0 || rv == 0)) {
+			return
+		}
+		cs = append(cs, Conflict{
+			Key:         l.Key(),
+			Field:       field,
+			Left:        strconv.Itoa(lv),
+			Right:       strconv.Itoa(rv),
+			LeftSource:  l.Source,
+			RightSource: r.Source,
+		})
+	}
+
+	lv := reflect.ValueOf(l)
+	rv := reflect.ValueOf(r)
+	t := lv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if len(only) > 0 && !only[name] {
+			continue
+		}
+		switch t.Field(i).Type.Kind() {
+		case reflect.Int:
+			add(name, int(lv.Field(i).Int()), int(rv.Field(i).Int()))
+		case reflect.Map:
+			lm, _ := lv.Field(i).Interface().(map[string]int)
+			rm, _ := rv.Field(i).Interface().(map[string]int)
+			keys := make(map[string]bool)
+			for k := range lm {
+				keys[k] = true
+			}
+			for k := range rm {
+				keys[k] = true
+			}
+			for k := range keys {
+				add(fmt.Sprintf("%s[%s]", name, k), lm[k], rm[k])
+			}
+		}
+	}
+	return cs
+}
+
+func (cs Conflicts) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"日期", "字段", "左侧", "右侧", "左侧来源", "右侧来源"},
+	}
+	for _, c := range cs {
+		records = append(records, []string{c.Key, c.Field, c.Left, c.Right, c.LeftSource, c.RightSource})
+	}
+	return SaveToCSV(filename, records)
+}
+
+func (cs Conflicts) SaveToMarkdown(filename string) error {
+	var b strings.Builder
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	fmt.Fprintf(&b, "# 数据冲突报告\n\n共 %d 处不一致。\n", len(cs))
+	key := ""
+	for _, c := range cs {
+		if c.Key != key {
+			key = c.Key
+			fmt.Fprintf(&b, "\n## %s\n\n", key)
+			fmt.Fprintf(&b, "- 左侧来源：%s\n- 右侧来源：%s\n\n", c.LeftSource, c.RightSource)
+			b.WriteString("| 字段 | 左侧 | 右侧 |\n| --- | ---: | ---: |\n")
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", escape.Replace(c.Field), escape.Replace(c.Left), escape.Replace(c.Right))
+	}
+	return os.WriteFile(filename, []byte(b.String()), 0644)
+}
+
+// 从全国通报中取出某个省份的数据，以便与该省份自己的通报对比
+//
+//	全国通报的分区字段以省份为键，只有这几项能对应到省份
+func (cs Dailys) Province(name string) Dailys {
+	ps := make(Dailys, 0, len(cs))
+	for _, d := range cs {
+		p := Daily{
+			Date:                           d.Date,
+			LocalConfirmed:                 d.DistrictConfirmed[name],
+			LocalAsymptomatic:              d.DistrictAsymptomatic[name],
+			LocalConfirmedFromAsymptomatic: d.DistrictConfirmedFromAsymptomatic[name],
+			Source:                         d.Source,
+		}
+		p.LocalPositive = p.LocalConfirmed + p.LocalAsymptomatic
+		ps = append(ps, p)
+	}
+	return ps
+}
@@ -0,0 +1,198 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 风险等级
+type RiskLevel string
+
+const (
+	RiskHigh   RiskLevel = "高风险"
+	RiskMedium RiskLevel = "中风险"
+	RiskLow    RiskLevel = "低风险" // 即解除中高风险
+)
+
+// 风险地区调整通告中的一项：自 Date 起，将某地区列为（或调整为）某风险等级
+type RiskAreaNotice struct {
+	Date     time.Time // 生效日期
+	City     string    // 城市
+	District string    // 区
+	Name     string    // 地区名称，如 潘家园街道松榆西里社区
+	Level    RiskLevel // 风险等级，低风险表示解除
+	Source   string    // 通告链接
+}
+
+func (n RiskAreaNotice) Key() string {
+	return fmt.Sprintf("%s.%s.%s.%s", n.Date.Format("2006-01-02"), n.District, n.Name, n.Level)
+}
+
+func (n RiskAreaNotice) String() string {
+	return fmt.Sprintf("[%s] %s%s%s => %s", n.Date.Format("2006-01-02"), n.City, n.District, n.Name, n.Level)
+}
+
+type RiskAreaNotices []RiskAreaNotice
+
+// 某地区处于中高风险的一段时期
+type RiskArea struct {
+	City       string    // 城市
+	District   string    // 区
+	Name       string    // 地区名称
+	Level      RiskLevel // 风险等级
+	Since      time.Time // 列为该风险等级的日期
+	Until      time.Time // 解除或调整为其它等级的日期，仍在生效时为零值
+	Source     string    // 列为该风险等级的通告链接
+	LiftSource string    // 解除的通告链接
+}
+
+func (a RiskArea) Key() string {
+	return fmt.Sprintf("%s.%s.%s.%s", a.Since.Format("2006-01-02"), a.District, a.Name, a.Level)
+}
+
+func (a RiskArea) String() string {
+	until := "至今"
+	if !a.Until.IsZero() {
+		until = a.Until.Format("2006-01-02")
+	}
+	return fmt.Sprintf("[%s ~ %s] %s%s%s: %s", a.Since.Format("2006-01-02"), until, a.City, a.District, a.Name, a.Level)
+}
+
+// 在 date 当天是否处于该风险等级
+func (a RiskArea) ActiveOn(date time.Time) bool {
+	return !date.Before(a.Since) && (a.Until.IsZero() || date.Before(a.Until))
+}
+
+type RiskAreas []RiskArea
+
+// 将时期拆回通告，用于与新抓取的通告合并
+func (as RiskAreas) Notices() RiskAreaNotices {
+	var ns RiskAreaNotices
+	for _, a := range as {
+		ns = append(ns, RiskAreaNotice{Date: a.Since, City: a.City, District: a.District, Name: a.Name, Level: a.Level, Source: a.Source})
+		if !a.Until.IsZero() {
+			ns = append(ns, RiskAreaNotice{Date: a.Until, City: a.City, District: a.District, Name: a.Name, Level: RiskLow, Source: a.LiftSource})
+		}
+	}
+	return ns
+}
+
+// 由通告整理出每个地区的风险时期
+//
+//	同一地区按日期排列通告：列为中高风险时开始一段时期，调整为其它等级或低风险时结束；
+//	同一天既有解除又有列为中高风险时，先解除。重复的通告只计一次。
+func (ns RiskAreaNotices) Periods() RiskAreas {
+	seen := make(map[string]bool)
+	areas := make(map[string]RiskAreaNotices)
+	var keys []string
+	for _, n := range ns {
+		if seen[n.Key()] {
+			continue
+		}
+		seen[n.Key()] = true
+		k := n.City + "." + n.District + "." + n.Name
+		if _, ok := areas[k]; !ok {
+			keys = append(keys, k)
+		}
+		areas[k] = append(areas[k], n)
+	}
+
+	var as RiskAreas
+	for _, k := range keys {
+		list := areas[k]
+		sort.SliceStable(list, func(i, j int) bool {
+			if !list[i].Date.Equal(list[j].Date) {
+				return list[i].Date.Before(list[j].Date)
+			}
+			return list[i].Level == RiskLow && list[j].Level != RiskLow
+		})
+
+		var open *RiskArea
+		for _, n := range list {
+			if open != nil && n.Level == open.Level {
+				//	重复列为同一等级
+				continue
+			}
+			if open != nil {
+				open.Until = n.Date
+				open.LiftSource = n.Source
+				as = append(as, *open)
+				open = nil
+			}
+			if n.Level != RiskLow {
+				open = &RiskArea{City: n.City, District: n.District, Name: n.Name, Level: n.Level, Since: n.Date, Source: n.Source}
+			}
+		}
+		if open != nil {
+			as = append(as, *open)
+		}
+	}
+	as.Sort()
+	return as
+}
+
+// 将新抓取的通告合并到已有的风险时期中
+func (as RiskAreas) Merge(fresh RiskAreaNotices) RiskAreas {
+	return append(as.Notices(), fresh...).Periods()
+}
+
+func (as RiskAreas) Sort() {
+	sort.SliceStable(as, func(i, j int) bool {
+		l, r := as[i], as[j]
+		if !l.Since.Equal(r.Since) {
+			return l.Since.After(r.Since)
+		}
+		if l.District != r.District {
+			return strings.Compare(l.District, r.District) < 0
+		}
+		return strings.Compare(l.Name, r.Name) < 0
+	})
+}
+
+func (as RiskAreas) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"市", "区", "地区", "风险等级", "起始日期", "解除日期", "来源", "解除来源"},
+	}
+	for _, a := range as {
+		until := ""
+		if !a.Until.IsZero() {
+			until = a.Until.Format("2006-01-02")
+		}
+		records = append(records, []string{
+			a.City,
+			a.District,
+			a.Name,
+			string(a.Level),
+			a.Since.Format("2006-01-02"),
+			until,
+			a.Source,
+			a.LiftSource,
+		})
+	}
+	return SaveToCSV(filename, records)
+}
+
+func (as RiskAreas) SaveToJSON(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	e := json.NewEncoder(f)
+	e.SetIndent("", "  ")
+	return e.Encode(as)
+}
+
+func (as *RiskAreas) LoadFromJSON(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d := json.NewDecoder(f)
+	return d.Decode(&as)
+}
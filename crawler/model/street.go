@@ -0,0 +1,99 @@
+package model
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 某日某街道/乡镇的汇总
+//
+//	上海的居住地信息只列出地址，没有分型，此时只有 Count 有值
+type Street struct {
+	Date         time.Time // 日期
+	City         string    // 城市
+	District     string    // 区
+	Street       string    // 街道/乡镇
+	Count        int       // 居住地信息条数
+	Confirmed    int       // 确诊病例（轻型、普通型、重型、危重型）
+	Asymptomatic int       // 无症状感染者
+}
+
+func (s Street) Key() string {
+	return s.Date.Format("2006-01-02") + "." + s.District + "." + s.Street
+}
+
+type Streets []Street
+
+// 按日期、区、街道汇总居住地信息，没有街道的记录不计入
+func (rs Residents) Streets() Streets {
+	index := make(map[string]int)
+	var ss Streets
+	for _, r := range rs {
+		if len(r.Street) == 0 {
+			continue
+		}
+		s := Street{Date: r.Date, City: r.City, District: r.District, Street: r.Street}
+		i, ok := index[s.Key()]
+		if !ok {
+			i = len(ss)
+			index[s.Key()] = i
+			ss = append(ss, s)
+		}
+		ss[i].Count++
+		switch r.Type {
+		case "":
+		case "无症状感染者":
+			ss[i].Asymptomatic++
+		default:
+			ss[i].Confirmed++
+		}
+	}
+	ss.Sort()
+	return ss
+}
+
+func (ss Streets) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"日期", "市", "区", "街道", "居住地信息", "确诊", "无症状"},
+	}
+	for _, s := range ss {
+		records = append(records, []string{
+			s.Date.Format("2006-01-02"),
+			s.City,
+			s.District,
+			s.Street,
+			strconv.Itoa(s.Count),
+			strconv.Itoa(s.Confirmed),
+			strconv.Itoa(s.Asymptomatic),
+		})
+	}
+	return SaveToCSV(filename, records)
+}
+
+func (ss Streets) SaveToJSON(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	e := json.NewEncoder(f)
+	e.SetIndent("", "  ")
+	return e.Encode(ss)
+}
+
+func (ss Streets) Sort() {
+	sort.SliceStable(ss, func(i, j int) bool {
+		l, r := ss[i], ss[j]
+		if !l.Date.Equal(r.Date) {
+			return l.Date.After(r.Date)
+		}
+		if l.District != r.District {
+			return strings.Compare(l.District, r.District) < 0
+		}
+		return strings.Compare(l.Street, r.Street) < 0
+	})
+}
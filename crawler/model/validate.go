@@ -0,0 +1,395 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// 违反规则的严重程度
+type Severity int
+
+const (
+	SeverityWarning Severity = iota // 可能是通报本身的问题，或分区解析不完整
+	SeverityError                   // 数据自相矛盾，不应发布
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// 一条校验规则
+type Rule struct {
+	ID       string   // 规则编号，如 asymptomatic-sum
+	Severity Severity // 严重程度
+	Message  string   // 规则说明
+	Check    func(d Daily) []Violation
+}
+
+// 违反规则的具体情况
+type Violation struct {
+	Rule     string   // 规则编号
+	Severity Severity // 严重程度
+	Key      string   // 日期
+	Field    string   // 不符合规则的字段
+	Expected int      // 根据其它字段计算出的值
+	Actual   int      // 字段的实际值
+	Message  string   // 详细说明
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("[%s] %s %s: %s", v.Key, v.Severity, v.Rule, v.Message)
+}
+
+type Violations []Violation
+
+func (vs Violations) HasErrors() bool {
+	for _, v := range vs {
+		if v.Severity >= SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// 按严重程度统计数量
+func (vs Violations) Count(s Severity) int {
+	n := 0
+	for _, v := range vs {
+		if v.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+func (vs Violations) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"日期", "规则", "严重程度", "字段", "期望值", "实际值", "说明"},
+	}
+	for _, v := range vs {
+		records = append(records, []string{v.Key, v.Rule, v.Severity.String(), v.Field, fmt.Sprint(v.Expected), fmt.Sprint(v.Actual), v.Message})
+	}
+	return SaveToCSV(filename, records)
+}
+
+func sumDistricts(dict map[string]int) int {
+	n := 0
+	for _, v := range dict {
+		n += v
+	}
+	return n
+}
+
+const (
+	formatLocalImported         = "总共:%d (本土:%d / 境外输入:%d)"
+	formatConfirmedAsymptomatic = "总共:%d (确诊:%d / 无症状:%d)"
+)
+
+// 总数 = 两个分项之和，如 本土 + 境外输入
+//
+//	require_parts 为 true 时，分项都为 0 不视为违反规则（分项没有解析出来，无法比较）
+func ruleSum(id, message, field, format string, total, local, imported func(d Daily) int, require_parts bool) Rule {
+	r := Rule{ID: id, Severity: SeverityError, Message: message}
+	r.Check = func(d Daily) []Violation {
+		t, l, i := total(d), local(d), imported(d)
+		if t == 0 || t == l+i || (require_parts && l == 0 && i == 0) {
+			return nil
+		}
+		return []Violation{{
+			Rule:     r.ID,
+			Severity: r.Severity,
+			Key:      d.Key(),
+			Field:    field,
+			Expected: l + i,
+			Actual:   t,
+			Message:  message + "：" + fmt.Sprintf(format, t, l, i),
+		}}
+	}
+	return r
+}
+
+// 分项总数 = 各分区之和
+//
+//	only_if_present 为 true 时，分区数据为空不视为违反规则（分项总数可能是计算得来的）
+func ruleDistrictSum(id, message, field string, total func(d Daily) int, districts func(d Daily) map[string]int, only_if_present bool) Rule {
+	r := Rule{ID: id, Severity: SeverityWarning, Message: message}
+	r.Check = func(d Daily) []Violation {
+		t, dict := total(d), districts(d)
+		if t == 0 || (only_if_present && len(dict) == 0) {
+			return nil
+		}
+		s := sumDistricts(dict)
+		if t == s {
+			return nil
+		}
+		return []Violation{{
+			Rule:     r.ID,
+			Severity: r.Severity,
+			Key:      d.Key(),
+			Field:    field,
+			Expected: s,
+			Actual:   t,
+			Message:  fmt.Sprintf("%s：总共:%d => %d: (分区: %v)", message, t, s, dict),
+		}}
+	}
+	return r
+}
+
+// 各分区的阳性感染者 = 该区确诊 + 无症状
+//
+//	广州等城市的通报直接给出分区阳性总数，分类型的分区数据来自病例列表，两者可能不一致；
+//	三项分区数据都有时才比较
+func ruleDistrictPositiveByType(id, message string) Rule {
+	r := Rule{ID: id, Severity: SeverityWarning, Message: message}
+	r.Check = func(d Daily) []Violation {
+		if len(d.DistrictPositive) == 0 || len(d.DistrictConfirmed) == 0 || len(d.DistrictAsymptomatic) == 0 {
+			return nil
+		}
+		districts := make(map[string]bool)
+		for _, dict := range []map[string]int{d.DistrictPositive, d.DistrictConfirmed, d.DistrictAsymptomatic} {
+			for k := range dict {
+				districts[k] = true
+			}
+		}
+		names := make([]string, 0, len(districts))
+		for k := range districts {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		var vs []Violation
+		for _, k := range names {
+			p, c, a := d.DistrictPositive[k], d.DistrictConfirmed[k], d.DistrictAsymptomatic[k]
+			if p == c+a {
+				continue
+			}
+			vs = append(vs, Violation{
+				Rule:     r.ID,
+				Severity: r.Severity,
+				Key:      d.Key(),
+				Field:    "DistrictPositive",
+				Expected: c + a,
+				Actual:   p,
+				Message:  fmt.Sprintf("%s：%s %d => %d: (确诊:%d / 无症状:%d)", message, k, p, c+a, c, a),
+			})
+		}
+		return vs
+	}
+	return r
+}
+
+// 计算得来的分项不应为负数
+func ruleNonNegative(id, message, field string, value func(d Daily) int) Rule {
+	r := Rule{ID: id, Severity: SeverityError, Message: message}
+	r.Check = func(d Daily) []Violation {
+		v := value(d)
+		if v >= 0 {
+			return nil
+		}
+		return []Violation{{
+			Rule:     r.ID,
+			Severity: r.Severity,
+			Key:      d.Key(),
+			Field:    field,
+			Expected: 0,
+			Actual:   v,
+			Message:  fmt.Sprintf("%s：%d", message, v),
+		}}
+	}
+	return r
+}
+
+// 所有的 Daily 校验规则
+var DailyRules = []Rule{
+	//	无症状
+	ruleSum("asymptomatic-sum", "无症状数据不匹配", "Asymptomatic", formatLocalImported,
+		func(d Daily) int { return d.Asymptomatic },
+		func(d Daily) int { return d.LocalAsymptomatic },
+		func(d Daily) int { return d.ImportedAsymptomatic }, false),
+	ruleDistrictSum("asymptomatic-from-bubble-district", "无症状(来自隔离管控)数据不匹配", "LocalAsymptomaticFromBubble",
+		func(d Daily) int { return d.LocalAsymptomaticFromBubble },
+		func(d Daily) map[string]int { return d.DistrictAsymptomaticFromBubble }, false),
+	ruleDistrictSum("asymptomatic-from-risk-district", "无症状(来自风险人群)数据不匹配", "LocalAsymptomaticFromRisk",
+		func(d Daily) int { return d.LocalAsymptomaticFromRisk },
+		func(d Daily) map[string]int { return d.DistrictAsymptomaticFromRisk }, true),
+	ruleNonNegative("asymptomatic-from-risk-negative", "无症状(来自风险人群)数据不合理", "LocalAsymptomaticFromRisk",
+		func(d Daily) int { return d.LocalAsymptomaticFromRisk }),
+
+	//	确诊
+	{
+		ID:       "clinical-type-sum",
+		Severity: SeverityWarning,
+		Message:  "本土确诊数据与临床分型之和不匹配",
+		Check: func(d Daily) []Violation {
+			mcsc := d.Mild + d.Common + d.Severe + d.Critical
+			if mcsc == 0 || d.LocalConfirmed == 0 || d.LocalConfirmed == mcsc {
+				return nil
+			}
+			return []Violation{{
+				Rule:     "clinical-type-sum",
+				Severity: SeverityWarning,
+				Key:      d.Key(),
+				Field:    "LocalConfirmed",
+				Expected: mcsc,
+				Actual:   d.LocalConfirmed,
+				Message: fmt.Sprintf("本土确诊数据不匹配：本土确诊:%d => %d: (轻型:%d / 普通型:%d / 重型:%d / 危重型:%d)",
+					d.LocalConfirmed, mcsc, d.Mild, d.Common, d.Severe, d.Critical),
+			}}
+		},
+	},
+	ruleSum("confirmed-sum", "确诊数据不匹配", "Confirmed", formatLocalImported,
+		func(d Daily) int { return d.Confirmed },
+		func(d Daily) int { return d.LocalConfirmed },
+		func(d Daily) int { return d.ImportedConfirmed }, false),
+	ruleDistrictSum("confirmed-from-asymptomatic-district", "确诊病例(来自无症状)数据不匹配", "LocalConfirmedFromAsymptomatic",
+		func(d Daily) int { return d.LocalConfirmedFromAsymptomatic },
+		func(d Daily) map[string]int { return d.DistrictConfirmedFromAsymptomatic }, false),
+	ruleDistrictSum("confirmed-from-bubble-district", "确诊病例(来自隔离管控)数据不匹配", "LocalConfirmedFromBubble",
+		func(d Daily) int { return d.LocalConfirmedFromBubble },
+		func(d Daily) map[string]int { return d.DistrictConfirmedFromBubble }, false),
+	ruleDistrictSum("confirmed-from-risk-district", "确诊病例(来自风险人群)数据不匹配", "LocalConfirmedFromRisk",
+		func(d Daily) int { return d.LocalConfirmedFromRisk },
+		func(d Daily) map[string]int { return d.DistrictConfirmedFromRisk }, true),
+	ruleNonNegative("confirmed-from-risk-negative", "确诊病例(来自风险人群)数据不合理", "LocalConfirmedFromRisk",
+		func(d Daily) int { return d.LocalConfirmedFromRisk }),
+
+	//	阳性感染者
+	ruleSum("local-positive-sum", "本土阳性感染者数据不匹配", "LocalPositive", formatConfirmedAsymptomatic,
+		func(d Daily) int { return d.LocalPositive },
+		func(d Daily) int { return d.LocalConfirmed },
+		func(d Daily) int { return d.LocalAsymptomatic }, false),
+	ruleSum("imported-positive-sum", "境外输入阳性感染者数据不匹配", "ImportedPositive", formatConfirmedAsymptomatic,
+		func(d Daily) int { return d.ImportedPositive },
+		func(d Daily) int { return d.ImportedConfirmed },
+		func(d Daily) int { return d.ImportedAsymptomatic }, false),
+	ruleSum("positive-sum", "阳性感染者数据不匹配", "Positive", formatConfirmedAsymptomatic,
+		func(d Daily) int { return d.Positive },
+		func(d Daily) int { return d.Confirmed },
+		func(d Daily) int { return d.Asymptomatic }, false),
+	ruleDistrictSum("positive-from-bubble-district", "阳性感染者(来自隔离管控)数据不匹配", "LocalPositiveFromBubble",
+		func(d Daily) int { return d.LocalPositiveFromBubble },
+		func(d Daily) map[string]int { return d.DistrictPositiveFromBubble }, false),
+	ruleDistrictSum("positive-from-risk-district", "阳性感染者(来自风险人群)数据不匹配", "LocalPositiveFromRisk",
+		func(d Daily) int { return d.LocalPositiveFromRisk },
+		func(d Daily) map[string]int { return d.DistrictPositiveFromRisk }, true),
+	ruleNonNegative("positive-from-risk-negative", "阳性感染者(来自风险人群)数据不合理", "LocalPositiveFromRisk",
+		func(d Daily) int { return d.LocalPositiveFromRisk }),
+	ruleDistrictPositiveByType("positive-district-by-type", "分区阳性感染者与分区确诊、无症状之和不匹配"),
+
+	//	治愈出院、解除医学观察、死亡、在院治疗、尚在医学观察
+	ruleSum("discharged-sum", "治愈出院数据不匹配", "DischargedFromHospital", formatLocalImported,
+		func(d Daily) int { return d.DischargedFromHospital },
+		func(d Daily) int { return d.LocalDischargedFromHospital },
+		func(d Daily) int { return d.ImportedDischargedFromHospital }, true),
+	ruleSum("medical-observation-discharged-sum", "解除医学观察数据不匹配", "DischargedFromMedicalObservation", formatLocalImported,
+		func(d Daily) int { return d.DischargedFromMedicalObservation },
+		func(d Daily) int { return d.LocalDischargedFromMedicalObservation },
+		func(d Daily) int { return d.ImportedDischargedFromMedicalObservation }, false),
+	ruleSum("death-sum", "死亡数据不匹配", "Death", formatLocalImported,
+		func(d Daily) int { return d.Death },
+		func(d Daily) int { return d.LocalDeath },
+		func(d Daily) int { return d.ImportedDeath }, false),
+	{
+		ID:       "death-cases-count",
+		Severity: SeverityWarning,
+		Message:  "死亡病例详情与本土死亡数不匹配",
+		Check: func(d Daily) []Violation {
+			n := len(d.DeathCases)
+			if n == 0 || n == d.LocalDeath {
+				return nil
+			}
+			return []Violation{{
+				Rule:     "death-cases-count",
+				Severity: SeverityWarning,
+				Key:      d.Key(),
+				Field:    "LocalDeath",
+				Expected: n,
+				Actual:   d.LocalDeath,
+				Message:  fmt.Sprintf("死亡病例详情与本土死亡数不匹配：本土死亡:%d => 死亡病例详情:%d", d.LocalDeath, n),
+			}}
+		},
+	},
+	ruleSum("in-hospital-sum", "在院治疗数据不匹配", "CurrentInHospital", formatLocalImported,
+		func(d Daily) int { return d.CurrentInHospital },
+		func(d Daily) int { return d.CurrentLocalInHospital },
+		func(d Daily) int { return d.CurrentImportedInHospital }, false),
+	ruleSum("under-medical-observation-sum", "尚在医疗观察数据不匹配", "UnderMedicalObservation", formatLocalImported,
+		func(d Daily) int { return d.UnderMedicalObservation },
+		func(d Daily) int { return d.LocalUnderMedicalObservation },
+		func(d Daily) int { return d.ImportedUnderMedicalObservation }, false),
+
+	//	累计
+	{
+		ID:       "local-total-balance",
+		Severity: SeverityWarning,
+		Message:  "本土确诊、出院、死亡、住院数据不匹配",
+		Check: func(d Daily) []Violation {
+			s := d.CurrentLocalInHospital + d.TotalLocalDischargedFromHospital + d.TotalLocalDeath
+			if s == d.TotalLocalConfirmed {
+				return nil
+			}
+			return []Violation{{
+				Rule:     "local-total-balance",
+				Severity: SeverityWarning,
+				Key:      d.Key(),
+				Field:    "TotalLocalConfirmed",
+				Expected: s,
+				Actual:   d.TotalLocalConfirmed,
+				Message: fmt.Sprintf("本土确诊、出院、死亡、住院数据不匹配：累计本土确诊(%d) => (%d): 本土在院治疗(%d) + 累计本土治愈出院(%d) + 累计本土死亡(%d)",
+					d.TotalLocalConfirmed, s, d.CurrentLocalInHospital, d.TotalLocalDischargedFromHospital, d.TotalLocalDeath),
+			}}
+		},
+	},
+	{
+		ID:       "imported-total-balance",
+		Severity: SeverityWarning,
+		Message:  "境外输入确诊、出院、住院数据不匹配",
+		Check: func(d Daily) []Violation {
+			s := d.CurrentImportedInHospital + d.TotalImportedDischargedFromHospital
+			if s == d.TotalImportedConfirmed {
+				return nil
+			}
+			return []Violation{{
+				Rule:     "imported-total-balance",
+				Severity: SeverityWarning,
+				Key:      d.Key(),
+				Field:    "TotalImportedConfirmed",
+				Expected: s,
+				Actual:   d.TotalImportedConfirmed,
+				Message: fmt.Sprintf("境外输入确诊、出院、死亡、住院数据不匹配：累计境外输入确诊(%d) => (%d): 境外输入在院治疗(%d) + 累计境外输入治愈出院(%d)",
+					d.TotalImportedConfirmed, s, d.CurrentImportedInHospital, d.TotalImportedDischargedFromHospital),
+			}}
+		},
+	},
+}
+
+// 用所有规则校验一天的数据
+func (d Daily) Validate() Violations {
+	var vs Violations
+	for _, r := range DailyRules {
+		vs = append(vs, r.Check(d)...)
+	}
+	return vs
+}
+
+// 校验所有数据，结果按日期、规则排序
+func (cs Dailys) Validate() Violations {
+	var vs Violations
+	for _, d := range cs {
+		vs = append(vs, d.Validate()...)
+	}
+	sort.SliceStable(vs, func(i, j int) bool {
+		if vs[i].Key != vs[j].Key {
+			return vs[i].Key < vs[j].Key
+		}
+		return vs[i].Rule < vs[j].Rule
+	})
+	return vs
+}
@@ -0,0 +1,218 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 封控分级
+type ZoneTier string
+
+const (
+	ZoneSealed     ZoneTier = "封控区"
+	ZoneControlled ZoneTier = "管控区"
+	ZonePrecaution ZoneTier = "防范区"
+)
+
+// 分级的严格程度，同一小区出现在多个名单中时取最严格的
+func (t ZoneTier) rank() int {
+	switch t {
+	case ZoneSealed:
+		return 3
+	case ZoneControlled:
+		return 2
+	case ZonePrecaution:
+		return 1
+	}
+	return 0
+}
+
+// 名单超过这个时间没有更新，就不再用于标记居住地信息
+const ZONE_LIST_MAX_AGE = 7 * 24 * time.Hour
+
+// 用于包含匹配的名称最少字数，以免“1号”这类过短的名称误匹配
+const ZONE_NAME_MIN_LEN = 4
+
+// 某区某日公布的封控区、管控区、防范区名单中的一个小区
+type Zone struct {
+	Date     time.Time // 名单公布日期
+	City     string    // 城市
+	District string    // 区
+	Street   string    // 街道/乡镇
+	Name     string    // 小区名称
+	Tier     ZoneTier  // 封控分级
+	Source   string    // 名单链接
+}
+
+func (z Zone) Key() string {
+	return fmt.Sprintf("%s.%s.%s", z.Date.Format("2006-01-02"), z.District, z.Name)
+}
+
+func (z Zone) String() string {
+	return fmt.Sprintf("[%s] %s%s%s%s: %s", z.Date.Format("2006-01-02"), z.City, z.District, z.Street, z.Name, z.Tier)
+}
+
+type Zones []Zone
+
+// 用新抓取的名单替换已有名单中同一天、同一区的全部小区，其余名单保持不变
+func (zs Zones) Merge(fresh Zones) Zones {
+	lists := make(map[string]bool)
+	for _, z := range fresh {
+		lists[z.Date.Format("2006-01-02")+"."+z.District] = true
+	}
+	result := make(Zones, 0, len(zs)+len(fresh))
+	for _, z := range zs {
+		if !lists[z.Date.Format("2006-01-02")+"."+z.District] {
+			result = append(result, z)
+		}
+	}
+	result = append(result, fresh...)
+	result.Sort()
+	return result
+}
+
+func (zs Zones) Sort() {
+	sort.SliceStable(zs, func(i, j int) bool {
+		l, r := zs[i], zs[j]
+		if !l.Date.Equal(r.Date) {
+			return l.Date.After(r.Date)
+		}
+		if l.District != r.District {
+			return strings.Compare(l.District, r.District) < 0
+		}
+		if l.Tier != r.Tier {
+			return l.Tier.rank() > r.Tier.rank()
+		}
+		if l.Street != r.Street {
+			return strings.Compare(l.Street, r.Street) < 0
+		}
+		return strings.Compare(l.Name, r.Name) < 0
+	})
+}
+
+func (zs Zones) SaveToCSV(filename string) error {
+	records := [][]string{
+		{"日期", "市", "区", "街道", "小区", "封控分级", "来源"},
+	}
+	for _, z := range zs {
+		records = append(records, []string{
+			z.Date.Format("2006-01-02"),
+			z.City,
+			z.District,
+			z.Street,
+			z.Name,
+			string(z.Tier),
+			z.Source,
+		})
+	}
+	return SaveToCSV(filename, records)
+}
+
+func (zs Zones) SaveToJSON(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	e := json.NewEncoder(f)
+	e.SetIndent("", "  ")
+	return e.Encode(zs)
+}
+
+func (zs *Zones) LoadFromJSON(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d := json.NewDecoder(f)
+	return d.Decode(&zs)
+}
+
+//	居住地信息与名单的关联
+
+// 某区某日的一份名单
+type zoneList struct {
+	date  time.Time
+	names map[string]ZoneTier // 规范化后的小区名称 => 分级
+	cache map[string]ZoneTier // 居住地 => 包含匹配的结果
+}
+
+func normalizeZoneName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func (l *zoneList) find(address string) ZoneTier {
+	address = normalizeZoneName(address)
+	if t, ok := l.names[address]; ok {
+		return t
+	}
+	if t, ok := l.cache[address]; ok {
+		return t
+	}
+	//	名单中的名称与居住地写法不完全一致，如“永年路24弄”与“永年路24弄（部分）”
+	var tier ZoneTier
+	if utf8.RuneCountInString(address) >= ZONE_NAME_MIN_LEN {
+		for name, t := range l.names {
+			if t.rank() <= tier.rank() || utf8.RuneCountInString(name) < ZONE_NAME_MIN_LEN {
+				continue
+			}
+			if strings.Contains(name, address) || strings.Contains(address, name) {
+				tier = t
+			}
+		}
+	}
+	l.cache[address] = tier
+	return tier
+}
+
+// 按居住地所在区当日（或之前最近一次）公布的名单，标记每条居住地信息的封控分级
+//
+//	没有名单、名单已过期或不在名单中的居住地，分级为空
+func (rs Residents) JoinZones(zs Zones) {
+	//	区 => 按日期排序的名单
+	lists := make(map[string][]*zoneList)
+	index := make(map[string]*zoneList)
+	for _, z := range zs {
+		k := z.City + "." + z.District + "." + z.Date.Format("2006-01-02")
+		l, ok := index[k]
+		if !ok {
+			l = &zoneList{date: z.Date, names: make(map[string]ZoneTier), cache: make(map[string]ZoneTier)}
+			index[k] = l
+			lists[z.City+"."+z.District] = append(lists[z.City+"."+z.District], l)
+		}
+		name := normalizeZoneName(z.Name)
+		if z.Tier.rank() > l.names[name].rank() {
+			l.names[name] = z.Tier
+		}
+	}
+	for _, ls := range lists {
+		sort.Slice(ls, func(i, j int) bool { return ls[i].date.Before(ls[j].date) })
+	}
+
+	for i, r := range rs {
+		rs[i].Zone = ""
+		ls := lists[r.City+"."+r.District]
+		//	最近一次不晚于当天的名单
+		n := sort.Search(len(ls), func(j int) bool { return ls[j].date.After(r.Date) })
+		if n == 0 {
+			continue
+		}
+		l := ls[n-1]
+		if r.Date.Sub(l.date) > ZONE_LIST_MAX_AGE {
+			continue
+		}
+		rs[i].Zone = l.find(r.Address)
+	}
+}